@@ -0,0 +1,141 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type Outcome string
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// Actor is who performed an action, taken from the request's authn.Principal.
+type Actor struct {
+	Type    string `json:"type,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	AppID   string `json:"app_id,omitempty"`
+	KeyID   string `json:"key_id,omitempty"`
+}
+
+// Resource is what an action changed.
+type Resource struct {
+	Type string `json:"type,omitempty"`
+	ID   string `json:"id,omitempty"`
+}
+
+// Event is one audit record. Sinks must treat events as append-only.
+type Event struct {
+	ID        string            `json:"id"`
+	Time      time.Time         `json:"time"`
+	Action    string            `json:"action"`
+	Outcome   Outcome           `json:"outcome"`
+	Actor     Actor             `json:"actor"`
+	Resource  Resource          `json:"resource,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	IP        string            `json:"ip,omitempty"`
+	Method    string            `json:"method,omitempty"`
+	Route     string            `json:"route,omitempty"`
+	Status    int               `json:"status,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Before    json.RawMessage   `json:"before,omitempty"`
+	After     json.RawMessage   `json:"after,omitempty"`
+	Changes   []Change          `json:"changes,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// Option sets fields of an event passed to Record or Annotate.
+type Option func(*Event)
+
+// WithResource sets the resource the action changed.
+func WithResource(resourceType, id string) Option {
+	return func(e *Event) {
+		e.Resource = Resource{Type: resourceType, ID: id}
+	}
+}
+
+// WithBefore stores v, encoded as JSON, as the state before the change. With
+// WithAfter the event also lists the changed fields.
+func WithBefore(v any) Option {
+	return func(e *Event) {
+		e.Before = encode(v)
+	}
+}
+
+// WithAfter stores v, encoded as JSON, as the state after the change.
+func WithAfter(v any) Option {
+	return func(e *Event) {
+		e.After = encode(v)
+	}
+}
+
+// WithError marks the event failed when err is not nil.
+func WithError(err error) Option {
+	return func(e *Event) {
+		if err != nil {
+			e.Outcome = OutcomeFailure
+			e.Error = err.Error()
+		}
+	}
+}
+
+// WithMetadata adds a key/value pair to the event.
+func WithMetadata(key, value string) Option {
+	return func(e *Event) {
+		if e.Metadata == nil {
+			e.Metadata = make(map[string]string)
+		}
+		e.Metadata[key] = value
+	}
+}
+
+// WithActor overrides the actor taken from the context, e.g. for jobs acting
+// on behalf of a user.
+func WithActor(actor Actor) Option {
+	return func(e *Event) {
+		e.Actor = actor
+	}
+}
+
+func encode(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	if raw, ok := v.(json.RawMessage); ok {
+		return raw
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// Record records action with the Recorder of ctx, or the app's Recorder once
+// it has started.
+//
+// Usage: err := audit.Record(ctx, "invoice.void", audit.WithResource("invoice", id), audit.WithBefore(old), audit.WithAfter(inv))
+func Record(ctx context.Context, action string, opts ...Option) error {
+	return FromContext(ctx).Record(ctx, action, opts...)
+}
+
+type pendingKey struct{}
+
+// Annotate adds options to the event of the audited route serving ctx. It is
+// a no-op outside an audited route.
+//
+// Usage: audit.Annotate(ctx, audit.WithResource("order", id), audit.WithBefore(order))
+func Annotate(ctx context.Context, opts ...Option) {
+	pending, ok := ctx.Value(pendingKey{}).(*Event)
+	if !ok {
+		return
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(pending)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/bronystylecrazy/ultrastructure/di"
+	"github.com/bronystylecrazy/ultrastructure/security/authn"
+	"github.com/bronystylecrazy/ultrastructure/ustest"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
+
+type cancelParams struct {
+	ID string `uri:"id" json:"-"`
+}
+
+type testOrderHandler struct{}
+
+func (testOrderHandler) Cancel(ctx context.Context, req cancelParams) (fiber.Map, error) {
+	if req.ID == "missing" {
+		return nil, web.NewError(http.StatusNotFound, "ORDER_NOT_FOUND", "order not found")
+	}
+	Annotate(ctx, WithResource("order", req.ID), WithBefore(fiber.Map{"status": "open"}), WithAfter(fiber.Map{"status": "cancelled"}))
+	return fiber.Map{"id": req.ID, "status": "cancelled"}, nil
+}
+
+func (h testOrderHandler) Handle(r web.Router) {
+	r.Post("/orders/:id/cancel").With(web.HandleParams(h.Cancel)).Audit("order.cancel")
+}
+
+func TestRouteAuditRecordsPrincipalOutcomeAndChanges(t *testing.T) {
+	sink := NewMemorySink(0)
+	app := ustest.Start(t,
+		Providers(UseSink(sink), UseQueryHandler()),
+		di.Provide(func() testOrderHandler { return testOrderHandler{} }),
+		web.InitHandlers(),
+	)
+	client := app.HTTP()
+	user := &authn.Principal{Type: authn.PrincipalUser, Subject: "u-1"}
+
+	client.Post("/orders/o-1/cancel").As(user).Expect(http.StatusCreated)
+	client.Post("/orders/missing/cancel").As(user).Expect(http.StatusNotFound)
+
+	events, _ := sink.Query(context.Background(), Filter{})
+	if len(events) != 2 {
+		t.Fatalf("events = %+v", events)
+	}
+	failed, ok := events[0], events[1]
+	if failed.Outcome != OutcomeFailure || failed.Status != http.StatusNotFound || failed.Error == "" {
+		t.Fatalf("failed event = %+v", failed)
+	}
+	if ok.Action != "order.cancel" || ok.Outcome != OutcomeSuccess || ok.Status != http.StatusCreated {
+		t.Fatalf("event = %+v", ok)
+	}
+	if ok.Actor.Subject != "u-1" || ok.Actor.Type != string(authn.PrincipalUser) {
+		t.Fatalf("actor = %+v", ok.Actor)
+	}
+	if ok.Route != "/orders/:id/cancel" || ok.Method != http.MethodPost || ok.Resource != (Resource{Type: "order", ID: "o-1"}) {
+		t.Fatalf("request fields = %+v", ok)
+	}
+	if len(ok.Changes) != 1 || ok.Changes[0].Path != "status" || ok.Changes[0].After != "cancelled" {
+		t.Fatalf("changes = %+v", ok.Changes)
+	}
+
+	client.Get("/admin/audit/events").Query("actor", "u-1").Query("resource_id", "o-1").
+		Expect(http.StatusOK).JSONPath("0.action", "order.cancel")
+	client.Get("/admin/audit/events").Query("from", "yesterday").Expect(http.StatusBadRequest)
+}
+
+func TestRecordUsesContextRecorder(t *testing.T) {
+	sink := NewMemorySink(0)
+	r, err := NewRecorder(Config{}, nil, sink)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	ctx := authn.WithPrincipal(WithRecorder(context.Background(), r), &authn.Principal{Type: authn.PrincipalApp, AppID: "billing"})
+
+	if err := Record(ctx, "invoice.void", WithResource("invoice", "i-1"), WithError(errors.New("already paid"))); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := Record(context.Background(), "dropped"); err != nil {
+		t.Fatalf("Record without recorder: %v", err)
+	}
+
+	events, _ := sink.Query(ctx, Filter{Actor: "billing"})
+	if len(events) != 1 || events[0].ID == "" || events[0].Outcome != OutcomeFailure || events[0].Resource.ID != "i-1" {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	changes := Diff(
+		[]byte(`{"status":"open","items":[{"qty":1}],"note":"a"}`),
+		[]byte(`{"status":"open","items":[{"qty":2}],"tag":"x"}`),
+	)
+	want := []string{"items.0.qty", "note", "tag"}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %+v", changes)
+	}
+	for i, path := range want {
+		if changes[i].Path != path {
+			t.Fatalf("changes[%d].Path = %q, want %q", i, changes[i].Path, path)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package audit
+
+type Config struct {
+	// Disabled turns Record and audited routes into no-ops.
+	Disabled bool `mapstructure:"disabled" default:"false"`
+	// Log also writes every event to the application log.
+	Log bool `mapstructure:"log" default:"false"`
+	// File appends every event to this file as JSON lines.
+	File        string `mapstructure:"file"`
+	AdminPrefix string `mapstructure:"admin_prefix" default:"/admin/audit"`
+}
+
+const DefaultAdminPrefix = "/admin/audit"
+
+// withDefaults fills in the values a zero Config leaves empty.
+func (c Config) withDefaults() Config {
+	if c.AdminPrefix == "" {
+		c.AdminPrefix = DefaultAdminPrefix
+	}
+	return c
+}
@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"github.com/bronystylecrazy/ultrastructure/cfg"
+	"github.com/bronystylecrazy/ultrastructure/di"
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
+
+const SinksGroupName = "us.audit.sinks"
+
+var OtelScope = "audit"
+
+// Providers registers the audit recorder. Any provided type implementing
+// Sink is picked up automatically.
+//
+// Usage: us.New(audit.Providers(audit.UseSink(xgorm.NewAuditStore(db))), ...)
+func Providers(extends ...di.Node) di.Node {
+	nodes := []any{
+		di.AutoGroup[Sink](SinksGroupName),
+		cfg.Config[Config]("audit", cfg.WithSourceFile("config.toml"), cfg.WithType("toml")),
+		di.Provide(NewRecorder, di.VariadicGroup(SinksGroupName), otel.Layer(OtelScope), web.Priority(web.Earliest)),
+	}
+	nodes = append(nodes, di.ConvertAnys(extends)...)
+	return di.Options(nodes...)
+}
+
+// UseSink adds sink to the recorder. Audited requests wait for Write, so a
+// sink that returns before the event is durable, such as xgorm.AuditStore
+// with WithAsyncAuditWrites, loses the events it still holds on a crash.
+func UseSink(sink Sink) di.Node {
+	return di.Supply(sink, di.Group(SinksGroupName))
+}
+
+// UseQueryHandler mounts the audit log search behind guards, which should
+// authenticate and authorize the caller.
+//
+// Usage: audit.UseQueryHandler(authn.Any(authn.UserTokenAuthenticator(users)), authz.RequireUserRole("auditor"))
+func UseQueryHandler(guards ...fiber.Handler) di.Node {
+	return di.Provide(func(config Config, recorder *Recorder) (*QueryHandler, error) {
+		return NewQueryHandler(config, recorder, guards...)
+	})
+}
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Change is one field that differs between the before and after payloads.
+// Path is dotted, with array indexes as segments, e.g. "items.0.qty".
+type Change struct {
+	Path   string `json:"path"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// Diff compares two JSON documents and returns the changed leaves sorted by
+// path. Objects are compared key by key; arrays of different length and
+// values of different kinds are reported as a whole.
+func Diff(before, after json.RawMessage) []Change {
+	var b, a any
+	if len(before) > 0 && json.Unmarshal(before, &b) != nil {
+		return nil
+	}
+	if len(after) > 0 && json.Unmarshal(after, &a) != nil {
+		return nil
+	}
+	var changes []Change
+	diffValue("", b, a, &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func diffValue(path string, before, after any, changes *[]Change) {
+	switch b := before.(type) {
+	case map[string]any:
+		a, ok := after.(map[string]any)
+		if !ok {
+			break
+		}
+		for key, bv := range b {
+			diffValue(joinPath(path, key), bv, a[key], changes)
+		}
+		for key, av := range a {
+			if _, seen := b[key]; !seen {
+				diffValue(joinPath(path, key), nil, av, changes)
+			}
+		}
+		return
+	case []any:
+		a, ok := after.([]any)
+		if !ok || len(a) != len(b) {
+			break
+		}
+		for i := range b {
+			diffValue(joinPath(path, strconv.Itoa(i)), b[i], a[i], changes)
+		}
+		return
+	}
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, Change{Path: path, Before: before, After: after})
+	}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
+
+var ErrNoQuerier = errors.New("audit: no sink can be queried; provide one implementing audit.Querier")
+
+// QueryHandler serves the audit log under Config.AdminPrefix.
+type QueryHandler struct {
+	config  Config
+	querier Querier
+	guards  []fiber.Handler
+}
+
+func NewQueryHandler(config Config, recorder *Recorder, guards ...fiber.Handler) (*QueryHandler, error) {
+	querier := recorder.Querier()
+	if querier == nil {
+		return nil, ErrNoQuerier
+	}
+	return &QueryHandler{
+		config:  config.withDefaults(),
+		querier: querier,
+		guards:  guards,
+	}, nil
+}
+
+// QueryRequest filters the audit log. From and To are RFC 3339 times; To is
+// exclusive.
+type QueryRequest struct {
+	Actor        string `query:"actor" json:"-"`
+	Action       string `query:"action" json:"-"`
+	ResourceType string `query:"resource_type" json:"-"`
+	ResourceID   string `query:"resource_id" json:"-"`
+	From         string `query:"from" json:"-"`
+	To           string `query:"to" json:"-"`
+	Limit        int    `query:"limit" json:"-"`
+}
+
+func (h *QueryHandler) Handle(r web.Router) {
+	r.Group(h.config.AdminPrefix, h.guards...).
+		Tags("Audit").
+		Get("/events").
+		With(web.HandleQuery(h.Query)).
+		Summary("Search the audit log").
+		ProducesWithDescription(web.Error{}, http.StatusBadRequest, "Invalid time filter")
+}
+
+func (h *QueryHandler) Query(ctx context.Context, req QueryRequest) ([]Event, error) {
+	filter := Filter{
+		Actor:        req.Actor,
+		Action:       req.Action,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+		Limit:        req.Limit,
+	}
+	var err error
+	if filter.From, err = parseTime(req.From); err != nil {
+		return nil, web.NewError(http.StatusBadRequest, "AUDIT_INVALID_TIME", "from must be an RFC 3339 time")
+	}
+	if filter.To, err = parseTime(req.To); err != nil {
+		return nil, web.NewError(http.StatusBadRequest, "AUDIT_INVALID_TIME", "to must be an RFC 3339 time")
+	}
+	return h.querier.Query(ctx, filter)
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,241 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	"github.com/bronystylecrazy/ultrastructure/security/authn"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// Metrics recorded by the recorder.
+const (
+	EventsMetric     = "audit.events"
+	SinkErrorsMetric = "audit.sink.errors"
+)
+
+// Recorder fills in events from the request context and writes them to
+// every sink. It is the web.Auditor of routes marked with Audit.
+type Recorder struct {
+	otel.Telemetry
+
+	config Config
+	sinks  []Sink
+	errors *web.ErrorRegistry
+	now    func() time.Time
+}
+
+var (
+	defaultRecorder atomic.Pointer[Recorder]
+	nopRecorder     = &Recorder{Telemetry: otel.Nop(), config: Config{Disabled: true}, now: time.Now}
+)
+
+// NewRecorder builds a recorder writing to sinks, plus the log and file
+// sinks enabled by config. errs resolves the status of failed routes and may
+// be nil.
+func NewRecorder(config Config, errs *web.ErrorRegistry, sinks ...Sink) (*Recorder, error) {
+	r := &Recorder{
+		Telemetry: otel.Nop(),
+		config:    config.withDefaults(),
+		errors:    errs,
+		now:       time.Now,
+	}
+	for _, sink := range sinks {
+		if sink != nil {
+			r.sinks = append(r.sinks, sink)
+		}
+	}
+	if r.config.Log {
+		r.sinks = append(r.sinks, NewLogSink(&r.Telemetry))
+	}
+	if r.config.File != "" {
+		file, err := NewFileSink(r.config.File)
+		if err != nil {
+			return nil, err
+		}
+		r.sinks = append(r.sinks, file)
+	}
+	return r, nil
+}
+
+// Querier returns the first sink that can be queried, or nil.
+func (r *Recorder) Querier() Querier {
+	for _, sink := range r.sinks {
+		if q, ok := sink.(Querier); ok {
+			return q
+		}
+	}
+	return nil
+}
+
+// Start makes the recorder the one audit.Record uses outside requests.
+func (r *Recorder) Start(context.Context) error {
+	defaultRecorder.Store(r)
+	return nil
+}
+
+// Stop stops the sinks that buffer or hold files, e.g. flushing the queue of
+// xgorm.AuditStore.
+func (r *Recorder) Stop(ctx context.Context) error {
+	defaultRecorder.CompareAndSwap(r, nil)
+	var errs []error
+	for _, sink := range r.sinks {
+		if s, ok := sink.(interface{ Stop(context.Context) error }); ok {
+			errs = append(errs, s.Stop(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns ctx carrying r for audit.Record.
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// FromContext returns the recorder of ctx, the started app recorder, or a
+// recorder that drops every event.
+func FromContext(ctx context.Context) *Recorder {
+	if r, ok := ctx.Value(recorderKey{}).(*Recorder); ok && r != nil {
+		return r
+	}
+	if r := defaultRecorder.Load(); r != nil {
+		return r
+	}
+	return nopRecorder
+}
+
+// Handle installs the recorder on every request, for audit.Record and the
+// routes marked with Audit.
+func (r *Recorder) Handle(router web.Router) {
+	router.Use(func(c fiber.Ctx) error {
+		web.SetAuditorLocals(c, r)
+		c.SetContext(WithRecorder(c.Context(), r))
+		return c.Next()
+	})
+}
+
+// Record records action, taking the actor and request ID from ctx.
+func (r *Recorder) Record(ctx context.Context, action string, opts ...Option) error {
+	event := Event{Action: action, Outcome: OutcomeSuccess}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&event)
+		}
+	}
+	return r.write(ctx, event)
+}
+
+// AuditRoute implements web.Auditor.
+func (r *Recorder) AuditRoute(c fiber.Ctx, action string, next func() error) error {
+	if r.config.Disabled {
+		return next()
+	}
+	event := &Event{Action: action, Outcome: OutcomeSuccess}
+	c.SetContext(context.WithValue(c.Context(), pendingKey{}, event))
+
+	err := next()
+
+	event.Method = c.Method()
+	event.Route = c.Route().Path
+	event.IP = c.IP()
+	event.Status = c.Response().StatusCode()
+	if err != nil {
+		event.Status = r.status(err)
+		event.Error = err.Error()
+	}
+	if event.Status >= http.StatusBadRequest {
+		event.Outcome = OutcomeFailure
+	}
+	if werr := r.write(c.Context(), *event); werr != nil {
+		r.Obs.Error("audit: record route failed", zap.String("audit.action", action), zap.Error(werr))
+	}
+	return err
+}
+
+func (r *Recorder) status(err error) int {
+	if r.errors != nil {
+		return r.errors.Resolve(err).Status
+	}
+	var httpErr *web.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Status
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// write completes event from ctx and writes it to every sink, returning the
+// joined sink errors.
+func (r *Recorder) write(ctx context.Context, event Event) error {
+	if r.config.Disabled {
+		return nil
+	}
+	if event.ID == "" {
+		event.ID = uuid.NewString()
+	}
+	if event.Time.IsZero() {
+		event.Time = r.now().UTC()
+	}
+	if event.Outcome == "" {
+		event.Outcome = OutcomeSuccess
+	}
+	if event.Actor == (Actor{}) {
+		if p, ok := authn.PrincipalFromContext(ctx); ok {
+			event.Actor = Actor{Type: string(p.Type), Subject: p.Subject, AppID: p.AppID, KeyID: p.KeyID}
+		}
+	}
+	if event.RequestID == "" {
+		event.RequestID = otel.RequestIDFromContext(ctx)
+	}
+	if event.Changes == nil && len(event.Before) > 0 && len(event.After) > 0 {
+		event.Changes = Diff(event.Before, event.After)
+	}
+
+	event = detach(event)
+
+	var errs []error
+	for _, sink := range r.sinks {
+		if err := sink.Write(ctx, event); err != nil {
+			r.Obs.AddCounter(ctx, SinkErrorsMetric, 1, attribute.String("audit.action", event.Action))
+			errs = append(errs, err)
+		}
+	}
+	r.Obs.AddCounter(ctx, EventsMetric, 1,
+		attribute.String("audit.action", event.Action),
+		attribute.String("audit.outcome", string(event.Outcome)),
+	)
+	return errors.Join(errs...)
+}
+
+// detach copies the strings of event, which may point into fiber's reused
+// request buffers, so sinks can keep it after the request.
+func detach(event Event) Event {
+	for _, s := range []*string{
+		&event.Action, &event.Actor.Type, &event.Actor.Subject, &event.Actor.AppID, &event.Actor.KeyID,
+		&event.Resource.Type, &event.Resource.ID, &event.RequestID, &event.IP, &event.Method, &event.Route, &event.Error,
+	} {
+		*s = strings.Clone(*s)
+	}
+	if event.Metadata != nil {
+		metadata := make(map[string]string, len(event.Metadata))
+		for k, v := range event.Metadata {
+			metadata[strings.Clone(k)] = strings.Clone(v)
+		}
+		event.Metadata = metadata
+	}
+	return event
+}
@@ -0,0 +1,170 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	"go.uber.org/zap"
+)
+
+// Sink stores audit events. Provided types implementing Sink are picked up
+// by Providers; xgorm.AuditStore keeps them in a hash-chained table.
+type Sink interface {
+	Write(ctx context.Context, event Event) error
+}
+
+// Querier searches stored events for the query handler.
+type Querier interface {
+	// Query returns matching events newest first.
+	Query(ctx context.Context, filter Filter) ([]Event, error)
+}
+
+const DefaultQueryLimit = 100
+
+// Filter narrows Querier.Query. Zero fields match everything.
+type Filter struct {
+	Actor        string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	From         time.Time
+	To           time.Time
+	Limit        int
+}
+
+// LimitOrDefault returns Limit, or DefaultQueryLimit when it is not set.
+func (f Filter) LimitOrDefault() int {
+	if f.Limit <= 0 {
+		return DefaultQueryLimit
+	}
+	return f.Limit
+}
+
+// Matches reports whether event passes the filter. Actor matches the
+// subject, app ID or key ID.
+func (f Filter) Matches(event Event) bool {
+	if f.Actor != "" && f.Actor != event.Actor.Subject && f.Actor != event.Actor.AppID && f.Actor != event.Actor.KeyID {
+		return false
+	}
+	if f.Action != "" && f.Action != event.Action {
+		return false
+	}
+	if f.ResourceType != "" && f.ResourceType != event.Resource.Type {
+		return false
+	}
+	if f.ResourceID != "" && f.ResourceID != event.Resource.ID {
+		return false
+	}
+	if !f.From.IsZero() && event.Time.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !event.Time.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// MemorySink keeps the latest events in process memory, for tests and
+// development.
+type MemorySink struct {
+	mu       sync.Mutex
+	capacity int
+	events   []Event
+}
+
+// NewMemorySink keeps up to capacity events; zero keeps 1000.
+func NewMemorySink(capacity int) *MemorySink {
+	if capacity <= 0 {
+		capacity = 1000
+	}
+	return &MemorySink{capacity: capacity}
+}
+
+func (s *MemorySink) Write(_ context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.events) == s.capacity {
+		s.events = slices.Delete(s.events, 0, 1)
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *MemorySink) Query(_ context.Context, filter Filter) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Event
+	for i := len(s.events) - 1; i >= 0 && len(out) < filter.LimitOrDefault(); i-- {
+		if filter.Matches(s.events[i]) {
+			out = append(out, s.events[i])
+		}
+	}
+	return out, nil
+}
+
+// LogSink writes events as structured log entries, which the otel log
+// exporter ships when enabled.
+type LogSink struct {
+	obs *otel.Telemetry
+}
+
+func NewLogSink(telemetry *otel.Telemetry) *LogSink {
+	return &LogSink{obs: telemetry}
+}
+
+func (s *LogSink) Write(ctx context.Context, event Event) error {
+	_, span := s.obs.Obs.Start(ctx, "audit.log")
+	defer span.End()
+	span.Info("audit",
+		zap.String("audit.id", event.ID),
+		zap.String("audit.action", event.Action),
+		zap.String("audit.outcome", string(event.Outcome)),
+		zap.String("audit.actor.type", event.Actor.Type),
+		zap.String("audit.actor.subject", event.Actor.Subject),
+		zap.String("audit.actor.app_id", event.Actor.AppID),
+		zap.String("audit.resource.type", event.Resource.Type),
+		zap.String("audit.resource.id", event.Resource.ID),
+		zap.String("request.id", event.RequestID),
+		zap.String("client.address", event.IP),
+		zap.String("http.route", event.Route),
+		zap.Int("http.response.status_code", event.Status),
+		zap.Any("audit.changes", event.Changes),
+	)
+	return nil
+}
+
+// FileSink appends events to a file as JSON lines.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+func (s *FileSink) Write(_ context.Context, event Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(event)
+}
+
+func (s *FileSink) Stop(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.file.Sync(); err != nil {
+		_ = s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
@@ -14,7 +14,7 @@ func UseServiceCommands() di.Node {
 
 func UseBasicCommands() di.Node {
 	return di.Options(
-		di.Provide(NewHealthcheckCommand),
+		di.Provide(NewHealthcheckCommand, di.Params(``, di.Optional())),
 		di.Provide(NewVersionCommand),
 	)
 }
@@ -3,23 +3,43 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"time"
 
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	"github.com/bronystylecrazy/ultrastructure/web/httpclient"
 	"github.com/spf13/cobra"
 	"go.uber.org/fx"
 )
 
-const defaultHealthcheckURL = "http://127.0.0.1:3000/healthz"
+const defaultHealthcheckURL = "http://127.0.0.1:8080/healthz"
+
+// HealthcheckURLResolver supplies the default URL probed by the healthcheck
+// command, usually derived from the configured web address.
+type HealthcheckURLResolver interface {
+	HealthcheckURL() string
+}
 
 type HealthcheckCommand struct {
 	shutdowner fx.Shutdowner
+	resolver   HealthcheckURLResolver
 }
 
-func NewHealthcheckCommand(shutdowner fx.Shutdowner) *HealthcheckCommand {
+func NewHealthcheckCommand(shutdowner fx.Shutdowner, resolver HealthcheckURLResolver) *HealthcheckCommand {
 	return &HealthcheckCommand{
 		shutdowner: shutdowner,
+		resolver:   resolver,
+	}
+}
+
+func (s *HealthcheckCommand) defaultURL() string {
+	if s.resolver != nil {
+		if url := s.resolver.HealthcheckURL(); url != "" {
+			return url
+		}
 	}
+	return defaultHealthcheckURL
 }
 
 func (s *HealthcheckCommand) Command() *cobra.Command {
@@ -32,7 +52,7 @@ func (s *HealthcheckCommand) Command() *cobra.Command {
 			return s.shutdowner.Shutdown()
 		},
 	}
-	c.Flags().String("url", defaultHealthcheckURL, "health endpoint URL")
+	c.Flags().String("url", s.defaultURL(), "health endpoint URL")
 	c.Flags().Duration("timeout", 3*time.Second, "request timeout")
 	return c
 }
@@ -58,7 +78,18 @@ func (s *HealthcheckCommand) Run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("build request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	client, err := httpclient.New("healthcheck", httpclient.ClientConfig{
+		Timeout:        timeout,
+		Retry:          httpclient.RetryConfig{MaxAttempts: 1},
+		CircuitBreaker: httpclient.BreakerConfig{Disabled: true},
+		// The server's certificate rarely names the loopback address, and a
+		// probe of the local listener only checks that it answers.
+		TLS: otel.TLSConfig{InsecureSkipVerify: req.URL.Scheme == "https" && isLoopback(req.URL.Hostname())},
+	}, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("healthcheck request failed: %w", err)
 	}
@@ -71,3 +102,11 @@ func (s *HealthcheckCommand) Run(cmd *cobra.Command, args []string) error {
 	_, err = fmt.Fprintln(cmd.OutOrStdout(), "ok")
 	return err
 }
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthcheckProbesLocalTLSListener(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := NewHealthcheckCommand(newRecordingShutdowner(), nil).Command()
+	if err := c.Flags().Set("url", server.URL+"/healthz"); err != nil {
+		t.Fatalf("set url: %v", err)
+	}
+	var out bytes.Buffer
+	c.SetOut(&out)
+	c.SetContext(context.Background())
+	if err := c.RunE(c, nil); err != nil {
+		t.Fatalf("healthcheck over https: %v", err)
+	}
+	if out.String() != "ok\n" {
+		t.Fatalf("output: %q", out.String())
+	}
+}
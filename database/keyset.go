@@ -0,0 +1,88 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KeysetColumn is one sort key of a keyset-paginated query. Name is written
+// into SQL as is and must never come from user input.
+type KeysetColumn struct {
+	Name string
+	Desc bool
+}
+
+// KeysetWhere builds the condition selecting rows after values in columns'
+// sort order, or before them when backward is set. placeholder renders the
+// n-th (1-based) bind argument, e.g. "?" or "$n".
+//
+// Columns sharing one direction use a row comparison, (a, b) > (?, ?);
+// mixed directions expand to (a > ?) OR (a = ? AND b < ?).
+func KeysetWhere(columns []KeysetColumn, values []any, backward bool, placeholder func(n int) string) (string, []any, error) {
+	if len(columns) == 0 {
+		return "", nil, fmt.Errorf("database: keyset requires at least one column")
+	}
+	if len(values) != len(columns) {
+		return "", nil, fmt.Errorf("database: keyset has %d columns but cursor has %d keys", len(columns), len(values))
+	}
+
+	if sameDirection(columns) {
+		names := make([]string, len(columns))
+		binds := make([]string, len(columns))
+		for i, column := range columns {
+			names[i] = column.Name
+			binds[i] = placeholder(i + 1)
+		}
+		op := keysetOperator(columns[0].Desc, backward)
+		if len(columns) == 1 {
+			return names[0] + " " + op + " " + binds[0], append([]any(nil), values...), nil
+		}
+		return "(" + strings.Join(names, ", ") + ") " + op + " (" + strings.Join(binds, ", ") + ")", append([]any(nil), values...), nil
+	}
+
+	var (
+		terms []string
+		args  []any
+	)
+	for i, column := range columns {
+		parts := make([]string, 0, i+1)
+		for _, prev := range columns[:i] {
+			args = append(args, values[len(parts)])
+			parts = append(parts, prev.Name+" = "+placeholder(len(args)))
+		}
+		args = append(args, values[i])
+		parts = append(parts, column.Name+" "+keysetOperator(column.Desc, backward)+" "+placeholder(len(args)))
+		terms = append(terms, "("+strings.Join(parts, " AND ")+")")
+	}
+	return "(" + strings.Join(terms, " OR ") + ")", args, nil
+}
+
+// KeysetOrder returns the ORDER BY list for columns, reversed when backward
+// so the rows nearest the cursor come first.
+func KeysetOrder(columns []KeysetColumn, backward bool) string {
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		if column.Desc != backward {
+			parts[i] = column.Name + " DESC"
+		} else {
+			parts[i] = column.Name + " ASC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func sameDirection(columns []KeysetColumn) bool {
+	for _, column := range columns[1:] {
+		if column.Desc != columns[0].Desc {
+			return false
+		}
+	}
+	return true
+}
+
+func keysetOperator(desc, backward bool) string {
+	if desc != backward {
+		return "<"
+	}
+	return ">"
+}
@@ -0,0 +1,27 @@
+package health
+
+import "time"
+
+type Config struct {
+	Disabled      bool          `mapstructure:"disabled" default:"false"`
+	LivenessPath  string        `mapstructure:"liveness_path" default:"/healthz"`
+	ReadinessPath string        `mapstructure:"readiness_path" default:"/readyz"`
+	Timeout       time.Duration `mapstructure:"timeout" default:"2s"`
+	CacheTTL      time.Duration `mapstructure:"cache_ttl" default:"1s"`
+}
+
+const (
+	DefaultLivenessPath  = "/healthz"
+	DefaultReadinessPath = "/readyz"
+	DefaultTimeout       = 2 * time.Second
+)
+
+// withDefaults fills in the values a zero Config leaves empty. Probe paths
+// are defaulted when loading config instead, since an empty path disables
+// its probe.
+func (c Config) withDefaults() Config {
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	return c
+}
@@ -0,0 +1,50 @@
+package health
+
+import (
+	"github.com/bronystylecrazy/ultrastructure/cfg"
+	"github.com/bronystylecrazy/ultrastructure/cmd"
+	"github.com/bronystylecrazy/ultrastructure/di"
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	"github.com/bronystylecrazy/ultrastructure/web"
+)
+
+const CheckersGroupName = "us.health.checkers"
+
+var OtelScope = "health"
+
+// Providers registers the health service and the /healthz and /readyz routes.
+// Any provided type implementing Checker is picked up automatically. Set a
+// path to "" in config to disable its probe.
+func Providers(extends ...di.Node) di.Node {
+	nodes := []any{
+		di.AutoGroup[Checker](CheckersGroupName),
+		cfg.Config[Config]("health",
+			cfg.WithSourceFile("config.toml"),
+			cfg.WithType("toml"),
+			cfg.WithDefault("health.liveness_path", DefaultLivenessPath),
+			cfg.WithDefault("health.readiness_path", DefaultReadinessPath),
+		),
+		di.Provide(NewService, di.VariadicGroup(CheckersGroupName), otel.Layer(OtelScope)),
+		di.Provide(NewHandler, web.Priority(web.Earlier)),
+		di.Provide(NewTarget, di.As[cmd.HealthcheckURLResolver]()),
+	}
+	nodes = append(nodes, di.ConvertAnys(extends)...)
+	return di.Options(nodes...)
+}
+
+// WithChecks registers function checks with the health service.
+//
+// Usage: health.WithChecks(health.NewCheck("queue", q.Ping))
+func WithChecks(checks ...*Check) di.Node {
+	nodes := make([]any, 0, len(checks))
+	for _, check := range checks {
+		if check == nil {
+			continue
+		}
+		nodes = append(nodes, di.Provide(
+			func() Checker { return check },
+			di.Group(CheckersGroupName),
+		))
+	}
+	return di.Options(nodes...)
+}
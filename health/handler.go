@@ -0,0 +1,59 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
+
+// Handler exposes the liveness and readiness probes over HTTP.
+type Handler struct {
+	config  Config
+	service *Service
+}
+
+func NewHandler(config Config, service *Service) *Handler {
+	return &Handler{
+		config:  config,
+		service: service,
+	}
+}
+
+func (h *Handler) Handle(r web.Router) {
+	if h.config.Disabled {
+		return
+	}
+	if path := h.config.LivenessPath; path != "" {
+		r.Get(path, h.Liveness).
+			Public().
+			Tags("Health").
+			Summary("Liveness probe").
+			Ok(Report{}).
+			ProducesWithDescription(Report{}, http.StatusServiceUnavailable, "A liveness check failed")
+	}
+	if path := h.config.ReadinessPath; path != "" {
+		r.Get(path, h.Readiness).
+			Public().
+			Tags("Health").
+			Summary("Readiness probe").
+			Ok(Report{}).
+			ProducesWithDescription(Report{}, http.StatusServiceUnavailable, "A readiness check failed")
+	}
+}
+
+func (h *Handler) Liveness(c fiber.Ctx) error {
+	return writeReport(c, h.service.Liveness(c.Context()))
+}
+
+func (h *Handler) Readiness(c fiber.Ctx) error {
+	return writeReport(c, h.service.Readiness(c.Context()))
+}
+
+func writeReport(c fiber.Ctx, report Report) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if !report.Up() {
+		c.Status(http.StatusServiceUnavailable)
+	}
+	return c.JSON(report)
+}
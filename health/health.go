@@ -0,0 +1,129 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Probe selects which endpoints a checker contributes to.
+type Probe uint8
+
+const (
+	// Liveness checks decide whether the process should be restarted.
+	Liveness Probe = 1 << iota
+	// Readiness checks decide whether the process should receive traffic.
+	Readiness
+)
+
+// Checker reports the health of one dependency.
+//
+// Checkers are collected from the container via di.AutoGroup, so providing a
+// type that implements Checker is enough to register it.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// ProbeChecker lets a checker choose its probes. Checkers that do not
+// implement it only take part in readiness.
+type ProbeChecker interface {
+	Probes() Probe
+}
+
+// TimeoutChecker overrides the default per-check timeout.
+type TimeoutChecker interface {
+	Timeout() time.Duration
+}
+
+// CacheChecker overrides how long a check result is reused.
+type CacheChecker interface {
+	CacheTTL() time.Duration
+}
+
+// Check is a Checker built from a function.
+type Check struct {
+	name     string
+	fn       func(ctx context.Context) error
+	probes   Probe
+	timeout  time.Duration
+	cacheTTL time.Duration
+}
+
+type CheckOption func(*Check)
+
+// WithProbes sets the probes the check contributes to.
+func WithProbes(probes Probe) CheckOption {
+	return func(c *Check) {
+		c.probes = probes
+	}
+}
+
+// WithTimeout bounds a single run of the check.
+func WithTimeout(timeout time.Duration) CheckOption {
+	return func(c *Check) {
+		c.timeout = timeout
+	}
+}
+
+// WithCacheTTL reuses the last result for ttl. A negative ttl disables caching.
+func WithCacheTTL(ttl time.Duration) CheckOption {
+	return func(c *Check) {
+		c.cacheTTL = ttl
+	}
+}
+
+// NewCheck creates a readiness check from fn.
+//
+// Usage: health.NewCheck("queue", q.Ping, health.WithTimeout(time.Second))
+func NewCheck(name string, fn func(ctx context.Context) error, opts ...CheckOption) *Check {
+	c := &Check{
+		name:   name,
+		fn:     fn,
+		probes: Readiness,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	return c
+}
+
+func (c *Check) Name() string {
+	return c.name
+}
+
+func (c *Check) Check(ctx context.Context) error {
+	if c.fn == nil {
+		return nil
+	}
+	return c.fn(ctx)
+}
+
+func (c *Check) Probes() Probe {
+	return c.probes
+}
+
+func (c *Check) Timeout() time.Duration {
+	return c.timeout
+}
+
+func (c *Check) CacheTTL() time.Duration {
+	return c.cacheTTL
+}
+
+// Pinger is implemented by clients such as *pgxpool.Pool.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// NewPingCheck creates a readiness check that pings p.
+func NewPingCheck(name string, p Pinger, opts ...CheckOption) *Check {
+	return NewCheck(name, p.Ping, opts...)
+}
+
+// NewSQLCheck creates a readiness check that pings a database/sql pool.
+func NewSQLCheck(name string, db *sql.DB, opts ...CheckOption) *Check {
+	return NewCheck(name, db.PingContext, opts...)
+}
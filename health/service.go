@@ -0,0 +1,194 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	"go.uber.org/zap"
+)
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Status    Status    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report aggregates the results of a probe.
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Up reports whether every check passed.
+func (r Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+type cachedResult struct {
+	result    Result
+	expiresAt time.Time
+}
+
+// Service runs the registered checkers for liveness and readiness probes.
+type Service struct {
+	otel.Telemetry
+
+	config Config
+
+	mu       sync.RWMutex
+	checkers []Checker
+
+	cacheMu sync.Mutex
+	cache   map[string]cachedResult
+}
+
+func NewService(config Config, checkers ...Checker) *Service {
+	s := &Service{
+		Telemetry: otel.Nop(),
+		config:    config.withDefaults(),
+		cache:     make(map[string]cachedResult),
+	}
+	s.Add(checkers...)
+	return s
+}
+
+// Add registers more checkers.
+func (s *Service) Add(checkers ...Checker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, checker := range checkers {
+		if checker != nil {
+			s.checkers = append(s.checkers, checker)
+		}
+	}
+}
+
+// Liveness runs the checks registered for the liveness probe.
+func (s *Service) Liveness(ctx context.Context) Report {
+	return s.run(ctx, Liveness)
+}
+
+// Readiness runs the checks registered for the readiness probe.
+func (s *Service) Readiness(ctx context.Context) Report {
+	return s.run(ctx, Readiness)
+}
+
+func (s *Service) run(ctx context.Context, probe Probe) Report {
+	s.mu.RLock()
+	checkers := make([]Checker, 0, len(s.checkers))
+	for _, checker := range s.checkers {
+		if probesOf(checker)&probe != 0 {
+			checkers = append(checkers, checker)
+		}
+	}
+	s.mu.RUnlock()
+
+	report := Report{
+		Status: StatusUp,
+		Checks: make(map[string]Result, len(checkers)),
+	}
+	results := make([]Result, len(checkers))
+
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.check(ctx, checker)
+		}()
+	}
+	wg.Wait()
+
+	for i, checker := range checkers {
+		report.Checks[checker.Name()] = results[i]
+		if results[i].Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+func (s *Service) check(ctx context.Context, checker Checker) Result {
+	name := checker.Name()
+	ttl := s.config.CacheTTL
+	if c, ok := checker.(CacheChecker); ok && c.CacheTTL() != 0 {
+		ttl = c.CacheTTL()
+	}
+
+	if ttl > 0 {
+		s.cacheMu.Lock()
+		cached, ok := s.cache[name]
+		s.cacheMu.Unlock()
+		if ok && time.Now().Before(cached.expiresAt) {
+			return cached.result
+		}
+	}
+
+	timeout := s.config.Timeout
+	if c, ok := checker.(TimeoutChecker); ok && c.Timeout() > 0 {
+		timeout = c.Timeout()
+	}
+	checkCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		checkCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	startedAt := time.Now()
+	err := runCheck(checkCtx, checker)
+	result := Result{
+		Status:    StatusUp,
+		Duration:  time.Since(startedAt).String(),
+		CheckedAt: startedAt,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+		s.Obs.Warn("health check failed", zap.String("check", name), zap.Error(err))
+	}
+
+	if ttl > 0 {
+		s.cacheMu.Lock()
+		s.cache[name] = cachedResult{result: result, expiresAt: startedAt.Add(ttl)}
+		s.cacheMu.Unlock()
+	}
+	return result
+}
+
+// runCheck stops waiting once ctx is done, so a checker that ignores its
+// context cannot hold the probe past its timeout.
+func runCheck(ctx context.Context, checker Checker) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- checker.Check(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return errors.New("check timed out")
+		}
+		return ctx.Err()
+	}
+}
+
+func probesOf(checker Checker) Probe {
+	if c, ok := checker.(ProbeChecker); ok {
+		return c.Probes()
+	}
+	return Readiness
+}
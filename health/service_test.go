@@ -0,0 +1,167 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestServiceSplitsLivenessAndReadiness(t *testing.T) {
+	svc := NewService(Config{},
+		NewCheck("process", func(context.Context) error { return nil }, WithProbes(Liveness)),
+		NewCheck("database", func(context.Context) error { return errors.New("connection refused") }),
+	)
+
+	live := svc.Liveness(context.Background())
+	if !live.Up() || len(live.Checks) != 1 {
+		t.Fatalf("unexpected liveness report: %+v", live)
+	}
+
+	ready := svc.Readiness(context.Background())
+	if ready.Up() {
+		t.Fatalf("expected readiness to be down: %+v", ready)
+	}
+	if got := ready.Checks["database"]; got.Status != StatusDown || got.Error != "connection refused" {
+		t.Fatalf("unexpected database result: %+v", got)
+	}
+	if _, ok := ready.Checks["process"]; ok {
+		t.Fatalf("liveness-only check ran for readiness: %+v", ready)
+	}
+}
+
+func TestServiceTimesOutSlowChecks(t *testing.T) {
+	svc := NewService(Config{Timeout: 20 * time.Millisecond},
+		NewCheck("slow", func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}),
+	)
+
+	startedAt := time.Now()
+	report := svc.Readiness(context.Background())
+	if elapsed := time.Since(startedAt); elapsed > 500*time.Millisecond {
+		t.Fatalf("probe waited too long: %s", elapsed)
+	}
+	if got := report.Checks["slow"]; got.Status != StatusDown || got.Error != "check timed out" {
+		t.Fatalf("unexpected slow result: %+v", got)
+	}
+}
+
+func TestServiceCachesResults(t *testing.T) {
+	var calls atomic.Int32
+	svc := NewService(Config{CacheTTL: time.Minute},
+		NewCheck("redis", func(context.Context) error {
+			calls.Add(1)
+			return nil
+		}),
+		NewCheck("uncached", func(context.Context) error {
+			calls.Add(100)
+			return nil
+		}, WithCacheTTL(-1)),
+	)
+
+	svc.Readiness(context.Background())
+	svc.Readiness(context.Background())
+	if got := calls.Load(); got != 201 {
+		t.Fatalf("calls: got=%d want=%d", got, 201)
+	}
+}
+
+func TestHandlerReportsStatus(t *testing.T) {
+	var healthy atomic.Bool
+	svc := NewService(Config{},
+		NewCheck("queue", func(context.Context) error {
+			if healthy.Load() {
+				return nil
+			}
+			return errors.New("queue unavailable")
+		}),
+	)
+
+	app := fiber.New()
+	NewHandler(Config{LivenessPath: "/healthz", ReadinessPath: "/readyz"}, svc).
+		Handle(web.NewRouterWithRegistry(app, web.NewMetadataRegistry()))
+
+	res, body := doProbe(t, app, "/readyz")
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusServiceUnavailable)
+	}
+	if body.Status != StatusDown {
+		t.Fatalf("unexpected report: %+v", body)
+	}
+
+	healthy.Store(true)
+	res, body = doProbe(t, app, "/readyz")
+	if res.StatusCode != http.StatusOK || body.Status != StatusUp {
+		t.Fatalf("unexpected readiness: status=%d report=%+v", res.StatusCode, body)
+	}
+	if cc := res.Header.Get(fiber.HeaderCacheControl); cc != "no-store" {
+		t.Fatalf("cache control: got=%q", cc)
+	}
+
+	res, _ = doProbe(t, app, "/healthz")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("liveness status: got=%d want=%d", res.StatusCode, http.StatusOK)
+	}
+}
+
+func TestTargetUsesWebAddress(t *testing.T) {
+	webConfig := web.Config{}
+	webConfig.Server.Host = "0.0.0.0"
+	webConfig.Server.Port = 9090
+
+	got := NewTarget(webConfig, Config{LivenessPath: "/livez"}).HealthcheckURL()
+	if want := "http://127.0.0.1:9090/livez"; got != want {
+		t.Fatalf("url: got=%q want=%q", got, want)
+	}
+
+	webConfig.TLS.CertFile = "server.crt"
+	got = NewTarget(webConfig, Config{LivenessPath: "/livez"}).HealthcheckURL()
+	if want := "https://127.0.0.1:9090/livez"; got != want {
+		t.Fatalf("tls url: got=%q want=%q", got, want)
+	}
+}
+
+func doProbe(t *testing.T, app *fiber.App, path string) (*http.Response, Report) {
+	t.Helper()
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	var report Report
+	if err := json.Unmarshal(raw, &report); err != nil {
+		t.Fatalf("decode report %q: %v", raw, err)
+	}
+	return res, report
+}
+
+func TestHandlerSkipsProbesWithEmptyPath(t *testing.T) {
+	app := fiber.New()
+	NewHandler(Config{ReadinessPath: "/readyz"}, NewService(Config{})).
+		Handle(web.NewRouterWithRegistry(app, web.NewMetadataRegistry()))
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("disabled liveness status: got=%d want=%d", res.StatusCode, http.StatusNotFound)
+	}
+	if res, _ := doProbe(t, app, "/readyz"); res.StatusCode != http.StatusOK {
+		t.Fatalf("readiness status: got=%d want=%d", res.StatusCode, http.StatusOK)
+	}
+}
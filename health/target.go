@@ -0,0 +1,52 @@
+package health
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+)
+
+// Target resolves the local liveness URL from the web server address so the
+// healthcheck command probes the right port without flags. It uses https when
+// web.tls has a certificate.
+type Target struct {
+	host string
+	port int
+	path string
+	tls  bool
+}
+
+func NewTarget(webConfig web.Config, config Config) *Target {
+	return &Target{
+		host: webConfig.Server.Host,
+		port: webConfig.Server.Port,
+		path: config.LivenessPath,
+		tls:  strings.TrimSpace(webConfig.TLS.CertFile) != "",
+	}
+}
+
+func (t *Target) HealthcheckURL() string {
+	host := strings.TrimSpace(t.host)
+	switch host {
+	case "", "0.0.0.0", "::", "[::]":
+		host = "127.0.0.1"
+	}
+	port := t.port
+	if port <= 0 {
+		port = 8080
+	}
+	path := t.path
+	if path == "" {
+		path = "/healthz"
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	scheme := "http://"
+	if t.tls {
+		scheme = "https://"
+	}
+	return scheme + net.JoinHostPort(strings.Trim(host, "[]"), strconv.Itoa(port)) + path
+}
@@ -0,0 +1,284 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+	"github.com/pelletier/go-toml/v2"
+)
+
+//go:embed locales/*.toml
+var builtin embed.FS
+
+// Source is a directory of catalog files, typically in an embed.FS.
+type Source struct {
+	FS  fs.FS
+	Dir string
+}
+
+// Catalog holds messages by locale and key. Nested tables in catalog files
+// become dotted keys, e.g. [errors] ORDER_NOT_FOUND is
+// "errors.ORDER_NOT_FOUND". Messages may use {name} placeholders.
+//
+// It is the web.Translator of every request and negotiates the request
+// locale from Accept-Language.
+type Catalog struct {
+	config Config
+
+	mu       sync.RWMutex
+	locales  []string
+	messages map[string]map[string]string
+}
+
+var defaultCatalog atomic.Pointer[Catalog]
+
+// NewCatalog loads the built-in English and Thai messages, then every
+// source in order, later files overriding earlier ones.
+func NewCatalog(config Config, sources ...Source) (*Catalog, error) {
+	c := &Catalog{
+		config:   config.withDefaults(),
+		messages: make(map[string]map[string]string),
+	}
+	if err := c.Load(builtin, "locales"); err != nil {
+		return nil, err
+	}
+	for _, source := range sources {
+		if source.FS == nil {
+			continue
+		}
+		if err := c.Load(source.FS, source.Dir); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Load reads every <locale>.toml and <locale>.json file in dir of fsys,
+// e.g. "th.toml" or "en-US.json".
+func (c *Catalog) Load(fsys fs.FS, dir string) error {
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("i18n: read %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".toml" && ext != ".json") {
+			continue
+		}
+		file := path.Join(dir, entry.Name())
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("i18n: read %s: %w", file, err)
+		}
+		var doc map[string]any
+		if ext == ".toml" {
+			err = toml.Unmarshal(data, &doc)
+		} else {
+			err = json.Unmarshal(data, &doc)
+		}
+		if err != nil {
+			return fmt.Errorf("i18n: parse %s: %w", file, err)
+		}
+		messages := make(map[string]string)
+		if err := flatten("", doc, messages); err != nil {
+			return fmt.Errorf("i18n: %s: %w", file, err)
+		}
+		c.Add(strings.TrimSuffix(entry.Name(), ext), messages)
+	}
+	return nil
+}
+
+func flatten(prefix string, doc map[string]any, out map[string]string) error {
+	for key, value := range doc {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case string:
+			out[key] = v
+		case map[string]any:
+			if err := flatten(key, v, out); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("message %q is %T, not a string", key, value)
+		}
+	}
+	return nil
+}
+
+// Add merges messages into locale, replacing existing keys.
+func (c *Catalog) Add(locale string, messages map[string]string) {
+	id := normalize(locale)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	catalog, ok := c.messages[id]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		c.messages[id] = catalog
+		c.locales = append(c.locales, locale)
+	}
+	for key, message := range messages {
+		catalog[key] = message
+	}
+}
+
+// Locales returns the loaded locales in load order.
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.locales...)
+}
+
+// Message returns the message for key in locale, falling back to its base
+// language and then to the default locale, with {name} placeholders
+// replaced from args.
+func (c *Catalog) Message(locale, key string, args map[string]string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, candidate := range []string{locale, base(locale), c.config.DefaultLocale, base(c.config.DefaultLocale)} {
+		if message, ok := c.messages[normalize(candidate)][key]; ok {
+			return format(message, args), true
+		}
+	}
+	return "", false
+}
+
+// Translate implements web.Translator with the locale of ctx.
+func (c *Catalog) Translate(ctx context.Context, key string, args map[string]string) (string, bool) {
+	return c.Message(Locale(ctx), key, args)
+}
+
+// Match returns the loaded locale best matching an Accept-Language value,
+// or the default locale. "th-TH" matches a "th" catalog and "en" matches
+// "en-US".
+func (c *Catalog) Match(acceptLanguage string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if tag == "*" {
+			break
+		}
+		if locale, ok := c.lookup(tag); ok {
+			return locale
+		}
+		if locale, ok := c.lookup(base(tag)); ok {
+			return locale
+		}
+		for _, locale := range c.locales {
+			if base(locale) == base(tag) {
+				return locale
+			}
+		}
+	}
+	return c.config.DefaultLocale
+}
+
+func (c *Catalog) lookup(tag string) (string, bool) {
+	for _, locale := range c.locales {
+		if normalize(locale) == tag {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// Start makes the catalog the one T uses outside requests.
+func (c *Catalog) Start(context.Context) error {
+	defaultCatalog.Store(c)
+	return nil
+}
+
+func (c *Catalog) Stop(context.Context) error {
+	defaultCatalog.CompareAndSwap(c, nil)
+	return nil
+}
+
+// Handle negotiates the locale of every request, placing it and the catalog
+// on the request context and sending Content-Language.
+func (c *Catalog) Handle(router web.Router) {
+	router.Use(c.Middleware)
+}
+
+func (c *Catalog) Middleware(ctx fiber.Ctx) error {
+	locale := ""
+	if c.config.QueryParam != "" {
+		if lang := ctx.Query(c.config.QueryParam); lang != "" {
+			locale = c.Match(lang)
+		}
+	}
+	if locale == "" {
+		locale = c.Match(ctx.Get(fiber.HeaderAcceptLanguage))
+	}
+	ctx.SetContext(WithCatalog(WithLocale(ctx.Context(), locale), c))
+	web.SetTranslatorLocals(ctx, c)
+	ctx.Set(fiber.HeaderContentLanguage, locale)
+	ctx.Vary(fiber.HeaderAcceptLanguage)
+	return ctx.Next()
+}
+
+// parseAcceptLanguage returns the normalized tags of value by descending q,
+// dropping those with q=0.
+func parseAcceptLanguage(value string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(value, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = normalize(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag: tag, q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = t.tag
+	}
+	return out
+}
+
+func normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
+
+func base(locale string) string {
+	language, _, _ := strings.Cut(normalize(locale), "-")
+	return language
+}
+
+func format(message string, args map[string]string) string {
+	if len(args) == 0 || !strings.Contains(message, "{") {
+		return message
+	}
+	pairs := make([]string, 0, len(args)*2)
+	for name, value := range args {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(message)
+}
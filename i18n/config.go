@@ -0,0 +1,19 @@
+package i18n
+
+type Config struct {
+	// DefaultLocale is used when Accept-Language matches no loaded locale.
+	DefaultLocale string `mapstructure:"default_locale" default:"en"`
+	// QueryParam names a query parameter that overrides Accept-Language,
+	// e.g. "lang" for ?lang=th. Empty disables it.
+	QueryParam string `mapstructure:"query_param"`
+}
+
+const DefaultLocale = "en"
+
+// withDefaults fills in the values a zero Config leaves empty.
+func (c Config) withDefaults() Config {
+	if c.DefaultLocale == "" {
+		c.DefaultLocale = DefaultLocale
+	}
+	return c
+}
@@ -0,0 +1,33 @@
+package i18n
+
+import (
+	"io/fs"
+	"math"
+
+	"github.com/bronystylecrazy/ultrastructure/cfg"
+	"github.com/bronystylecrazy/ultrastructure/di"
+	"github.com/bronystylecrazy/ultrastructure/web"
+)
+
+const SourcesGroupName = "us.i18n.sources"
+
+// Providers registers the message catalog and the Accept-Language
+// middleware, which localizes error messages and validation details.
+//
+// Usage: us.New(i18n.Providers(i18n.UseFS(locales, "locales")), ...)
+func Providers(extends ...di.Node) di.Node {
+	nodes := []any{
+		cfg.Config[Config]("i18n", cfg.WithSourceFile("config.toml"), cfg.WithType("toml")),
+		// Negotiate ahead of every web middleware, recover included, so the
+		// errors of CSRF, rate limits, body limits and the like are localized.
+		di.Provide(NewCatalog, di.VariadicGroup(SourcesGroupName), web.Priority(math.MinInt32-5)),
+	}
+	nodes = append(nodes, di.ConvertAnys(extends)...)
+	return di.Options(nodes...)
+}
+
+// UseFS adds the catalog files in dir of fsys, typically embedded with
+// //go:embed locales/*.toml.
+func UseFS(fsys fs.FS, dir string) di.Node {
+	return di.Supply(Source{FS: fsys, Dir: dir}, di.Group(SourcesGroupName))
+}
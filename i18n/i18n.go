@@ -0,0 +1,58 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+)
+
+type localeKey struct{}
+
+type catalogKey struct{}
+
+// WithLocale returns ctx carrying locale for T and the request's Translator.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// Locale returns the locale negotiated for the request of ctx, or "" outside
+// one, in which case messages use the default locale.
+func Locale(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// WithCatalog returns ctx carrying c for T.
+func WithCatalog(ctx context.Context, c *Catalog) context.Context {
+	return context.WithValue(ctx, catalogKey{}, c)
+}
+
+// FromContext returns the catalog of ctx, the started app catalog, or nil.
+func FromContext(ctx context.Context) *Catalog {
+	if c, ok := ctx.Value(catalogKey{}).(*Catalog); ok && c != nil {
+		return c
+	}
+	return defaultCatalog.Load()
+}
+
+// T translates key for the locale of ctx, with args as name/value pairs
+// filling {name} placeholders. It returns key when there is no translation.
+//
+// Usage: msg := i18n.T(ctx, "order.shipped", "id", order.ID)
+func T(ctx context.Context, key string, args ...any) string {
+	c := FromContext(ctx)
+	if c == nil {
+		return key
+	}
+	var named map[string]string
+	if len(args) > 0 {
+		named = make(map[string]string, len(args)/2)
+		for i := 0; i+1 < len(args); i += 2 {
+			named[fmt.Sprint(args[i])] = fmt.Sprint(args[i+1])
+		}
+	}
+	message, ok := c.Message(Locale(ctx), key, named)
+	if !ok {
+		return key
+	}
+	return message
+}
@@ -0,0 +1,134 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/bronystylecrazy/ultrastructure/di"
+	"github.com/bronystylecrazy/ultrastructure/ustest"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
+
+var testLocales = fstest.MapFS{
+	"locales/th.toml": {Data: []byte(`
+[errors]
+ORDER_NOT_FOUND = "ไม่พบคำสั่งซื้อ"
+
+[fields]
+email = "อีเมล"
+
+[field_validation.quantity]
+gte = "ต้องสั่งอย่างน้อย {param} ชิ้น"
+
+[order]
+created = "สร้างคำสั่งซื้อ {id} แล้ว"
+`)},
+	"locales/en.json": {Data: []byte(`{"order": {"created": "Order {id} created"}}`)},
+}
+
+type createOrderRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Quantity int    `json:"quantity" validate:"gte=1"`
+}
+
+type testOrderHandler struct{}
+
+func (testOrderHandler) Create(ctx context.Context, req createOrderRequest) (fiber.Map, error) {
+	return fiber.Map{"message": T(ctx, "order.created", "id", "o-1")}, nil
+}
+
+func (testOrderHandler) Get(ctx context.Context, _ struct{}) (fiber.Map, error) {
+	return nil, web.NewError(http.StatusNotFound, "ORDER_NOT_FOUND", "order not found")
+}
+
+func (h testOrderHandler) Handle(r web.Router) {
+	r.Post("/orders").With(web.Handle(h.Create))
+	r.Get("/orders/missing").With(web.HandleParams(h.Get))
+}
+
+func TestLocalizesErrorsValidationAndMessages(t *testing.T) {
+	app := ustest.Start(t,
+		Providers(UseFS(testLocales, "locales")),
+		di.Provide(func() testOrderHandler { return testOrderHandler{} }),
+		web.InitHandlers(),
+	)
+	client := app.HTTP()
+
+	res := client.Post("/orders").Header("Accept-Language", "th-TH,th;q=0.9,en;q=0.5").
+		JSON(fiber.Map{"quantity": 0}).
+		Expect(http.StatusUnprocessableEntity).
+		JSONPath("error.message", "ข้อมูลไม่ผ่านการตรวจสอบ").
+		JSONPath("error.details", []string{"จำเป็นต้องระบุ อีเมล", "ต้องสั่งอย่างน้อย 1 ชิ้น"})
+	if got := res.Header.Get(fiber.HeaderContentLanguage); got != "th" {
+		t.Fatalf("Content-Language = %q", got)
+	}
+
+	client.Post("/orders").Header("Accept-Language", "en-US").
+		JSON(fiber.Map{"email": "nope", "quantity": 1}).
+		Expect(http.StatusUnprocessableEntity).
+		JSONPath("error.message", "validation failed").
+		JSONPath("error.details", []string{"email must be a valid email address"})
+
+	client.Post("/orders").Header("Accept-Language", "th").
+		JSON(fiber.Map{"email": "a@example.com", "quantity": 1}).
+		Expect(http.StatusCreated).
+		JSONPath("message", "สร้างคำสั่งซื้อ o-1 แล้ว")
+	client.Post("/orders").
+		JSON(fiber.Map{"email": "a@example.com", "quantity": 1}).
+		Expect(http.StatusCreated).
+		JSONPath("message", "Order o-1 created")
+
+	client.Get("/orders/missing").Header("Accept-Language", "th").Expect(http.StatusNotFound).JSONPath("error.message", "ไม่พบคำสั่งซื้อ")
+	client.Get("/orders/missing").Header("Accept-Language", "fr").Expect(http.StatusNotFound).JSONPath("error.message", "order not found")
+}
+
+func TestLocalizesErrorsOfEarlyMiddlewares(t *testing.T) {
+	t.Setenv("WEB_CSRF_ENABLED", "true")
+	app := ustest.Start(t,
+		Providers(UseFS(testLocales, "locales")),
+		di.Provide(func() testOrderHandler { return testOrderHandler{} }),
+		web.InitHandlers(),
+	)
+
+	app.HTTP().Post("/orders").Header("Accept-Language", "th").
+		Header("Cookie", "access_token=t-1").
+		JSON(fiber.Map{"email": "a@example.com", "quantity": 1}).
+		Expect(http.StatusForbidden).
+		JSONPath("error.code", "CSRF_TOKEN_INVALID").
+		JSONPath("error.message", "ไม่พบ CSRF token หรือ token ไม่ถูกต้อง")
+}
+
+func TestCatalogMatchesAcceptLanguage(t *testing.T) {
+	c, err := NewCatalog(Config{})
+	if err != nil {
+		t.Fatalf("NewCatalog: %v", err)
+	}
+	c.Add("en-US", map[string]string{"hello": "Howdy"})
+
+	cases := map[string]string{
+		"":                   "en",
+		"th-TH":              "th",
+		"fr, th;q=0.8":       "th",
+		"en;q=0.5, th;q=0.9": "th",
+		"EN_us":              "en-US",
+		"th;q=0, de":         "en",
+		"*":                  "en",
+	}
+	for header, want := range cases {
+		if got := c.Match(header); got != want {
+			t.Fatalf("Match(%q) = %q, want %q", header, got, want)
+		}
+	}
+	if got, _ := c.Message("en-GB", "hello", nil); got != "" {
+		t.Fatalf("en-GB fell back to en-US: %q", got)
+	}
+	if got := T(WithCatalog(WithLocale(context.Background(), "en-US"), c), "hello"); got != "Howdy" {
+		t.Fatalf("T = %q", got)
+	}
+	if got := T(context.Background(), "missing.key"); got != "missing.key" {
+		t.Fatalf("T without catalog = %q", got)
+	}
+}
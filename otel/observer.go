@@ -110,14 +110,11 @@ func ZapFieldsFromContext(ctx context.Context, buf *[4]zap.Field) []zap.Field {
 		return nil
 	}
 	n := 0
-	span := trace.SpanFromContext(ctx)
-	if span == nil {
-		if n == 0 {
-			return nil
-		}
-		return buf[:n]
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		buf[n] = zap.String("request.id", requestID)
+		n++
 	}
-	spanCtx := span.SpanContext()
+	spanCtx := trace.SpanContextFromContext(ctx)
 	if !spanCtx.IsValid() {
 		if n == 0 {
 			return nil
@@ -176,7 +173,12 @@ func (o *Observer) Span(ctx context.Context, name string, opts ...trace.SpanStar
 			zap.String("span.id", span.SpanContext().SpanID().String()),
 			zap.Bool("trace.sampled", span.SpanContext().IsSampled()),
 		}
+		if requestID := RequestIDFromContext(ctx); requestID != "" {
+			fields = append(fields, zap.String("request.id", requestID))
+		}
 		enrichedLogger = o.Logger.With(fields...)
+	} else if requestID := RequestIDFromContext(ctx); requestID != "" {
+		enrichedLogger = o.Logger.With(zap.String("request.id", requestID))
 	}
 
 	// Store enriched obs back in context
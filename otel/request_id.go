@@ -0,0 +1,27 @@
+package otel
+
+import (
+	"context"
+	"strings"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID stores the request correlation id in context so that
+// ContextFunc and spans started from ctx can attach it to log lines.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	requestID = strings.TrimSpace(requestID)
+	if ctx == nil || requestID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request correlation id stored in ctx.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
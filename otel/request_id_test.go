@@ -0,0 +1,36 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestContextFuncIncludesRequestID(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-123")
+
+	fields := ContextFunc(ctx)
+	if len(fields) != 1 || fields[0].Key != "request.id" || fields[0].String != "req-123" {
+		t.Fatalf("unexpected fields without span: %+v", fields)
+	}
+
+	tracer := sdktrace.NewTracerProvider().Tracer("test")
+	ctx, span := tracer.Start(ctx, "op")
+	defer span.End()
+
+	fields = ContextFunc(ctx)
+	if len(fields) != 4 || fields[0].Key != "request.id" || fields[1].Key != "trace.id" {
+		t.Fatalf("unexpected fields with span: %+v", fields)
+	}
+}
+
+func TestWithRequestIDIgnoresBlankIDs(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "  ")
+	if got := RequestIDFromContext(ctx); got != "" {
+		t.Fatalf("request id: got=%q want empty", got)
+	}
+	if fields := ContextFunc(ctx); fields != nil {
+		t.Fatalf("expected no fields, got %+v", fields)
+	}
+}
@@ -77,7 +77,7 @@ func UseTCPListener() di.Node {
 
 func UseWebsocketListener(opts ...Option) di.Node {
 	return di.Options(
-		di.Provide(func(auth Authorizer, cfg Config, broker usmqtt.Broker) *Websocket {
+		di.Provide(func(auth Authorizer, cfg Config, broker usmqtt.Broker, drain *web.Drain) *Websocket {
 			if _, ok := broker.(*usmqtt.Server); !ok {
 				return nil
 			}
@@ -96,11 +96,12 @@ func UseWebsocketListener(opts ...Option) di.Node {
 				WithAuthorizer(auth),
 				WithId(id),
 				WithPath(path),
+				WithDrain(drain),
 			}
 			return NewWebsocketWithOptions(append(base, opts...)...)
 			// An app that serves no authorizer still gets a websocket listener;
 			// the config and the broker are always present.
-		}, web.Priority(web.Later), di.Params(di.Optional(), ``, ``)),
+		}, web.Priority(web.Later), di.Params(di.Optional(), ``, ``, di.Optional())),
 	)
 }
 
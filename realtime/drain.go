@@ -0,0 +1,95 @@
+package realtime
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/packets"
+)
+
+// DrainHook tracks every MQTT client session in the web Drain and disconnects
+// them once draining begins, so TCP clients are drained like websockets.
+type DrainHook struct {
+	mqtt.HookBase
+	drain *web.Drain
+
+	mu      sync.Mutex
+	clients map[*mqtt.Client]func()
+	stop    func() bool
+}
+
+// NewDrainHook returns a hook that does nothing when drain is nil.
+func NewDrainHook(drain *web.Drain) *DrainHook {
+	h := &DrainHook{
+		drain:   drain,
+		clients: make(map[*mqtt.Client]func()),
+	}
+	if drain != nil {
+		h.stop = context.AfterFunc(drain.Context(), h.disconnectAll)
+	}
+	return h
+}
+
+func (h *DrainHook) ID() string {
+	return "us-drain-hook"
+}
+
+func (h *DrainHook) Provides(b byte) bool {
+	if h.drain == nil {
+		return false
+	}
+	return b == mqtt.OnSessionEstablished || b == mqtt.OnDisconnect || b == mqtt.OnStopped
+}
+
+func (h *DrainHook) OnSessionEstablished(cl *mqtt.Client, _ packets.Packet) {
+	if cl.Net.Inline {
+		return
+	}
+	if h.drain.Draining() {
+		cl.Stop(packets.ErrServerShuttingDown)
+		return
+	}
+
+	h.mu.Lock()
+	h.clients[cl] = h.drain.Track()
+	h.mu.Unlock()
+}
+
+func (h *DrainHook) OnDisconnect(cl *mqtt.Client, _ error, _ bool) {
+	h.mu.Lock()
+	release, ok := h.clients[cl]
+	delete(h.clients, cl)
+	h.mu.Unlock()
+
+	if ok {
+		release()
+	}
+}
+
+func (h *DrainHook) OnStopped() {
+	h.stop()
+
+	h.mu.Lock()
+	clients := h.clients
+	h.clients = make(map[*mqtt.Client]func())
+	h.mu.Unlock()
+
+	for _, release := range clients {
+		release()
+	}
+}
+
+func (h *DrainHook) disconnectAll() {
+	h.mu.Lock()
+	clients := make([]*mqtt.Client, 0, len(h.clients))
+	for cl := range h.clients {
+		clients = append(clients, cl)
+	}
+	h.mu.Unlock()
+
+	for _, cl := range clients {
+		cl.Stop(packets.ErrServerShuttingDown)
+	}
+}
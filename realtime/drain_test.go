@@ -0,0 +1,78 @@
+package realtime
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	usmqtt "github.com/bronystylecrazy/ultrastructure/realtime/mqtt"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+)
+
+func TestDrainHookDisconnectsTCPClients(t *testing.T) {
+	server, err := usmqtt.NewServer(slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	drain := web.NewDrain()
+	if err := server.AddHook(new(auth.AllowHook), nil); err != nil {
+		t.Fatalf("AddHook allow: %v", err)
+	}
+	if err := server.AddHook(NewDrainHook(drain), nil); err != nil {
+		t.Fatalf("AddHook drain: %v", err)
+	}
+	tcp := listeners.NewTCP(listeners.Config{ID: "t1", Address: "127.0.0.1:0"})
+	if err := server.AddListener(tcp); err != nil {
+		t.Fatalf("AddListener: %v", err)
+	}
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer func() {
+		_ = server.Stop(context.Background())
+	}()
+
+	conn, err := net.Dial("tcp", tcp.Address())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// CONNECT, MQTT 3.1.1, clean session, client id "c1".
+	connect := []byte{0x10, 14, 0, 4, 'M', 'Q', 'T', 'T', 4, 0x02, 0, 60, 0, 2, 'c', '1'}
+	if _, err := conn.Write(connect); err != nil {
+		t.Fatalf("write connect: %v", err)
+	}
+	connack := make([]byte, 4)
+	if _, err := io.ReadFull(conn, connack); err != nil {
+		t.Fatalf("read connack: %v", err)
+	}
+	if connack[0] != 0x20 || connack[3] != 0 {
+		t.Fatalf("unexpected connack: %v", connack)
+	}
+	waitInFlight(t, drain, 1)
+
+	drain.Begin()
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Fatalf("connection was not closed: %v", err)
+	}
+	waitInFlight(t, drain, 0)
+}
+
+func waitInFlight(t *testing.T, drain *web.Drain, want int64) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for drain.InFlight() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("in flight: got=%d want=%d", drain.InFlight(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,29 @@
+package realtime
+
+import (
+	"context"
+
+	usmqtt "github.com/bronystylecrazy/ultrastructure/realtime/mqtt"
+)
+
+// BrokerHealthChecker reports the embedded or external MQTT broker to the
+// health service.
+type BrokerHealthChecker struct {
+	broker usmqtt.Broker
+}
+
+func NewBrokerHealthChecker(broker usmqtt.Broker) *BrokerHealthChecker {
+	return &BrokerHealthChecker{broker: broker}
+}
+
+func (h *BrokerHealthChecker) Name() string {
+	return "mqtt"
+}
+
+func (h *BrokerHealthChecker) Check(ctx context.Context) error {
+	pinger, ok := h.broker.(usmqtt.Pinger)
+	if !ok {
+		return nil
+	}
+	return pinger.Ping(ctx)
+}
@@ -0,0 +1,74 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/packets"
+)
+
+// RequestIDProperty is the MQTT v5 user property carrying the request id of
+// the call that caused a publish.
+const RequestIDProperty = "x-request-id"
+
+// ContextPublisher publishes with request-scoped metadata taken from ctx.
+type ContextPublisher interface {
+	PublishContext(ctx context.Context, topic string, payload []byte, retain bool, qos byte) error
+}
+
+// PublishContext publishes through pub, forwarding the request id from ctx
+// when pub supports it. Other publishers fall back to Publish.
+func PublishContext(ctx context.Context, pub Publisher, topic string, payload []byte, retain bool, qos byte) error {
+	if cp, ok := pub.(ContextPublisher); ok {
+		return cp.PublishContext(ctx, topic, payload, retain, qos)
+	}
+	return pub.Publish(topic, payload, retain, qos)
+}
+
+// PublishJSONContext marshals payload as JSON and publishes it with PublishContext.
+func PublishJSONContext(ctx context.Context, pub Publisher, topic string, payload any, retain bool, qos byte) error {
+	p, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return PublishContext(ctx, pub, topic, p, retain, qos)
+}
+
+// RequestIDFromPacket returns the request id user property of pk.
+func RequestIDFromPacket(pk packets.Packet) string {
+	for _, prop := range pk.Properties.User {
+		if prop.Key == RequestIDProperty {
+			return prop.Val
+		}
+	}
+	return ""
+}
+
+// PublishContext publishes like Publish and attaches the request id from ctx
+// as a user property, so inline subscribers can correlate the message.
+func (m *Server) PublishContext(ctx context.Context, topic string, payload []byte, retain bool, qos byte) error {
+	requestID := otel.RequestIDFromContext(ctx)
+	if requestID == "" {
+		return m.Publish(topic, payload, retain, qos)
+	}
+
+	cl, ok := m.Clients.Get(mqtt.InlineClientId)
+	if !ok || cl == nil {
+		return mqtt.ErrInlineClientNotEnabled
+	}
+
+	pk := packets.Packet{
+		FixedHeader: packets.FixedHeader{
+			Type:   packets.Publish,
+			Qos:    qos,
+			Retain: retain,
+		},
+		TopicName: topic,
+		Payload:   payload,
+		PacketID:  uint16(qos),
+	}
+	pk.Properties.User = []packets.UserProperty{{Key: RequestIDProperty, Val: requestID}}
+	return m.InjectPacket(cl, pk)
+}
@@ -435,6 +435,15 @@ func (e *External) subscribeRemote(filter string) error {
 	})
 }
 
+// Ping reports whether the connection to the external broker is up.
+func (e *External) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := e.connectedConn()
+	return err
+}
+
 func (e *External) connectedConn() (net.Conn, error) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync/atomic"
 
 	mqtt "github.com/mochi-mqtt/server/v2"
 )
@@ -38,8 +39,17 @@ const (
 	QoS2 byte = 2
 )
 
+// Pinger is implemented by brokers that can report whether they are serving.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+var ErrServerNotRunning = errors.New("realtime/mqtt: embedded broker is not running")
+
 type Server struct {
 	*mqtt.Server
+
+	running atomic.Bool
 }
 
 func NewServer(logger *slog.Logger) (*Server, error) {
@@ -68,13 +78,29 @@ func (m *Server) PublishString(topic string, payload string, retain bool, qos by
 }
 
 func (m *Server) Start(context.Context) error {
-	return m.Server.Serve()
+	if err := m.Server.Serve(); err != nil {
+		return err
+	}
+	m.running.Store(true)
+	return nil
 }
 
 func (m *Server) Stop(context.Context) error {
+	m.running.Store(false)
 	return m.Server.Close()
 }
 
+// Ping reports whether the embedded broker is serving.
+func (m *Server) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !m.running.Load() {
+		return ErrServerNotRunning
+	}
+	return nil
+}
+
 func (m *Server) DisconnectClient(_ context.Context, clientID string, reason string) error {
 	if strings.TrimSpace(clientID) == "" {
 		return errors.New("realtime/mqtt: client id is required")
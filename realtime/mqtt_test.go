@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bronystylecrazy/ultrastructure/otel"
 	usmqtt "github.com/bronystylecrazy/ultrastructure/realtime/mqtt"
 	mqtt "github.com/mochi-mqtt/server/v2"
 	"github.com/mochi-mqtt/server/v2/packets"
@@ -75,3 +76,52 @@ func TestMqttServerPublishSubscribeRoundtrip(t *testing.T) {
 	case <-time.After(150 * time.Millisecond):
 	}
 }
+
+func TestMqttServerPublishContextCarriesRequestID(t *testing.T) {
+	server, err := usmqtt.NewServer(slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	go func() {
+		_ = server.Start(context.Background())
+	}()
+	defer func() {
+		_ = server.Stop(context.Background())
+	}()
+
+	received := make(chan string, 1)
+	handler := topicHandlerToInlineSubFn(func(ctx Ctx) error {
+		received <- ctx.RequestID()
+		return nil
+	}, server, nil, nil)
+	if err := server.Subscribe("orders/created", 1, handler); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	reqCtx := otel.WithRequestID(context.Background(), "req-123")
+	if err := usmqtt.PublishJSONContext(reqCtx, server, "orders/created", testPayload{Message: "hello"}, false, 0); err != nil {
+		t.Fatalf("PublishJSONContext: %v", err)
+	}
+
+	select {
+	case got := <-received:
+		if got != "req-123" {
+			t.Fatalf("request id: got=%q want=%q", got, "req-123")
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("timeout waiting for published message")
+	}
+
+	if err := server.Publish("orders/created", []byte("{}"), false, 0); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	select {
+	case got := <-received:
+		if got != "" {
+			t.Fatalf("request id without context: got=%q want empty", got)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("timeout waiting for published message")
+	}
+}
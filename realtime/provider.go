@@ -25,6 +25,7 @@ func Providers(opts ...di.Node) di.Node {
 			di.As[usmqtt.Publisher](),
 			di.As[usmqtt.Subscriber](),
 		),
+		di.Provide(NewBrokerHealthChecker),
 		di.Provide(NewClientIdentityStore),
 		di.Provide(
 			NewClientIdentityHook,
@@ -35,6 +36,11 @@ func Providers(opts ...di.Node) di.Node {
 			NewClientConnectContextHook,
 			di.As[mqtt.Hook](`group:"mqtt_hooks"`),
 		),
+		di.Provide(
+			NewDrainHook,
+			di.As[mqtt.Hook](`group:"mqtt_hooks"`),
+			di.Params(di.Optional()),
+		),
 		di.Provide(
 			UseClientIdentityContext,
 			di.As[TopicMiddleware](`group:"mqtt_topic_middlewares"`),
@@ -43,6 +49,7 @@ func Providers(opts ...di.Node) di.Node {
 			UseConnectedContext,
 			di.As[TopicMiddleware](`group:"mqtt_topic_middlewares"`),
 		),
+		di.Provide(NewSSEBridge),
 		di.Provide(
 			NewManagedPubSub,
 			di.AsSelf[TopicRegistrar](),
@@ -0,0 +1,176 @@
+package realtime
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	usmqtt "github.com/bronystylecrazy/ultrastructure/realtime/mqtt"
+	"github.com/bronystylecrazy/ultrastructure/security/authn"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"go.uber.org/multierr"
+)
+
+// sseSubscriptionBase keeps bridge subscription ids clear of ManagedPubSub ids.
+const sseSubscriptionBase = 1 << 24
+
+var ErrSSETopicForbidden = web.NewError(http.StatusForbidden, "REALTIME_TOPIC_FORBIDDEN", "not allowed to subscribe to this topic")
+
+// SSEMessage is the data of every event sent by SSEBridge routes.
+type SSEMessage struct {
+	Topic   string `json:"topic"`
+	Payload string `json:"payload"`
+}
+
+// SSETopicAuthorizer decides whether principal may receive messages from filter.
+// principal is nil for unauthenticated requests.
+type SSETopicAuthorizer func(principal *authn.Principal, filter string) bool
+
+// SSEBridgeOption customizes an SSEBridge.
+type SSEBridgeOption func(*SSEBridge)
+
+// WithSSETopicAuthorizer sets the authorizer. By default any authenticated
+// principal may subscribe.
+func WithSSETopicAuthorizer(authorize SSETopicAuthorizer) SSEBridgeOption {
+	return func(b *SSEBridge) {
+		b.authorize = authorize
+	}
+}
+
+// WithSSEReplaySize sets how many events each route keeps for Last-Event-ID resume.
+func WithSSEReplaySize(size int) SSEBridgeOption {
+	return func(b *SSEBridge) {
+		b.replaySize = size
+	}
+}
+
+// SSEBridge streams realtime topics to browsers over Server-Sent Events, for
+// clients that cannot speak MQTT over websockets.
+//
+// Each route subscribes once to its filters through usmqtt.Subscriber and
+// fans messages out to connected clients from a bounded replay buffer.
+type SSEBridge struct {
+	sub        usmqtt.Subscriber
+	authorize  SSETopicAuthorizer
+	replaySize int
+	nextID     atomic.Int64
+
+	mu     sync.Mutex
+	topics []*sseTopic
+}
+
+type sseTopic struct {
+	filters []string
+	ids     []int
+	buffer  *web.SSEBuffer
+
+	mu         sync.Mutex
+	subscribed bool
+}
+
+func NewSSEBridge(sub usmqtt.Subscriber, opts ...SSEBridgeOption) *SSEBridge {
+	b := &SSEBridge{
+		sub: sub,
+		authorize: func(principal *authn.Principal, _ string) bool {
+			return principal != nil
+		},
+	}
+	b.nextID.Store(sseSubscriptionBase)
+	for _, opt := range opts {
+		if opt != nil {
+			opt(b)
+		}
+	}
+	return b
+}
+
+// Topics returns a RouteOption that streams messages matching filters.
+// Requests are authorized against every filter before the stream opens.
+//
+// Usage: r.Group("/events", authn.UserOnly(tokens)).Get("/orders").With(bridge.Topics("orders/+/status"))
+func (b *SSEBridge) Topics(filters ...string) web.RouteOption {
+	topic := &sseTopic{
+		filters: append([]string(nil), filters...),
+		buffer:  web.NewSSEBuffer(b.replaySize),
+	}
+	b.mu.Lock()
+	b.topics = append(b.topics, topic)
+	b.mu.Unlock()
+
+	return func(r *web.RouteBuilder) *web.RouteBuilder {
+		r.Middleware(func(c fiber.Ctx, next func() error) error {
+			principal, _ := authn.PrincipalFromLocals(c)
+			for _, filter := range topic.filters {
+				if !b.authorize(principal, filter) {
+					return ErrSSETopicForbidden
+				}
+			}
+			if err := b.subscribe(topic); err != nil {
+				return err
+			}
+			return next()
+		})
+		r.SSE(func(_ context.Context, stream *web.SSEStream) error {
+			return stream.Forward(topic.buffer)
+		})
+		return r.
+			ProducesAs(SSEMessage{}, http.StatusOK, web.ContentTypeTextEventStream).
+			Forbidden(web.Error{}, "Topic not allowed")
+	}
+}
+
+// subscribe registers the topic's filters on first use, so routes can be
+// declared before the broker is connected.
+func (b *SSEBridge) subscribe(topic *sseTopic) error {
+	topic.mu.Lock()
+	defer topic.mu.Unlock()
+	if topic.subscribed {
+		return nil
+	}
+
+	handler := func(_ *mqtt.Client, _ packets.Subscription, pk packets.Packet) {
+		topic.buffer.Publish(web.SSEEvent{
+			Event: "message",
+			Data:  SSEMessage{Topic: pk.TopicName, Payload: string(pk.Payload)},
+		})
+	}
+	ids := make([]int, 0, len(topic.filters))
+	for _, filter := range topic.filters {
+		id := int(b.nextID.Add(1))
+		if err := b.sub.Subscribe(filter, id, handler); err != nil {
+			for i, done := range ids {
+				_ = b.sub.Unsubscribe(topic.filters[i], done)
+			}
+			return err
+		}
+		ids = append(ids, id)
+	}
+	topic.ids = ids
+	topic.subscribed = true
+	return nil
+}
+
+// Stop drops the bridge's broker subscriptions.
+func (b *SSEBridge) Stop(context.Context) error {
+	b.mu.Lock()
+	topics := append([]*sseTopic(nil), b.topics...)
+	b.mu.Unlock()
+
+	var err error
+	for _, topic := range topics {
+		topic.mu.Lock()
+		if topic.subscribed {
+			for i, id := range topic.ids {
+				err = multierr.Append(err, b.sub.Unsubscribe(topic.filters[i], id))
+			}
+			topic.subscribed = false
+			topic.ids = nil
+		}
+		topic.mu.Unlock()
+	}
+	return err
+}
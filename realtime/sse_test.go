@@ -0,0 +1,122 @@
+package realtime
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/security/authn"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/packets"
+)
+
+type fakeSubscriber struct {
+	mu       sync.Mutex
+	handlers map[string]mqtt.InlineSubFn
+	removed  []string
+}
+
+func (s *fakeSubscriber) Subscribe(filter string, _ int, handler mqtt.InlineSubFn) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.handlers == nil {
+		s.handlers = make(map[string]mqtt.InlineSubFn)
+	}
+	s.handlers[filter] = handler
+	return nil
+}
+
+func (s *fakeSubscriber) Unsubscribe(filter string, _ int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.removed = append(s.removed, filter)
+	return nil
+}
+
+func (s *fakeSubscriber) deliver(filter, topic, payload string) {
+	s.mu.Lock()
+	handler := s.handlers[filter]
+	s.mu.Unlock()
+	handler(nil, packets.Subscription{Filter: filter}, packets.Packet{TopicName: topic, Payload: []byte(payload)})
+}
+
+func newSSEBridgeTestApp(bridge *SSEBridge, drain *web.Drain) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: web.NewErrorHandler(web.Config{}, nil).HandleError})
+	app.Use(drain.Middleware)
+	app.Use(func(c fiber.Ctx) error {
+		if subject := c.Get("X-Subject"); subject != "" {
+			authn.SetPrincipalLocals(c, &authn.Principal{Type: authn.PrincipalUser, Subject: subject})
+		}
+		return c.Next()
+	})
+	r := web.NewRouterWithRegistry(app, nil)
+	r.Get("/events/orders").With(bridge.Topics("orders/#"))
+	return app
+}
+
+func TestSSEBridgeRejectsUnauthorizedPrincipal(t *testing.T) {
+	sub := &fakeSubscriber{}
+	bridge := NewSSEBridge(sub, WithSSETopicAuthorizer(func(p *authn.Principal, filter string) bool {
+		return p != nil && p.Subject == "admin"
+	}))
+	app := newSSEBridgeTestApp(bridge, web.NewDrain())
+
+	for _, subject := range []string{"", "guest"} {
+		req := httptest.NewRequest(http.MethodGet, "/events/orders", nil)
+		if subject != "" {
+			req.Header.Set("X-Subject", subject)
+		}
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		if res.StatusCode != http.StatusForbidden {
+			t.Fatalf("subject %q: status=%d want 403", subject, res.StatusCode)
+		}
+	}
+	if len(sub.handlers) != 0 {
+		t.Fatal("rejected request must not subscribe")
+	}
+}
+
+func TestSSEBridgeStreamsTopicMessages(t *testing.T) {
+	sub := &fakeSubscriber{}
+	bridge := NewSSEBridge(sub)
+	drain := web.NewDrain()
+	app := newSSEBridgeTestApp(bridge, drain)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		sub.deliver("orders/#", "orders/42/status", "shipped")
+		time.Sleep(50 * time.Millisecond)
+		drain.Begin()
+	}()
+
+	req := httptest.NewRequest(http.MethodGet, "/events/orders", nil)
+	req.Header.Set("X-Subject", "user-1")
+	res, err := app.Test(req, fiber.TestConfig{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	want := "event: message\ndata: {\"topic\":\"orders/42/status\",\"payload\":\"shipped\"}\n\n"
+	if !strings.Contains(string(body), want) {
+		t.Fatalf("body:\n%s\nwant event:\n%s", body, want)
+	}
+
+	if err := bridge.Stop(t.Context()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if len(sub.removed) != 1 || sub.removed[0] != "orders/#" {
+		t.Fatalf("unsubscribed: %v", sub.removed)
+	}
+}
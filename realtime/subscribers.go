@@ -295,7 +295,7 @@ func topicHandlerToInlineSubFn(handler TopicHandler, pub usmqtt.Publisher, contr
 			controller: controller,
 			sub:        sub,
 			packet:     pk,
-			ctx:        context.Background(),
+			ctx:        otel.WithRequestID(context.Background(), usmqtt.RequestIDFromPacket(pk)),
 		}
 
 		if err := handler(ctx); err != nil && onError != nil {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/bronystylecrazy/ultrastructure/otel"
 	usmqtt "github.com/bronystylecrazy/ultrastructure/realtime/mqtt"
 	mqtt "github.com/mochi-mqtt/server/v2"
 	"github.com/mochi-mqtt/server/v2/packets"
@@ -59,6 +60,10 @@ type Ctx interface {
 	// SetContext replaces the base context used by this Ctx.
 	// Example: ctx.SetContext(context.WithValue(ctx.Context(), key, value))
 	SetContext(ctx context.Context)
+	// RequestID returns the id of the HTTP request that published the message,
+	// when the publisher forwarded one.
+	// Example: id := ctx.RequestID()
+	RequestID() string
 	// Identity returns client identity from context when present.
 	// Example: identity, ok := ctx.Identity()
 	Identity() (ClientIdentity, bool)
@@ -128,6 +133,9 @@ func (c *topicCtx) Publish(topic string, payload []byte, retain bool, qos byte)
 	if c.pub == nil {
 		return ErrTopicCtxNoPublisher
 	}
+	if cp, ok := c.contextPublisher(); ok {
+		return cp.PublishContext(c.Context(), topic, payload, retain, qos)
+	}
 	return c.pub.Publish(topic, payload, retain, qos)
 }
 
@@ -135,6 +143,9 @@ func (c *topicCtx) PublishJSON(topic string, payload any, retain bool, qos byte)
 	if c.pub == nil {
 		return ErrTopicCtxNoPublisher
 	}
+	if _, ok := c.contextPublisher(); ok {
+		return usmqtt.PublishJSONContext(c.Context(), c.pub, topic, payload, retain, qos)
+	}
 	return c.pub.PublishJSON(topic, payload, retain, qos)
 }
 
@@ -142,9 +153,22 @@ func (c *topicCtx) PublishString(topic string, payload string, retain bool, qos
 	if c.pub == nil {
 		return ErrTopicCtxNoPublisher
 	}
+	if cp, ok := c.contextPublisher(); ok {
+		return cp.PublishContext(c.Context(), topic, []byte(payload), retain, qos)
+	}
 	return c.pub.PublishString(topic, payload, retain, qos)
 }
 
+// contextPublisher returns the publisher when the message being handled
+// carries a request id worth forwarding to the messages it causes.
+func (c *topicCtx) contextPublisher() (usmqtt.ContextPublisher, bool) {
+	if c.RequestID() == "" {
+		return nil, false
+	}
+	cp, ok := c.pub.(usmqtt.ContextPublisher)
+	return cp, ok
+}
+
 func (c *topicCtx) Context() context.Context {
 	if c.ctx == nil {
 		return context.Background()
@@ -172,6 +196,10 @@ func (c *topicCtx) SetContext(ctx context.Context) {
 	c.ctx = ctx
 }
 
+func (c *topicCtx) RequestID() string {
+	return otel.RequestIDFromContext(c.Context())
+}
+
 func (c *topicCtx) Identity() (ClientIdentity, bool) {
 	return IdentityFromContext(c.Context())
 }
@@ -1,11 +1,13 @@
 package realtime
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net"
 	"net/http"
 	"sync"
+	"time"
 
 	"log/slog"
 
@@ -28,6 +30,7 @@ type Websocket struct { // [MQTT-4.2.0-1]
 	Log         *slog.Logger          // server logging
 	EstablishFn listeners.EstablishFn // the server's establish connection handler
 	Upgrader    *websocket.Upgrader   //  upgrade the incoming http/tcp connection to a websocket compliant connection.
+	Drain       *web.Drain            // tracks connections and closes them when the server drains
 }
 
 // NewWebsocket initializes and returns a new Websocket listener, listening on an address.
@@ -95,6 +98,13 @@ func WithUpgrader(upgrader *websocket.Upgrader) Option {
 	}
 }
 
+// WithDrain tracks connections in drain and closes them once it begins.
+func WithDrain(drain *web.Drain) Option {
+	return func(w *Websocket) {
+		w.Drain = drain
+	}
+}
+
 // ID returns the id of the listener.
 func (l *Websocket) ID() string {
 	return l.Id
@@ -131,6 +141,16 @@ func (l *Websocket) Handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer c.Close()
+	if l.Drain != nil {
+		release := l.Drain.Track()
+		defer release()
+		stop := context.AfterFunc(l.Drain.Context(), func() {
+			msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+			_ = c.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+			_ = c.Close()
+		})
+		defer stop()
+	}
 	err = l.EstablishFn(l.Id, &wsConn{Conn: c.UnderlyingConn(), c: c})
 	if err != nil {
 		l.Log.Warn("", "error", err)
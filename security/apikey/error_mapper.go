@@ -0,0 +1,27 @@
+package apikey
+
+import (
+	"net/http"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+)
+
+// ErrorMapper renders API key validation errors through the web error handler.
+type ErrorMapper struct {
+	mappings web.ErrorMappings
+}
+
+func NewErrorMapper() *ErrorMapper {
+	return &ErrorMapper{
+		mappings: web.ErrorMappings{
+			{Target: ErrRevokedAPIKey, Status: http.StatusUnauthorized, Code: "API_KEY_REVOKED", Message: "api key revoked"},
+			{Target: ErrExpiredAPIKey, Status: http.StatusUnauthorized, Code: "API_KEY_EXPIRED", Message: "api key expired"},
+			{Target: ErrInvalidAPIKey, Status: http.StatusUnauthorized, Code: "API_KEY_INVALID", Message: "invalid api key"},
+			{Target: ErrInvalidRawKeyFormat, Status: http.StatusUnauthorized, Code: "API_KEY_INVALID", Message: "invalid api key"},
+		},
+	}
+}
+
+func (m *ErrorMapper) MapError(err error) (*web.HTTPError, bool) {
+	return m.mappings.MapError(err)
+}
@@ -23,6 +23,7 @@ func Providers(opts ...di.Node) di.Node {
 				Rotator:   in.Rotator,
 			})
 		}, di.AsSelf[Manager]()),
+		di.Provide(NewErrorMapper),
 		di.Options(di.ConvertAnys(opts)...),
 	)
 }
@@ -3,6 +3,7 @@ package apikey
 import (
 	"context"
 
+	"github.com/bronystylecrazy/ultrastructure/web"
 	"github.com/gofiber/fiber/v3"
 )
 
@@ -30,8 +31,13 @@ func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
 	return p, ok && p != nil
 }
 
+// SetPrincipalLocals stores principal for PrincipalFromLocals and records its
+// app as the web.CallerID of the request, as authn does for app principals.
 func SetPrincipalLocals(c fiber.Ctx, principal *Principal) {
 	c.Locals(principalLocalsKey, principal)
+	if principal != nil && principal.AppID != "" {
+		web.SetCallerID(c, "app:"+principal.AppID)
+	}
 }
 
 func PrincipalFromLocals(c fiber.Ctx) (*Principal, bool) {
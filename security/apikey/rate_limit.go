@@ -0,0 +1,20 @@
+package apikey
+
+import (
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
+
+// RateLimitByKeyID counts requests per API key. Requests without an API key
+// principal fall back to the client IP.
+//
+// Usage: web.RateLimit(1000, time.Hour, web.WithRateLimitKey(apikey.RateLimitByKeyID()))
+func RateLimitByKeyID() web.RateLimitKeyFunc {
+	return func(c fiber.Ctx) string {
+		p, ok := PrincipalFromLocals(c)
+		if !ok || p.KeyID == "" {
+			return ""
+		}
+		return "apikey:" + p.KeyID
+	}
+}
@@ -24,14 +24,63 @@ func (f AuthenticatorFunc) Authenticate(c fiber.Ctx) (*Principal, bool, error) {
 	return f(c)
 }
 
+const authenticatorsLocalsKey = "us.authn.authenticators"
+
+// UseAuthenticators returns middleware after which Any and AnyWithMode try
+// authenticators ahead of their own, for credentials accepted app-wide. ustest
+// registers one for Request.As.
+//
+// Usage: r.Use(authn.UseAuthenticators(serviceMeshAuthenticator))
+func UseAuthenticators(authenticators ...Authenticator) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		existing := requestAuthenticators(c)
+		c.Locals(authenticatorsLocalsKey, append(existing[:len(existing):len(existing)], authenticators...))
+		return c.Next()
+	}
+}
+
+func requestAuthenticators(c fiber.Ctx) []Authenticator {
+	authenticators, _ := c.Locals(authenticatorsLocalsKey).([]Authenticator)
+	return authenticators
+}
+
+const failureHooksLocalsKey = "us.authn.failure_hooks"
+
+// onFailure runs hook when the request authenticated by Any or AnyWithMode
+// ends with an error or a non-2xx status, e.g. to give back a webhook nonce.
+func onFailure(c fiber.Ctx, hook func(context.Context)) {
+	hooks, _ := c.Locals(failureHooksLocalsKey).([]func(context.Context))
+	c.Locals(failureHooksLocalsKey, append(hooks, hook))
+}
+
+func runFailureHooks(c fiber.Ctx, err error) {
+	if err == nil && c.Response().StatusCode() < fiber.StatusMultipleChoices {
+		return
+	}
+	hooks, _ := c.Locals(failureHooksLocalsKey).([]func(context.Context))
+	if len(hooks) == 0 {
+		return
+	}
+	c.Locals(failureHooksLocalsKey, nil)
+	ctx := context.WithoutCancel(c.Context())
+	for _, hook := range hooks {
+		hook(ctx)
+	}
+}
+
 func Any(authenticators ...Authenticator) fiber.Handler {
 	return AnyWithMode(ErrorModeFailFast, authenticators...)
 }
 
 func AnyWithMode(mode ErrorMode, authenticators ...Authenticator) fiber.Handler {
-	return func(c fiber.Ctx) error {
-		principals := make([]*Principal, 0, len(authenticators))
-		for _, a := range authenticators {
+	return func(c fiber.Ctx) (err error) {
+		defer func() { runFailureHooks(c, err) }()
+		chain := authenticators
+		if extra := requestAuthenticators(c); len(extra) > 0 {
+			chain = append(extra[:len(extra):len(extra)], authenticators...)
+		}
+		principals := make([]*Principal, 0, len(chain))
+		for _, a := range chain {
 			if a == nil {
 				continue
 			}
@@ -54,17 +103,20 @@ func AnyWithMode(mode ErrorMode, authenticators ...Authenticator) fiber.Handler
 		if len(principals) == 0 {
 			return httpx.Unauthorized(c, "unauthorized")
 		}
-
-		primary := principals[0]
-		ctx := WithPrincipals(c.Context(), principals)
-		ctx = WithPrincipal(ctx, primary)
-		c.SetContext(ctx)
-		SetPrincipalsLocals(c, principals)
-		SetPrincipalLocals(c, primary)
-		return c.Next()
+		return authenticated(c, principals)
 	}
 }
 
+func authenticated(c fiber.Ctx, principals []*Principal) error {
+	primary := principals[0]
+	ctx := WithPrincipals(c.Context(), principals)
+	ctx = WithPrincipal(ctx, primary)
+	c.SetContext(ctx)
+	SetPrincipalsLocals(c, principals)
+	SetPrincipalLocals(c, primary)
+	return c.Next()
+}
+
 func UserTokenAuthenticator(user session.Validator) Authenticator {
 	return UserTokenAuthenticatorWithExtractors(user)
 }
@@ -288,3 +288,38 @@ func TestEitherUserJWTRevokedUnauthorized(t *testing.T) {
 		t.Fatalf("status: got=%d want=%d", res.StatusCode, fiber.StatusUnauthorized)
 	}
 }
+
+func TestAnyTriesAuthenticatorsFromUseAuthenticators(t *testing.T) {
+	userM, _ := testutil.NewUserManager(t)
+	mesh := authn.AuthenticatorFunc(func(c fiber.Ctx) (*authn.Principal, bool, error) {
+		if c.Get("X-Mesh-Identity") == "" {
+			return nil, false, nil
+		}
+		return &authn.Principal{Type: authn.PrincipalApp, AppID: c.Get("X-Mesh-Identity")}, true, nil
+	})
+
+	app := fiber.New()
+	app.Use(authn.UseAuthenticators(mesh))
+	app.Get("/p", authn.Any(authn.UserTokenAuthenticator(userM)), func(c fiber.Ctx) error {
+		p, _ := authn.PrincipalFromContext(c.Context())
+		return c.SendString(p.AppID)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/p", nil)
+	req.Header.Set("X-Mesh-Identity", "billing")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	if res.StatusCode != fiber.StatusOK {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, fiber.StatusOK)
+	}
+
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/p", nil))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	if res.StatusCode != fiber.StatusUnauthorized {
+		t.Fatalf("status without credentials: got=%d want=%d", res.StatusCode, fiber.StatusUnauthorized)
+	}
+}
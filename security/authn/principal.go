@@ -3,6 +3,7 @@ package authn
 import (
 	"context"
 
+	"github.com/bronystylecrazy/ultrastructure/web"
 	"github.com/gofiber/fiber/v3"
 )
 
@@ -48,8 +49,27 @@ func PrincipalsFromContext(ctx context.Context) ([]*Principal, bool) {
 	return p, ok && len(p) > 0
 }
 
+// SetPrincipalLocals stores p for PrincipalFromLocals and records it as the
+// web.CallerID of the request.
 func SetPrincipalLocals(c fiber.Ctx, p *Principal) {
 	c.Locals(principalLocalsKey, p)
+	web.SetCallerID(c, principalID(p))
+}
+
+// principalID identifies p as "<type>:<subject>", or "<type>:<app id>" for
+// principals without a subject, and is "" when p has neither.
+func principalID(p *Principal) string {
+	if p == nil {
+		return ""
+	}
+	id := p.Subject
+	if id == "" {
+		id = p.AppID
+	}
+	if id == "" {
+		return ""
+	}
+	return string(p.Type) + ":" + id
 }
 
 func PrincipalFromLocals(c fiber.Ctx) (*Principal, bool) {
@@ -0,0 +1,18 @@
+package authn
+
+import (
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
+
+// RateLimitBySubject counts requests per authenticated principal. App
+// principals without a subject are counted per app. Anonymous requests fall
+// back to the client IP.
+//
+// Usage: web.RateLimit(100, time.Minute, web.WithRateLimitKey(authn.RateLimitBySubject()))
+func RateLimitBySubject() web.RateLimitKeyFunc {
+	return func(c fiber.Ctx) string {
+		p, _ := PrincipalFromLocals(c)
+		return principalID(p)
+	}
+}
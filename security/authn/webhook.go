@@ -0,0 +1,393 @@
+package authn
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// PrincipalWebhook is a verified inbound webhook. Its Subject is the source
+// ID given to the authenticator and its only scope is WebhookSourceScope of
+// that ID, so scope guards and policies can target a source.
+const PrincipalWebhook PrincipalType = "webhook"
+
+const (
+	DefaultWebhookTolerance = 5 * time.Minute
+	DefaultWebhookNonceTTL  = 24 * time.Hour
+)
+
+var (
+	ErrWebhookSignature = errors.New("authn: invalid webhook signature")
+	ErrWebhookTimestamp = errors.New("authn: webhook timestamp outside tolerance")
+	ErrWebhookReplay    = errors.New("authn: webhook already received")
+)
+
+// WebhookSourceScope returns the scope carried by principals of source.
+func WebhookSourceScope(source string) string {
+	return "webhook:" + source
+}
+
+// WebhookVerifier checks the signature of an inbound webhook.
+type WebhookVerifier interface {
+	// VerifyWebhook returns matched=false when the request carries no
+	// signature of this kind. nonce identifies the delivery for replay
+	// protection and must be derived from signed material, e.g. the
+	// signature, or a replay could simply change it; timestamps older than
+	// tolerance are rejected.
+	VerifyWebhook(c fiber.Ctx, now time.Time, tolerance time.Duration) (nonce string, matched bool, err error)
+}
+
+// NonceStore remembers delivery nonces so a captured webhook cannot be
+// replayed. x/redis provides a store shared between instances.
+type NonceStore interface {
+	// Remember records key until ttl passes and reports whether it was new.
+	Remember(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Forget drops key so the delivery can be received again.
+	Forget(ctx context.Context, key string) error
+}
+
+type WebhookOption func(*webhookOptions)
+
+type webhookOptions struct {
+	tolerance time.Duration
+	nonces    NonceStore
+	nonceTTL  time.Duration
+	now       func() time.Time
+}
+
+// WithWebhookTolerance bounds the age of signed timestamps. A negative value
+// disables the check.
+func WithWebhookTolerance(tolerance time.Duration) WebhookOption {
+	return func(o *webhookOptions) {
+		o.tolerance = tolerance
+	}
+}
+
+// WithWebhookNonceStore enables replay protection; every nonce is accepted
+// once within ttl, DefaultWebhookNonceTTL when zero. A nil store disables it.
+// A nonce whose request fails, in the handler or any later guard, is
+// forgotten so the provider's retry of that delivery is accepted.
+func WithWebhookNonceStore(store NonceStore, ttl time.Duration) WebhookOption {
+	return func(o *webhookOptions) {
+		o.nonces = store
+		if ttl > 0 {
+			o.nonceTTL = ttl
+		}
+	}
+}
+
+// WebhookAuthenticator authenticates requests verified by verifier as a
+// PrincipalWebhook of source.
+//
+// Usage: authn.Any(authn.WebhookAuthenticator("billing", verifier, authn.WithWebhookNonceStore(nonces, 0)))
+func WebhookAuthenticator(source string, verifier WebhookVerifier, opts ...WebhookOption) Authenticator {
+	o := webhookOptions{
+		tolerance: DefaultWebhookTolerance,
+		nonceTTL:  DefaultWebhookNonceTTL,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return AuthenticatorFunc(func(c fiber.Ctx) (*Principal, bool, error) {
+		if verifier == nil {
+			return nil, false, nil
+		}
+		nonce, matched, err := verifier.VerifyWebhook(c, o.now(), o.tolerance)
+		if !matched {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, true, err
+		}
+		if o.nonces != nil && nonce != "" {
+			key := "webhook:" + source + ":" + nonce
+			fresh, err := o.nonces.Remember(c.Context(), key, o.nonceTTL)
+			if err != nil {
+				return nil, true, err
+			}
+			if !fresh {
+				return nil, true, ErrWebhookReplay
+			}
+			onFailure(c, func(ctx context.Context) { _ = o.nonces.Forget(ctx, key) })
+		}
+		return &Principal{
+			Type:    PrincipalWebhook,
+			Subject: source,
+			Scopes:  []string{WebhookSourceScope(source)},
+		}, true, nil
+	})
+}
+
+// SignatureEncoding is how a signature is written in its header.
+type SignatureEncoding string
+
+const (
+	SignatureHex       SignatureEncoding = "hex"
+	SignatureBase64    SignatureEncoding = "base64"
+	SignatureBase64URL SignatureEncoding = "base64url"
+)
+
+func (e SignatureEncoding) decode(s string) ([]byte, error) {
+	switch e {
+	case SignatureBase64:
+		return base64.StdEncoding.DecodeString(s)
+	case SignatureBase64URL:
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	default:
+		return hex.DecodeString(s)
+	}
+}
+
+// HMACWebhookConfig describes a generic HMAC signature. Secrets holds the
+// accepted keys, several while one rotates. When TimestampHeader is set the
+// signed payload is "<timestamp>.<body>", otherwise the body alone.
+type HMACWebhookConfig struct {
+	Secrets         []string
+	SignatureHeader string
+	// Prefix is stripped from each signature, e.g. "sha256=" or "v1=".
+	Prefix          string
+	Encoding        SignatureEncoding
+	Hash            func() hash.Hash
+	TimestampHeader string
+}
+
+// HMACWebhookAuthenticator verifies generic HMAC signatures. The header may
+// hold several space- or comma-separated signatures; any match passes, and
+// the matching signature is the replay nonce.
+//
+// Usage: authn.HMACWebhookAuthenticator("orders", authn.HMACWebhookConfig{Secrets: []string{secret}, SignatureHeader: "Webhook-Signature", Prefix: "v1=", TimestampHeader: "Webhook-Timestamp"})
+func HMACWebhookAuthenticator(source string, config HMACWebhookConfig, opts ...WebhookOption) Authenticator {
+	if config.SignatureHeader == "" {
+		config.SignatureHeader = "Webhook-Signature"
+	}
+	if config.Hash == nil {
+		config.Hash = sha256.New
+	}
+	return WebhookAuthenticator(source, hmacVerifier(config), opts...)
+}
+
+type hmacVerifier HMACWebhookConfig
+
+func (v hmacVerifier) VerifyWebhook(c fiber.Ctx, now time.Time, tolerance time.Duration) (string, bool, error) {
+	header := strings.TrimSpace(c.Get(v.SignatureHeader))
+	if header == "" {
+		return "", false, nil
+	}
+	body := c.BodyRaw()
+	var payload []byte
+	if v.TimestampHeader != "" {
+		timestamp := strings.TrimSpace(c.Get(v.TimestampHeader))
+		if err := checkWebhookTimestamp(timestamp, now, tolerance); err != nil {
+			return "", true, err
+		}
+		payload = append([]byte(timestamp+"."), body...)
+	} else {
+		payload = body
+	}
+
+	for _, signature := range strings.FieldsFunc(header, func(r rune) bool { return r == ' ' || r == ',' }) {
+		if v.Prefix != "" && !strings.HasPrefix(signature, v.Prefix) {
+			continue
+		}
+		sum, err := v.Encoding.decode(strings.TrimPrefix(signature, v.Prefix))
+		if err != nil {
+			continue
+		}
+		if matchesHMAC(v.Hash, v.Secrets, payload, sum) {
+			return hex.EncodeToString(sum), true, nil
+		}
+	}
+	return "", true, ErrWebhookSignature
+}
+
+// StripeWebhookAuthenticator verifies Stripe-Signature headers
+// ("t=<unix>,v1=<hex>,...") signed with the endpoint's signing secrets.
+// Replays are rejected by a MemoryNonceStore unless WithWebhookNonceStore
+// replaces it.
+//
+// Usage: authn.StripeWebhookAuthenticator("stripe", []string{signingSecret})
+func StripeWebhookAuthenticator(source string, secrets []string, opts ...WebhookOption) Authenticator {
+	return WebhookAuthenticator(source, stripeVerifier(secrets), withDefaultNonceStore(opts)...)
+}
+
+type stripeVerifier []string
+
+func (v stripeVerifier) VerifyWebhook(c fiber.Ctx, now time.Time, tolerance time.Duration) (string, bool, error) {
+	header := strings.TrimSpace(c.Get("Stripe-Signature"))
+	if header == "" {
+		return "", false, nil
+	}
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if err := checkWebhookTimestamp(timestamp, now, tolerance); err != nil {
+		return "", true, err
+	}
+	payload := append([]byte(timestamp+"."), c.BodyRaw()...)
+	for _, signature := range signatures {
+		sum, err := hex.DecodeString(signature)
+		if err == nil && matchesHMAC(sha256.New, v, payload, sum) {
+			return timestamp + "." + hex.EncodeToString(sum), true, nil
+		}
+	}
+	return "", true, ErrWebhookSignature
+}
+
+// GitHubWebhookAuthenticator verifies X-Hub-Signature-256 headers. GitHub
+// signs no timestamp and X-GitHub-Delivery is not signed, so the signature
+// is the nonce and replays are rejected by a MemoryNonceStore; pass the
+// x/redis store when several instances receive webhooks.
+//
+// Usage: authn.GitHubWebhookAuthenticator("github", []string{secret}, authn.WithWebhookNonceStore(nonces, 0))
+func GitHubWebhookAuthenticator(source string, secrets []string, opts ...WebhookOption) Authenticator {
+	return WebhookAuthenticator(source, hmacVerifier{
+		Secrets:         secrets,
+		SignatureHeader: "X-Hub-Signature-256",
+		Prefix:          "sha256=",
+		Encoding:        SignatureHex,
+		Hash:            sha256.New,
+	}, withDefaultNonceStore(opts)...)
+}
+
+// withDefaultNonceStore puts a MemoryNonceStore ahead of opts, which may
+// replace or disable it.
+func withDefaultNonceStore(opts []WebhookOption) []WebhookOption {
+	return append([]WebhookOption{WithWebhookNonceStore(NewMemoryNonceStore(), 0)}, opts...)
+}
+
+// Ed25519WebhookConfig describes an Ed25519-signed body. When
+// TimestampHeader is set the signed message is the timestamp immediately
+// followed by the body, as Discord signs interactions.
+type Ed25519WebhookConfig struct {
+	PublicKeys      []ed25519.PublicKey
+	SignatureHeader string
+	Encoding        SignatureEncoding
+	TimestampHeader string
+}
+
+// Ed25519WebhookAuthenticator verifies Ed25519 signatures, hex encoded in
+// X-Signature-Ed25519 by default.
+//
+// Usage: authn.Ed25519WebhookAuthenticator("discord", authn.Ed25519WebhookConfig{PublicKeys: []ed25519.PublicKey{key}, TimestampHeader: "X-Signature-Timestamp"})
+func Ed25519WebhookAuthenticator(source string, config Ed25519WebhookConfig, opts ...WebhookOption) Authenticator {
+	if config.SignatureHeader == "" {
+		config.SignatureHeader = "X-Signature-Ed25519"
+	}
+	return WebhookAuthenticator(source, ed25519Verifier(config), opts...)
+}
+
+type ed25519Verifier Ed25519WebhookConfig
+
+func (v ed25519Verifier) VerifyWebhook(c fiber.Ctx, now time.Time, tolerance time.Duration) (string, bool, error) {
+	header := strings.TrimSpace(c.Get(v.SignatureHeader))
+	if header == "" {
+		return "", false, nil
+	}
+	message := c.BodyRaw()
+	if v.TimestampHeader != "" {
+		timestamp := strings.TrimSpace(c.Get(v.TimestampHeader))
+		if err := checkWebhookTimestamp(timestamp, now, tolerance); err != nil {
+			return "", true, err
+		}
+		message = append([]byte(timestamp), message...)
+	}
+	signature, err := v.Encoding.decode(header)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return "", true, ErrWebhookSignature
+	}
+	for _, key := range v.PublicKeys {
+		if len(key) == ed25519.PublicKeySize && ed25519.Verify(key, message, signature) {
+			return hex.EncodeToString(signature), true, nil
+		}
+	}
+	return "", true, ErrWebhookSignature
+}
+
+// checkWebhookTimestamp parses unix seconds and applies tolerance.
+func checkWebhookTimestamp(timestamp string, now time.Time, tolerance time.Duration) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrWebhookTimestamp
+	}
+	if tolerance >= 0 {
+		if d := now.Sub(time.Unix(unix, 0)); d > tolerance || d < -tolerance {
+			return ErrWebhookTimestamp
+		}
+	}
+	return nil
+}
+
+func matchesHMAC(newHash func() hash.Hash, secrets []string, payload, sum []byte) bool {
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		mac := hmac.New(newHash, []byte(secret))
+		mac.Write(payload)
+		if hmac.Equal(mac.Sum(nil), sum) {
+			return true
+		}
+	}
+	return false
+}
+
+// MemoryNonceStore keeps nonces in process memory; use the x/redis store
+// when several instances receive webhooks.
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	expires   map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{expires: make(map[string]time.Time), now: time.Now}
+}
+
+func (s *MemoryNonceStore) Remember(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, exp := range s.expires {
+			if !now.Before(exp) {
+				delete(s.expires, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	if exp, ok := s.expires[key]; ok && now.Before(exp) {
+		return false, nil
+	}
+	s.expires[key] = now.Add(ttl)
+	return true, nil
+}
+
+func (s *MemoryNonceStore) Forget(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.expires, key)
+	return nil
+}
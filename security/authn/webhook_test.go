@@ -0,0 +1,171 @@
+package authn_test
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	authn "github.com/bronystylecrazy/ultrastructure/security/authn"
+	"github.com/bronystylecrazy/ultrastructure/security/authz"
+	"github.com/gofiber/fiber/v3"
+)
+
+func hmacHex(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhookApp(auth authn.Authenticator, sources ...string) *fiber.App {
+	app := fiber.New()
+	app.Post("/hooks", authn.Any(auth), authz.RequireWebhookSource(sources...), func(c fiber.Ctx) error {
+		p, _ := authn.PrincipalFromContext(c.Context())
+		return c.SendString(string(p.Type) + ":" + p.Subject)
+	})
+	return app
+}
+
+func sendWebhook(t *testing.T, app *fiber.App, body string, headers map[string]string) int {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/hooks", strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	return res.StatusCode
+}
+
+func TestHMACWebhookAuthenticatorChecksTimestampAndReplays(t *testing.T) {
+	nonces := authn.NewMemoryNonceStore()
+	app := newWebhookApp(authn.HMACWebhookAuthenticator("orders", authn.HMACWebhookConfig{
+		Secrets:         []string{"old", "s3cret"},
+		Prefix:          "v1=",
+		TimestampHeader: "Webhook-Timestamp",
+	}, authn.WithWebhookNonceStore(nonces, 0)), "orders")
+
+	body := `{"id":"o-1"}`
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	headers := map[string]string{
+		"Webhook-Id":        "d-1",
+		"Webhook-Timestamp": ts,
+		"Webhook-Signature": "v1=deadbeef v1=" + hmacHex("s3cret", ts+"."+body),
+	}
+	if got := sendWebhook(t, app, body, headers); got != http.StatusOK {
+		t.Fatalf("status: got=%d want=200", got)
+	}
+	if got := sendWebhook(t, app, body, headers); got != http.StatusUnauthorized {
+		t.Fatalf("replay: got=%d want=401", got)
+	}
+	// Neither an unsigned delivery ID nor re-encoding the signature makes a
+	// replay look new.
+	headers["Webhook-Id"] = "d-2"
+	headers["Webhook-Signature"] = "v1=" + strings.ToUpper(hmacHex("s3cret", ts+"."+body))
+	if got := sendWebhook(t, app, body, headers); got != http.StatusUnauthorized {
+		t.Fatalf("replay with a new id: got=%d want=401", got)
+	}
+
+	if got := sendWebhook(t, app, `{"id":"o-2"}`, headers); got != http.StatusUnauthorized {
+		t.Fatalf("tampered body: got=%d want=401", got)
+	}
+
+	stale := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	headers["Webhook-Timestamp"] = stale
+	headers["Webhook-Signature"] = "v1=" + hmacHex("s3cret", stale+"."+body)
+	if got := sendWebhook(t, app, body, headers); got != http.StatusUnauthorized {
+		t.Fatalf("stale timestamp: got=%d want=401", got)
+	}
+}
+
+func TestStripeAndGitHubWebhookAuthenticators(t *testing.T) {
+	body := `{"type":"charge.succeeded"}`
+	stripe := newWebhookApp(authn.StripeWebhookAuthenticator("stripe", []string{"whsec_1"}), "stripe")
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	if got := sendWebhook(t, stripe, body, map[string]string{
+		"Stripe-Signature": "t=" + ts + ",v1=" + hmacHex("whsec_1", ts+"."+body) + ",v0=ignored",
+	}); got != http.StatusOK {
+		t.Fatalf("stripe: got=%d want=200", got)
+	}
+
+	github := newWebhookApp(authn.GitHubWebhookAuthenticator("github", []string{"gh"}), "stripe")
+	headers := map[string]string{
+		"X-Hub-Signature-256": "sha256=" + hmacHex("gh", body),
+		"X-GitHub-Delivery":   "g-1",
+	}
+	if got := sendWebhook(t, github, body, headers); got != http.StatusForbidden {
+		t.Fatalf("github source outside the guard: got=%d want=403", got)
+	}
+	github = newWebhookApp(authn.GitHubWebhookAuthenticator("github", []string{"gh"}))
+	if got := sendWebhook(t, github, body, headers); got != http.StatusOK {
+		t.Fatalf("github: got=%d want=200", got)
+	}
+	if got := sendWebhook(t, github, body, headers); got != http.StatusUnauthorized {
+		t.Fatalf("github replay: got=%d want=401", got)
+	}
+	headers["X-GitHub-Delivery"] = "g-2"
+	if got := sendWebhook(t, github, body, headers); got != http.StatusUnauthorized {
+		t.Fatalf("github replay with a new delivery id: got=%d want=401", got)
+	}
+	if got := sendWebhook(t, github, body, nil); got != http.StatusUnauthorized {
+		t.Fatalf("unsigned: got=%d want=401", got)
+	}
+}
+
+func TestWebhookNonceIsReleasedWhenTheHandlerFails(t *testing.T) {
+	fail := true
+	app := fiber.New()
+	app.Post("/hooks", authn.Any(authn.GitHubWebhookAuthenticator("github", []string{"gh"})), func(c fiber.Ctx) error {
+		if fail {
+			return fiber.ErrServiceUnavailable
+		}
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	body := `{"action":"opened"}`
+	headers := map[string]string{
+		"X-Hub-Signature-256": "sha256=" + hmacHex("gh", body),
+		"X-GitHub-Delivery":   "g-1",
+	}
+	if got := sendWebhook(t, app, body, headers); got != http.StatusServiceUnavailable {
+		t.Fatalf("failed delivery: got=%d want=503", got)
+	}
+	fail = false
+	if got := sendWebhook(t, app, body, headers); got != http.StatusNoContent {
+		t.Fatalf("retried delivery: got=%d want=204", got)
+	}
+	if got := sendWebhook(t, app, body, headers); got != http.StatusUnauthorized {
+		t.Fatalf("replay after success: got=%d want=401", got)
+	}
+}
+
+func TestEd25519WebhookAuthenticator(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	app := newWebhookApp(authn.Ed25519WebhookAuthenticator("discord", authn.Ed25519WebhookConfig{
+		PublicKeys:      []ed25519.PublicKey{public},
+		TimestampHeader: "X-Signature-Timestamp",
+	}))
+
+	body := `{"type":1}`
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := hex.EncodeToString(ed25519.Sign(private, []byte(ts+body)))
+	headers := map[string]string{"X-Signature-Ed25519": signature, "X-Signature-Timestamp": ts}
+	if got := sendWebhook(t, app, body, headers); got != http.StatusOK {
+		t.Fatalf("status: got=%d want=200", got)
+	}
+	if got := sendWebhook(t, app, `{"type":2}`, headers); got != http.StatusUnauthorized {
+		t.Fatalf("tampered body: got=%d want=401", got)
+	}
+}
@@ -76,6 +76,23 @@ func RequireUserRole(role string) fiber.Handler {
 	}
 }
 
+// RequireWebhookSource allows webhook principals of the given sources, or of
+// any source when none are given.
+//
+// Usage: r.Post("/hooks/stripe", authn.Any(stripe), authz.RequireWebhookSource("stripe"), h.Stripe)
+func RequireWebhookSource(sources ...string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		p, ok := authn.PrincipalFromContext(c.Context())
+		if !ok || p == nil || p.Type != authn.PrincipalWebhook {
+			return denyForbidden(c)
+		}
+		if len(sources) == 0 || lo.Contains(sources, p.Subject) {
+			return c.Next()
+		}
+		return denyForbidden(c)
+	}
+}
+
 var (
 	errUnauthorized = fiber.ErrUnauthorized
 	errConflict     = fiber.ErrForbidden
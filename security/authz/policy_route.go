@@ -12,8 +12,9 @@ import (
 )
 
 const (
-	defaultUserPolicyScheme = "BearerAuth"
-	defaultAppPolicyScheme  = "ApiKeyAuth"
+	defaultUserPolicyScheme    = "BearerAuth"
+	defaultAppPolicyScheme     = "ApiKeyAuth"
+	defaultWebhookPolicyScheme = "WebhookSignature"
 )
 
 var (
@@ -146,6 +147,8 @@ func securityRequirementsForPolicy(def PolicyDefinition) []web.SecurityRequireme
 		schemes = []string{defaultUserPolicyScheme}
 	case authn.PrincipalApp:
 		schemes = []string{defaultAppPolicyScheme}
+	case authn.PrincipalWebhook:
+		schemes = []string{defaultWebhookPolicyScheme}
 	}
 
 	allScopes := normalizeStringList(def.AllScopes)
@@ -12,9 +12,10 @@ import (
 type RouteScopeOption func(*routeScopeConfig)
 
 type routeScopeConfig struct {
-	registry    *web.MetadataRegistry
-	userSchemes map[string]struct{}
-	appSchemes  map[string]struct{}
+	registry       *web.MetadataRegistry
+	userSchemes    map[string]struct{}
+	appSchemes     map[string]struct{}
+	webhookSchemes map[string]struct{}
 }
 
 func defaultRouteScopeConfig() routeScopeConfig {
@@ -31,6 +32,9 @@ func defaultRouteScopeConfig() routeScopeConfig {
 			"ApiKey",
 			"APIKey",
 		),
+		webhookSchemes: toSet(
+			"WebhookSignature",
+		),
 	}
 }
 
@@ -58,6 +62,14 @@ func WithAppScopeSchemes(schemes ...string) RouteScopeOption {
 	}
 }
 
+func WithWebhookScopeSchemes(schemes ...string) RouteScopeOption {
+	return func(c *routeScopeConfig) {
+		if len(schemes) > 0 {
+			c.webhookSchemes = toSet(schemes...)
+		}
+	}
+}
+
 func RequireRouteScopes(opts ...RouteScopeOption) fiber.Handler {
 	cfg := defaultRouteScopeConfig()
 	for _, opt := range opts {
@@ -167,6 +179,9 @@ func relevantSecurityRequirements(in []web.SecurityRequirement, principalType au
 		case authn.PrincipalApp:
 			_, ok := cfg.appSchemes[scheme]
 			return ok
+		case authn.PrincipalWebhook:
+			_, ok := cfg.webhookSchemes[scheme]
+			return ok
 		}
 		return false
 	})
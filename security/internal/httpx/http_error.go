@@ -12,7 +12,7 @@ func Unauthorized(c fiber.Ctx, message string) error {
 	return c.Status(fiber.StatusUnauthorized).JSON(web.Error{
 		Error: web.ErrorDetail{
 			Code:    "UNAUTHORIZED",
-			Message: message,
+			Message: web.LocalizeErrorMessage(c, "UNAUTHORIZED", message),
 		},
 	})
 }
@@ -24,7 +24,7 @@ func Forbidden(c fiber.Ctx, message string) error {
 	return c.Status(fiber.StatusForbidden).JSON(web.Error{
 		Error: web.ErrorDetail{
 			Code:    "FORBIDDEN",
-			Message: message,
+			Message: web.LocalizeErrorMessage(c, "FORBIDDEN", message),
 		},
 	})
 }
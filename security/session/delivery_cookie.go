@@ -1,6 +1,9 @@
 package session
 
-import "github.com/gofiber/fiber/v3"
+import (
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
 
 type CookiePairDelivererConfig struct {
 	AccessTokenCookieName  string
@@ -43,6 +46,8 @@ func CookiePairDeliverer(cfg CookiePairDelivererConfig) PairDeliverer {
 
 		c.Cookie(&accessCookie)
 		c.Cookie(&refreshCookie)
+		// A new session gets a new CSRF token when web.csrf is enabled.
+		web.RotateCSRFToken(c)
 
 		if cfg.IncludeJSONBody {
 			return c.Status(cfg.StatusCode).JSON(pair)
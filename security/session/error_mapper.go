@@ -0,0 +1,28 @@
+package session
+
+import (
+	"net/http"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+)
+
+// ErrorMapper renders token validation errors through the web error handler.
+type ErrorMapper struct {
+	mappings web.ErrorMappings
+}
+
+func NewErrorMapper() *ErrorMapper {
+	return &ErrorMapper{
+		mappings: web.ErrorMappings{
+			{Target: ErrTokenRevoked, Status: http.StatusUnauthorized, Code: "TOKEN_REVOKED", Message: "token revoked"},
+			{Target: ErrInvalidTokenType, Status: http.StatusUnauthorized, Code: "TOKEN_INVALID", Message: "invalid token type"},
+			{Target: ErrInvalidClaims, Status: http.StatusUnauthorized, Code: "TOKEN_INVALID", Message: "invalid token claims"},
+			{Target: ErrMissingTokenSub, Status: http.StatusUnauthorized, Code: "TOKEN_INVALID", Message: "token has no subject"},
+			{Target: ErrTokenMissingInContext, Status: http.StatusUnauthorized, Code: "UNAUTHORIZED", Message: "unauthorized"},
+		},
+	}
+}
+
+func (m *ErrorMapper) MapError(err error) (*web.HTTPError, bool) {
+	return m.mappings.MapError(err)
+}
@@ -22,6 +22,7 @@ func Providers(opts ...di.Node) di.Node {
 			di.AsSelf[Rotator](),
 			di.AsSelf[MiddlewareFactory](),
 		),
+		di.Provide(NewErrorMapper),
 	}
 	nodes = append(nodes, di.ConvertAnys(opts)...)
 	return di.Options(nodes...)
@@ -29,6 +29,7 @@ func Providers(extends ...di.Node) di.Node {
 			},
 			di.AsSelf[Presigner](),
 		),
+		di.Provide(NewHealthChecker),
 	}
 	nodes = append(nodes, di.ConvertAnys(extends)...)
 	return di.Options(nodes...)
@@ -0,0 +1,32 @@
+package s3
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// HealthChecker issues HeadBucket against the configured bucket for the
+// health service. Without a configured bucket there is nothing to probe and
+// the check passes.
+type HealthChecker struct {
+	client BucketManager
+	bucket string
+}
+
+func NewHealthChecker(client BucketManager, cfg Config) *HealthChecker {
+	return &HealthChecker{client: client, bucket: cfg.Bucket}
+}
+
+func (h *HealthChecker) Name() string {
+	return "s3"
+}
+
+func (h *HealthChecker) Check(ctx context.Context) error {
+	if h.bucket == "" {
+		return nil
+	}
+	_, err := h.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(h.bucket)})
+	return err
+}
@@ -0,0 +1,205 @@
+// Package s3web streams multipart/form-data uploads from web routes into S3.
+package s3web
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/bronystylecrazy/ultrastructure/imgutil"
+	"github.com/bronystylecrazy/ultrastructure/storage/s3"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+)
+
+// StreamUploadConfig configures StreamUpload.
+type StreamUploadConfig struct {
+	Bucket string
+	Limits web.MultipartLimits
+	// Key names the object of a file part. It defaults to a random UUID with
+	// the file's extension.
+	Key      func(c fiber.Ctx, file *web.MultipartFile) string
+	PartSize int64
+	// Checksum computes the hex SHA-256 of each file.
+	Checksum bool
+	// ThumbHash computes the ThumbHash of image files while they upload. The
+	// decoded image is held in memory, so pair it with a small MaxSize.
+	ThumbHash bool
+	Options   []s3.MultipartInputOption
+}
+
+// UploadedFile is a file part stored by StreamUpload.
+type UploadedFile struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Bucket      string
+	Key         string
+	ETag        string
+	VersionID   string
+	Size        int64
+	Checksum    string
+	ThumbHash   *imgutil.ThumbHashResult
+}
+
+// UploadResult is the parsed form handed to an UploadHandler.
+type UploadResult struct {
+	Fields map[string][]string
+	Files  []UploadedFile
+}
+
+// Value returns the first value of a text field.
+func (r *UploadResult) Value(name string) string {
+	if values := r.Fields[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// File returns the first file uploaded in field.
+func (r *UploadResult) File(field string) (UploadedFile, bool) {
+	for _, file := range r.Files {
+		if file.Field == field {
+			return file, true
+		}
+	}
+	return UploadedFile{}, false
+}
+
+// StreamUpload reads a multipart/form-data request with web.ReadMultipart and
+// pipes each file part into its own S3 multipart upload. If the request fails
+// part way, files already stored are deleted when uploader is also a Deleter.
+func StreamUpload(c fiber.Ctx, uploader s3.MultipartUploader, cfg StreamUploadConfig) (*UploadResult, error) {
+	ctx := c.Context()
+	result := &UploadResult{}
+	fields, err := web.ReadMultipart(c, cfg.Limits, func(file *web.MultipartFile) error {
+		key := ""
+		if cfg.Key != nil {
+			key = cfg.Key(c, file)
+		}
+		if key == "" {
+			key = uuid.NewString() + strings.ToLower(path.Ext(file.Filename))
+		}
+		uploaded, err := streamFile(ctx, uploader, cfg, key, file)
+		if err != nil {
+			return err
+		}
+		result.Files = append(result.Files, uploaded)
+		return nil
+	})
+	if err != nil {
+		discardUploads(ctx, uploader, result.Files)
+		return nil, err
+	}
+	result.Fields = fields
+	return result, nil
+}
+
+// UploadHandler returns a handler that runs StreamUpload and passes the result
+// to handle.
+// Usage: r.Post("/avatars", s3web.UploadHandler(client, cfg, h.SaveAvatar)).MultipartStream(AvatarForm{})
+func UploadHandler(uploader s3.MultipartUploader, cfg StreamUploadConfig, handle func(c fiber.Ctx, result *UploadResult) error) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		result, err := StreamUpload(c, uploader, cfg)
+		if err != nil {
+			return err
+		}
+		return handle(c, result)
+	}
+}
+
+func streamFile(ctx context.Context, uploader s3.MultipartUploader, cfg StreamUploadConfig, key string, file *web.MultipartFile) (UploadedFile, error) {
+	var r io.Reader = file
+	var sum hash.Hash
+	if cfg.Checksum {
+		sum = sha256.New()
+		r = io.TeeReader(r, sum)
+	}
+	var thumb *thumbHashWriter
+	if cfg.ThumbHash && strings.HasPrefix(file.ContentType, "image/") {
+		thumb = newThumbHashWriter(ctx)
+		r = io.TeeReader(r, thumb)
+	}
+
+	opts := append([]s3.MultipartInputOption{s3.WithMultipartContentType(file.ContentType)}, cfg.Options...)
+	out, err := s3.UploadStream(ctx, uploader, cfg.Bucket, key, r, cfg.PartSize, opts...)
+	if thumb != nil {
+		thumb.Close()
+	}
+	if err != nil {
+		return UploadedFile{}, err
+	}
+
+	uploaded := UploadedFile{
+		Field:       file.Field,
+		Filename:    file.Filename,
+		ContentType: file.ContentType,
+		Bucket:      out.Bucket,
+		Key:         out.Key,
+		ETag:        out.ETag,
+		VersionID:   out.VersionID,
+		Size:        out.Size,
+	}
+	if sum != nil {
+		uploaded.Checksum = hex.EncodeToString(sum.Sum(nil))
+	}
+	if thumb != nil {
+		uploaded.ThumbHash = thumb.Result()
+	}
+	return uploaded, nil
+}
+
+func discardUploads(ctx context.Context, uploader s3.MultipartUploader, files []UploadedFile) {
+	deleter, ok := uploader.(s3.Deleter)
+	if !ok {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	for _, file := range files {
+		_, _ = deleter.DeleteObject(ctx, &awss3.DeleteObjectInput{Bucket: aws.String(file.Bucket), Key: aws.String(file.Key)})
+	}
+}
+
+// thumbHashWriter decodes an image from the bytes written to it. Writes never
+// fail, so a bad image only leaves the result empty.
+type thumbHashWriter struct {
+	pw     *io.PipeWriter
+	done   chan struct{}
+	result *imgutil.ThumbHashResult
+}
+
+func newThumbHashWriter(ctx context.Context) *thumbHashWriter {
+	pr, pw := io.Pipe()
+	w := &thumbHashWriter{pw: pw, done: make(chan struct{})}
+	go func() {
+		defer close(w.done)
+		result, err := imgutil.EncodeThumbHashResultFromReader(ctx, pr)
+		// Unblock the writer once the decoder has what it needs.
+		_ = pr.CloseWithError(io.ErrClosedPipe)
+		if err == nil {
+			w.result = &result
+		}
+	}()
+	return w
+}
+
+func (w *thumbHashWriter) Write(p []byte) (int, error) {
+	_, _ = w.pw.Write(p)
+	return len(p), nil
+}
+
+func (w *thumbHashWriter) Close() {
+	_ = w.pw.Close()
+	<-w.done
+}
+
+func (w *thumbHashWriter) Result() *imgutil.ThumbHashResult {
+	return w.result
+}
@@ -0,0 +1,226 @@
+package s3web
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awss3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/bronystylecrazy/ultrastructure/storage/s3"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
+
+type fakeMultipartStore struct {
+	mu        sync.Mutex
+	parts     map[string][][]byte
+	objects   map[string][]byte
+	aborted   []string
+	deleted   []string
+	partSizes []int
+}
+
+func newFakeMultipartStore() *fakeMultipartStore {
+	return &fakeMultipartStore{parts: map[string][][]byte{}, objects: map[string][]byte{}}
+}
+
+func (f *fakeMultipartStore) CreateMultipartUpload(_ context.Context, in *awss3.CreateMultipartUploadInput, _ ...func(*awss3.Options)) (*awss3.CreateMultipartUploadOutput, error) {
+	return &awss3.CreateMultipartUploadOutput{UploadId: in.Key}, nil
+}
+
+func (f *fakeMultipartStore) UploadPart(_ context.Context, in *awss3.UploadPartInput, _ ...func(*awss3.Options)) (*awss3.UploadPartOutput, error) {
+	body, _ := io.ReadAll(in.Body)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.parts[*in.UploadId] = append(f.parts[*in.UploadId], body)
+	f.partSizes = append(f.partSizes, len(body))
+	return &awss3.UploadPartOutput{ETag: aws.String("etag")}, nil
+}
+
+func (f *fakeMultipartStore) CompleteMultipartUpload(_ context.Context, in *awss3.CompleteMultipartUploadInput, _ ...func(*awss3.Options)) (*awss3.CompleteMultipartUploadOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[*in.Key] = bytes.Join(f.parts[*in.UploadId], nil)
+	return &awss3.CompleteMultipartUploadOutput{ETag: aws.String("final")}, nil
+}
+
+func (f *fakeMultipartStore) AbortMultipartUpload(_ context.Context, in *awss3.AbortMultipartUploadInput, _ ...func(*awss3.Options)) (*awss3.AbortMultipartUploadOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.aborted = append(f.aborted, *in.Key)
+	return &awss3.AbortMultipartUploadOutput{}, nil
+}
+
+func (f *fakeMultipartStore) DeleteObject(_ context.Context, in *awss3.DeleteObjectInput, _ ...func(*awss3.Options)) (*awss3.DeleteObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deleted = append(f.deleted, *in.Key)
+	delete(f.objects, *in.Key)
+	return &awss3.DeleteObjectOutput{}, nil
+}
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	for x := 0; x < 16; x++ {
+		for y := 0; y < 8; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 16), G: 80, B: 160, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func newUploadRequest(t *testing.T, files map[string][]byte, fields map[string]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, value := range fields {
+		_ = w.WriteField(name, value)
+	}
+	for name, data := range files {
+		part, err := w.CreateFormFile(name, name+".bin")
+		if err != nil {
+			t.Fatalf("create part: %v", err)
+		}
+		_, _ = part.Write(data)
+	}
+	_ = w.Close()
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set(fiber.HeaderContentType, w.FormDataContentType())
+	return req
+}
+
+func newUploadApp(store *fakeMultipartStore, cfg StreamUploadConfig, got **UploadResult) *fiber.App {
+	app := fiber.New(fiber.Config{
+		StreamRequestBody: true,
+		ErrorHandler:      web.NewErrorHandler(web.Config{}, nil).HandleError,
+	})
+	app.Post("/upload", UploadHandler(store, cfg, func(c fiber.Ctx, result *UploadResult) error {
+		*got = result
+		return c.SendStatus(http.StatusCreated)
+	}))
+	return app
+}
+
+func TestStreamUploadPipesFilesIntoMultipartUploads(t *testing.T) {
+	store := newFakeMultipartStore()
+	var result *UploadResult
+	app := newUploadApp(store, StreamUploadConfig{
+		Bucket: "media",
+		Limits: web.MultipartLimits{Files: map[string]web.MultipartFileLimit{
+			"avatar": {MaxSize: 1 << 20, AllowedTypes: []string{"image/*"}},
+			"doc":    {MaxSize: 8 << 20},
+		}},
+		Key:       func(_ fiber.Ctx, file *web.MultipartFile) string { return "uploads/" + file.Field },
+		Checksum:  true,
+		ThumbHash: true,
+	}, &result)
+
+	avatar := testPNG(t)
+	doc := bytes.Repeat([]byte("x"), s3.MinPartSize+10)
+	res, err := app.Test(newUploadRequest(t, map[string][]byte{"avatar": avatar, "doc": doc}, map[string]string{"title": "hello"}))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	if res.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(res.Body)
+		t.Fatalf("status=%d body=%s", res.StatusCode, body)
+	}
+
+	if result.Value("title") != "hello" {
+		t.Fatalf("fields: %v", result.Fields)
+	}
+	file, ok := result.File("avatar")
+	if !ok || file.ContentType != "image/png" || file.Size != int64(len(avatar)) || file.Key != "uploads/avatar" {
+		t.Fatalf("avatar: %+v", file)
+	}
+	sum := sha256.Sum256(avatar)
+	if file.Checksum != hex.EncodeToString(sum[:]) {
+		t.Fatalf("checksum: %s", file.Checksum)
+	}
+	if file.ThumbHash == nil || file.ThumbHash.Width == 0 || file.ThumbHash.HashBase64 == "" {
+		t.Fatalf("thumbhash: %+v", file.ThumbHash)
+	}
+	if !bytes.Equal(store.objects["uploads/doc"], doc) || len(store.parts["uploads/doc"]) != 2 {
+		t.Fatalf("doc must be uploaded in two parts, got %d", len(store.parts["uploads/doc"]))
+	}
+	for _, size := range store.partSizes {
+		if size > s3.MinPartSize {
+			t.Fatalf("part of %d bytes exceeds the part buffer", size)
+		}
+	}
+}
+
+func TestStreamUploadEnforcesLimitsAndCleansUp(t *testing.T) {
+	store := newFakeMultipartStore()
+	var result *UploadResult
+	app := newUploadApp(store, StreamUploadConfig{
+		Bucket: "media",
+		Limits: web.MultipartLimits{Default: web.MultipartFileLimit{MaxSize: 1024}},
+		Key:    func(_ fiber.Ctx, file *web.MultipartFile) string { return file.Field },
+	}, &result)
+
+	req := newUploadRequest(t, map[string][]byte{"a": []byte("small"), "b": bytes.Repeat([]byte("y"), 4096)}, nil)
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusRequestEntityTooLarge || !strings.Contains(string(body), "MULTIPART_LIMIT") {
+		t.Fatalf("status=%d body=%s", res.StatusCode, body)
+	}
+	if len(store.aborted) != 1 || store.aborted[0] != "b" {
+		t.Fatalf("oversized upload must be aborted, got %v", store.aborted)
+	}
+	if len(store.objects) != 0 {
+		t.Fatalf("stored files must be deleted on failure, got %v", store.deleted)
+	}
+
+	store = newFakeMultipartStore()
+	app = newUploadApp(store, StreamUploadConfig{
+		Bucket: "media",
+		Limits: web.MultipartLimits{Default: web.MultipartFileLimit{AllowedTypes: []string{"image/png"}}},
+	}, &result)
+	res, err = app.Test(newUploadRequest(t, map[string][]byte{"a": []byte("<html><body>hi</body></html>")}, nil))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	if res.StatusCode != http.StatusUnsupportedMediaType || len(store.parts) != 0 {
+		t.Fatalf("sniffed html must be rejected before uploading: status=%d", res.StatusCode)
+	}
+
+	var form bytes.Buffer
+	w := multipart.NewWriter(&form)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="a"; filename="a.png"`)
+	header.Set(fiber.HeaderContentType, "image/png")
+	part, _ := w.CreatePart(header)
+	_, _ = part.Write([]byte{0x7f, 'E', 'L', 'F', 2, 1, 1, 0})
+	_ = w.Close()
+	req = httptest.NewRequest(http.MethodPost, "/upload", &form)
+	req.Header.Set(fiber.HeaderContentType, w.FormDataContentType())
+	res, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	if res.StatusCode != http.StatusUnsupportedMediaType || len(store.parts) != 0 {
+		t.Fatalf("binary declared as image/png must be rejected: status=%d", res.StatusCode)
+	}
+}
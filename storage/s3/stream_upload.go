@@ -0,0 +1,127 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// MinPartSize is the smallest part S3 accepts, except for the last one.
+const MinPartSize = 5 << 20
+
+type MultipartInputOption func(*s3.CreateMultipartUploadInput)
+
+func WithMultipartContentType(contentType string) MultipartInputOption {
+	return func(in *s3.CreateMultipartUploadInput) {
+		in.ContentType = aws.String(contentType)
+	}
+}
+
+func WithMultipartACL(acl string) MultipartInputOption {
+	return func(in *s3.CreateMultipartUploadInput) {
+		in.ACL = s3types.ObjectCannedACL(acl)
+	}
+}
+
+type StreamUploadOutput struct {
+	Bucket    string
+	Key       string
+	ETag      string
+	VersionID string
+	Size      int64
+}
+
+// UploadStream uploads r with a multipart upload, holding one part of
+// partSize bytes in memory at a time. A partSize below MinPartSize uses
+// MinPartSize. The upload is aborted when reading or uploading fails,
+// including when ctx is canceled.
+func UploadStream(ctx context.Context, uploader MultipartUploader, bucket, key string, r io.Reader, partSize int64, opts ...MultipartInputOption) (*StreamUploadOutput, error) {
+	if bucket == "" || key == "" {
+		return nil, fmt.Errorf("bucket and key must be set")
+	}
+	if partSize < MinPartSize {
+		partSize = MinPartSize
+	}
+
+	input := &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(input)
+		}
+	}
+	created, err := uploader.CreateMultipartUpload(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("create multipart upload: %w", err)
+	}
+
+	out, err := uploadParts(ctx, uploader, bucket, key, created.UploadId, r, partSize)
+	if err != nil {
+		_, _ = uploader.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(bucket),
+			Key:      aws.String(key),
+			UploadId: created.UploadId,
+		})
+		return nil, err
+	}
+	return out, nil
+}
+
+func uploadParts(ctx context.Context, uploader MultipartUploader, bucket, key string, uploadID *string, r io.Reader, partSize int64) (*StreamUploadOutput, error) {
+	buf := make([]byte, partSize)
+	var parts []s3types.CompletedPart
+	var size int64
+	for number := int32(1); ; number++ {
+		n, readErr := io.ReadFull(r, buf)
+		if readErr != nil && !errors.Is(readErr, io.EOF) && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+			return nil, readErr
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// S3 needs at least one part, so an empty body uploads an empty one.
+		if n > 0 || number == 1 {
+			part, err := uploader.UploadPart(ctx, &s3.UploadPartInput{
+				Bucket:        aws.String(bucket),
+				Key:           aws.String(key),
+				UploadId:      uploadID,
+				PartNumber:    aws.Int32(number),
+				Body:          bytes.NewReader(buf[:n]),
+				ContentLength: aws.Int64(int64(n)),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("upload part %d: %w", number, err)
+			}
+			parts = append(parts, s3types.CompletedPart{ETag: part.ETag, PartNumber: aws.Int32(number)})
+			size += int64(n)
+		}
+		if readErr != nil {
+			break
+		}
+	}
+
+	done, err := uploader.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(key),
+		UploadId:        uploadID,
+		MultipartUpload: &s3types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("complete multipart upload: %w", err)
+	}
+	return &StreamUploadOutput{
+		Bucket:    bucket,
+		Key:       key,
+		ETag:      aws.ToString(done.ETag),
+		VersionID: aws.ToString(done.VersionId),
+		Size:      size,
+	}, nil
+}
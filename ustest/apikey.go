@@ -0,0 +1,54 @@
+package ustest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/bronystylecrazy/ultrastructure/security/apikey"
+)
+
+// APIKeyStore is an in-memory apikey.KeyLookup for tests.
+//
+// Usage: di.Supply(store, di.As[apikey.KeyLookup]()) and
+// .APIKey(store.Issue(t, keys, "app-1", apikey.WithScopes("orders:read")))
+type APIKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]*apikey.StoredKey
+}
+
+func NewAPIKeyStore() *APIKeyStore {
+	return &APIKeyStore{keys: make(map[string]*apikey.StoredKey)}
+}
+
+func (s *APIKeyStore) FindByKeyID(ctx context.Context, keyID string) (*apikey.StoredKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys[keyID], nil
+}
+
+// Add stores an issued key so the service validates it.
+func (s *APIKeyStore) Add(key *apikey.IssuedKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[key.KeyID] = &apikey.StoredKey{
+		KeyID:      key.KeyID,
+		AppID:      key.AppID,
+		SecretHash: key.SecretHash,
+		Scopes:     append([]string(nil), key.Scopes...),
+		Metadata:   key.Metadata,
+		ExpiresAt:  key.ExpiresAt,
+	}
+}
+
+// Issue issues a key for appID through manager, stores it and returns the
+// raw key.
+func (s *APIKeyStore) Issue(t testing.TB, manager apikey.Manager, appID string, opts ...apikey.IssueOption) string {
+	t.Helper()
+	key, err := manager.IssueKey(appID, opts...)
+	if err != nil {
+		t.Fatalf("ustest: issue api key for %q: %v", appID, err)
+	}
+	s.Add(key)
+	return key.RawKey
+}
@@ -0,0 +1,410 @@
+package ustest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bronystylecrazy/ultrastructure/security/authn"
+	"github.com/bronystylecrazy/ultrastructure/security/session"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
+
+// PrincipalHeader carries the id of a principal registered by Request.As.
+// The injector removes it before the request reaches the handlers.
+const PrincipalHeader = "X-Ustest-Principal"
+
+// Client sends requests to the app under test through fiber's App.Test,
+// without a network listener.
+type Client struct {
+	app    *App
+	header http.Header
+}
+
+// HTTP returns a client for the app's FiberServer. Provide the handlers with
+// web.InitHandlers instead of web.Init to keep the server from listening.
+//
+// Usage: app.HTTP().Post("/orders").JSON(body).As(principal).Expect(201)
+func (a *App) HTTP() *Client {
+	a.t.Helper()
+	if a.server == nil {
+		a.t.Fatalf("ustest: the app has no *web.FiberServer; add web.Providers and web.InitHandlers")
+	}
+	return &Client{app: a, header: make(http.Header)}
+}
+
+// Header sets a header sent with every request of the client.
+func (c *Client) Header(key, value string) *Client {
+	c.header.Set(key, value)
+	return c
+}
+
+func (c *Client) Get(path string) *Request    { return c.Request(http.MethodGet, path) }
+func (c *Client) Post(path string) *Request   { return c.Request(http.MethodPost, path) }
+func (c *Client) Put(path string) *Request    { return c.Request(http.MethodPut, path) }
+func (c *Client) Patch(path string) *Request  { return c.Request(http.MethodPatch, path) }
+func (c *Client) Delete(path string) *Request { return c.Request(http.MethodDelete, path) }
+
+// Request starts a request with method and path; path may carry a query.
+func (c *Client) Request(method, path string) *Request {
+	return &Request{
+		app:    c.app,
+		method: method,
+		path:   path,
+		query:  make(url.Values),
+		header: c.header.Clone(),
+	}
+}
+
+// Request is a request being built. Failures are reported to the test.
+type Request struct {
+	app       *App
+	method    string
+	path      string
+	query     url.Values
+	header    http.Header
+	body      []byte
+	principal *authn.Principal
+}
+
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// JSON sends v encoded as JSON.
+func (r *Request) JSON(v any) *Request {
+	r.app.t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		r.app.t.Fatalf("ustest: encode %s %s body: %v", r.method, r.path, err)
+	}
+	return r.Body(web.ContentTypeApplicationJSON, data)
+}
+
+// Body sends data as contentType.
+func (r *Request) Body(contentType string, data []byte) *Request {
+	r.header.Set(fiber.HeaderContentType, contentType)
+	r.body = data
+	return r
+}
+
+// As authenticates the request as p without credentials. authn.Any accepts
+// it through a test authenticator tried ahead of the route's own.
+func (r *Request) As(p *authn.Principal) *Request {
+	r.principal = p
+	return r
+}
+
+// Bearer sends token in the Authorization header.
+func (r *Request) Bearer(token string) *Request {
+	return r.Header(fiber.HeaderAuthorization, "Bearer "+token)
+}
+
+// Token issues an access token for subject and sends it as a bearer token.
+//
+// Usage: .Token(sessions, "user-1", session.WithAccessClaims(map[string]any{"scope": "orders:write"}))
+func (r *Request) Token(issuer session.Issuer, subject string, opts ...session.GenerateOption) *Request {
+	r.app.t.Helper()
+	pair, err := issuer.Generate(subject, opts...)
+	if err != nil {
+		r.app.t.Fatalf("ustest: issue token for %q: %v", subject, err)
+	}
+	return r.Bearer(pair.AccessToken)
+}
+
+// APIKey sends rawKey in the X-API-Key header.
+func (r *Request) APIKey(rawKey string) *Request {
+	return r.Header("X-API-Key", rawKey)
+}
+
+// Do sends the request. A response whose status the route declares, or any
+// 2xx response, is checked against the route's metadata.
+func (r *Request) Do() *Response {
+	t := r.app.t
+	t.Helper()
+
+	target := r.path
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + r.query.Encode()
+	}
+	req := httptest.NewRequest(r.method, target, bytes.NewReader(r.body))
+	for key, values := range r.header {
+		req.Header[key] = values
+	}
+	if r.principal != nil {
+		id := r.app.principals.add(r.principal)
+		defer r.app.principals.remove(id)
+		req.Header.Set(PrincipalHeader, id)
+	}
+
+	res, err := r.app.server.App.Test(req, fiber.TestConfig{Timeout: 0})
+	if err != nil {
+		t.Fatalf("ustest: %s %s: %v", r.method, r.path, err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("ustest: %s %s: read body: %v", r.method, r.path, err)
+	}
+
+	out := &Response{
+		t:          t,
+		name:       r.method + " " + r.path,
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       body,
+	}
+	out.checkRoute(r.app.route(r.method, req.URL.Path))
+	return out
+}
+
+// Expect sends the request and fails the test unless the status is status.
+func (r *Request) Expect(status int) *Response {
+	r.app.t.Helper()
+	res := r.Do()
+	if res.StatusCode != status {
+		r.app.t.Fatalf("ustest: %s %s: status got=%d want=%d body=%s", r.method, r.path, res.StatusCode, status, res.Body)
+	}
+	return res
+}
+
+// Response is a received response.
+type Response struct {
+	t          testing.TB
+	name       string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// JSON decodes the body into out.
+func (r *Response) JSON(out any) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, out); err != nil {
+		r.t.Fatalf("ustest: %s: decode body %s: %v", r.name, r.Body, err)
+	}
+	return r
+}
+
+// JSONPath fails the test unless the value at path equals want once both are
+// JSON encoded. Paths are dotted with numeric array indexes, such as
+// "items.0.id" or "$.items[0].id".
+func (r *Response) JSONPath(path string, want any) *Response {
+	r.t.Helper()
+	var doc any
+	if err := json.Unmarshal(r.Body, &doc); err != nil {
+		r.t.Fatalf("ustest: %s: decode body %s: %v", r.name, r.Body, err)
+	}
+	got, ok := lookupJSONPath(doc, path)
+	if !ok {
+		r.t.Fatalf("ustest: %s: %s not found in %s", r.name, path, r.Body)
+	}
+	data, err := json.Marshal(want)
+	if err != nil {
+		r.t.Fatalf("ustest: %s: encode %s: %v", r.name, path, err)
+	}
+	var normalized any
+	_ = json.Unmarshal(data, &normalized)
+	if !reflect.DeepEqual(got, normalized) {
+		r.t.Fatalf("ustest: %s: %s got=%v want=%v", r.name, path, got, normalized)
+	}
+	return r
+}
+
+func lookupJSONPath(doc any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	if path == "" {
+		return doc, true
+	}
+	current := doc
+	for _, part := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			v, ok := node[part]
+			if !ok {
+				return nil, false
+			}
+			current = v
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// checkRoute fails the test when the response breaks the route's declared
+// status, content type or JSON body type.
+func (r *Response) checkRoute(meta *web.RouteMetadata) {
+	r.t.Helper()
+	if meta == nil || len(meta.Responses) == 0 {
+		return
+	}
+	declared, ok := meta.Responses[r.StatusCode]
+	if !ok {
+		if r.StatusCode >= 200 && r.StatusCode < 300 {
+			r.t.Fatalf("ustest: %s: status %d is not declared by the route", r.name, r.StatusCode)
+		}
+		return
+	}
+	if declared.NoContent {
+		if len(r.Body) > 0 {
+			r.t.Fatalf("ustest: %s: status %d is declared without content, got %s", r.name, r.StatusCode, r.Body)
+		}
+		return
+	}
+	if len(r.Body) == 0 {
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get(fiber.HeaderContentType))
+	types := declaredTypes(declared, mediaType)
+	if types == nil {
+		r.t.Fatalf("ustest: %s: content type %q is not declared for status %d", r.name, mediaType, r.StatusCode)
+	}
+	if mediaType != web.ContentTypeApplicationJSON || meta.Pagination != nil {
+		return
+	}
+	var errs []string
+	for _, t := range types {
+		if t == nil {
+			return
+		}
+		dec := json.NewDecoder(bytes.NewReader(r.Body))
+		dec.DisallowUnknownFields()
+		err := dec.Decode(reflect.New(t).Interface())
+		if err == nil {
+			return
+		}
+		errs = append(errs, t.String()+": "+err.Error())
+	}
+	if len(errs) > 0 {
+		r.t.Fatalf("ustest: %s: body does not match the declared type (%s): %s", r.name, strings.Join(errs, "; "), r.Body)
+	}
+}
+
+// declaredTypes returns the types declared for mediaType, or nil when the
+// response does not declare it.
+func declaredTypes(declared web.ResponseMetadata, mediaType string) []reflect.Type {
+	if variants := declared.ContentVariants[mediaType]; len(variants) > 0 {
+		return variants
+	}
+	if t, ok := declared.Content[mediaType]; ok {
+		return []reflect.Type{t}
+	}
+	if declared.ContentType == "" && len(declared.Content) == 0 {
+		return []reflect.Type{declared.Type}
+	}
+	if contentType, _, _ := mime.ParseMediaType(declared.ContentType); contentType == mediaType {
+		return []reflect.Type{declared.Type}
+	}
+	return nil
+}
+
+// principalInjector authenticates requests carrying PrincipalHeader with the
+// principal registered under its id. Routes without authn middleware see the
+// principal too, and authn.Any accepts it through the authenticator it adds
+// with authn.UseAuthenticators.
+type principalInjector struct {
+	next       atomic.Int64
+	principals sync.Map
+}
+
+const injectedPrincipalLocalsKey = "us.ustest.principal"
+
+func (p *principalInjector) add(principal *authn.Principal) string {
+	id := strconv.FormatInt(p.next.Add(1), 10)
+	p.principals.Store(id, principal)
+	return id
+}
+
+func (p *principalInjector) remove(id string) {
+	p.principals.Delete(id)
+}
+
+func (p *principalInjector) Handle(r web.Router) {
+	r.Use(p.inject)
+	r.Use(authn.UseAuthenticators(p))
+}
+
+func (p *principalInjector) inject(c fiber.Ctx) error {
+	if id := c.Get(PrincipalHeader); id != "" {
+		if principal, ok := p.principals.Load(id); ok {
+			principal := principal.(*authn.Principal)
+			c.Locals(injectedPrincipalLocalsKey, principal)
+			c.SetContext(authn.WithPrincipals(authn.WithPrincipal(c.Context(), principal), []*authn.Principal{principal}))
+			authn.SetPrincipalLocals(c, principal)
+			authn.SetPrincipalsLocals(c, []*authn.Principal{principal})
+		}
+		c.Request().Header.Del(PrincipalHeader)
+	}
+	return c.Next()
+}
+
+// Authenticate accepts the principal injected for the request.
+func (p *principalInjector) Authenticate(c fiber.Ctx) (*authn.Principal, bool, error) {
+	principal, ok := c.Locals(injectedPrincipalLocalsKey).(*authn.Principal)
+	if !ok {
+		return nil, false, nil
+	}
+	return principal, true, nil
+}
+
+// route returns the metadata of the route serving method and path, preferring
+// the pattern with the most static segments, so /orders/export wins over
+// /orders/:id.
+func (a *App) route(method, path string) *web.RouteMetadata {
+	var config fiber.Config
+	if a.server != nil {
+		config = a.server.App.Config()
+	}
+	var best *web.RouteMetadata
+	bestScore := -1
+	for key, meta := range a.routes {
+		routeMethod, pattern, ok := strings.Cut(key, ":")
+		if !ok || routeMethod != method || !fiber.RoutePatternMatch(path, pattern, config) {
+			continue
+		}
+		if score := staticSegments(pattern); score > bestScore {
+			best, bestScore = meta, score
+		}
+	}
+	return best
+}
+
+func staticSegments(pattern string) int {
+	score := 0
+	for part := range strings.SplitSeq(strings.Trim(pattern, "/"), "/") {
+		if part != "" && !strings.ContainsAny(part, ":*+") {
+			score++
+		}
+	}
+	return score
+}
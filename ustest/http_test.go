@@ -0,0 +1,137 @@
+package ustest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bronystylecrazy/ultrastructure/di"
+	"github.com/bronystylecrazy/ultrastructure/security/apikey"
+	"github.com/bronystylecrazy/ultrastructure/security/authn"
+	"github.com/bronystylecrazy/ultrastructure/security/jws"
+	"github.com/bronystylecrazy/ultrastructure/security/session"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
+
+type testOrder struct {
+	ID    string `json:"id"`
+	Item  string `json:"item"`
+	Owner string `json:"owner"`
+}
+
+type testOrderHandler struct {
+	users session.Validator
+	keys  apikey.Manager
+}
+
+func (h *testOrderHandler) Handle(r web.Router) {
+	auth := authn.Any(authn.UserTokenAuthenticator(h.users), authn.APIKeyAuthenticator(h.keys))
+	owner := func(c fiber.Ctx) string {
+		p, _ := authn.PrincipalFromContext(c.Context())
+		if p.Subject != "" {
+			return p.Subject
+		}
+		return p.AppID
+	}
+	r.Post("/orders", auth, func(c fiber.Ctx) error {
+		var order testOrder
+		if err := c.Bind().Body(&order); err != nil {
+			return err
+		}
+		order.ID, order.Owner = "o-1", owner(c)
+		return c.Status(http.StatusCreated).JSON(order)
+	}).Produces(testOrder{}, http.StatusCreated)
+	r.Get("/orders/:id", auth, func(c fiber.Ctx) error {
+		return c.JSON(fiber.Map{"id": c.Params("id"), "owner": owner(c), "status": "draft"})
+	}).Produces(testOrder{}, http.StatusOK)
+}
+
+func newHTTPTestApp(t *testing.T) (*App, *session.JWTManager, *apikey.Service, *APIKeyStore) {
+	t.Helper()
+
+	signer, err := jws.NewSigner(jws.Config{Secret: "test-secret"})
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	users, err := session.NewJWTManager(jws.Config{Secret: "test-secret"}, signer)
+	if err != nil {
+		t.Fatalf("NewJWTManager: %v", err)
+	}
+	store := NewAPIKeyStore()
+	keys := apikey.NewService(apikey.NewServiceParams{
+		Generator: apikey.NewKeyGenerator(apikey.Config{}),
+		Hasher:    apikey.NewArgon2idHasher(),
+		Lookup:    store,
+	})
+
+	app := Start(t,
+		di.Provide(func() *testOrderHandler { return &testOrderHandler{users: users, keys: keys} }),
+		web.InitHandlers(),
+	)
+	return app, users, keys, store
+}
+
+func TestHTTPClientInjectsPrincipalAndIssuesCredentials(t *testing.T) {
+	app, users, keys, store := newHTTPTestApp(t)
+	client := app.HTTP()
+
+	var created testOrder
+	client.Post("/orders").
+		JSON(testOrder{Item: "tea"}).
+		As(&authn.Principal{Type: authn.PrincipalUser, Subject: "user-1"}).
+		Expect(http.StatusCreated).
+		JSONPath("$.owner", "user-1").
+		JSON(&created)
+	if created.ID != "o-1" || created.Item != "tea" {
+		t.Fatalf("created: %+v", created)
+	}
+
+	client.Post("/orders").JSON(testOrder{Item: "tea"}).Expect(http.StatusUnauthorized)
+
+	client.Post("/orders").
+		JSON(testOrder{Item: "milk"}).
+		Token(users, "user-2").
+		Expect(http.StatusCreated).
+		JSONPath("owner", "user-2")
+
+	client.Post("/orders").
+		JSON(testOrder{Item: "milk"}).
+		APIKey(store.Issue(t, keys, "app-1")).
+		Expect(http.StatusCreated).
+		JSONPath("owner", "app-1")
+}
+
+type fatalRecorder struct {
+	testing.TB
+	msg string
+}
+
+func (f *fatalRecorder) Helper() {}
+
+func (f *fatalRecorder) Fatalf(format string, args ...any) {
+	f.msg = fmt.Sprintf(format, args...)
+	panic(f)
+}
+
+func TestHTTPClientChecksResponsesAgainstRouteMetadata(t *testing.T) {
+	app, _, _, _ := newHTTPTestApp(t)
+	client := app.HTTP()
+
+	recorder := &fatalRecorder{TB: t}
+	app.t = recorder
+	func() {
+		defer func() {
+			if r := recover(); r != nil && r != recorder {
+				panic(r)
+			}
+		}()
+		client.Get("/orders/o-1").As(&authn.Principal{Type: authn.PrincipalUser, Subject: "user-1"}).Do()
+	}()
+	app.t = t
+
+	if !strings.Contains(recorder.msg, `unknown field "status"`) {
+		t.Fatalf("expected the undeclared status field to fail the route check, got %q", recorder.msg)
+	}
+}
@@ -4,18 +4,47 @@ import (
 	"testing"
 
 	us "github.com/bronystylecrazy/ultrastructure"
+	"github.com/bronystylecrazy/ultrastructure/di"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"go.uber.org/fx"
 	"go.uber.org/fx/fxtest"
 )
 
 // App wraps fxtest.App with ultrastructure-specific constructor helpers.
 type App struct {
 	app *fxtest.App
+	t   testing.TB
+
+	server     *web.FiberServer
+	routes     map[string]*web.RouteMetadata
+	principals *principalInjector
+}
+
+type webIn struct {
+	fx.In
+
+	Server     *web.FiberServer       `optional:"true"`
+	Registries *web.RegistryContainer `optional:"true"`
 }
 
 // New builds a us.New app from nodes and returns a test app.
 func New(t testing.TB, nodes ...any) *App {
 	t.Helper()
-	return &App{app: fxtest.New(t, us.New(nodes...).Build())}
+
+	a := &App{t: t, principals: &principalInjector{}}
+	var in webIn
+	nodes = append(nodes,
+		di.Provide(func() *principalInjector { return a.principals }, web.Priority(web.Earliest)),
+		di.Populate(&in),
+	)
+	a.app = fxtest.New(t, us.New(nodes...).Build())
+
+	// Route metadata is cleared at start; keep it for response checks.
+	a.server = in.Server
+	if in.Registries != nil && in.Registries.Metadata != nil {
+		a.routes = in.Registries.Metadata.AllRoutes()
+	}
+	return a
 }
 
 // Start builds, starts, and auto-stops the app on test cleanup.
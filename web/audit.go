@@ -0,0 +1,44 @@
+package web
+
+import "github.com/gofiber/fiber/v3"
+
+// Auditor records calls to routes marked with RouteBuilder.Audit. The audit
+// package provides one and installs it per request with SetAuditorLocals.
+type Auditor interface {
+	// AuditRoute runs next and records the call as action.
+	AuditRoute(c fiber.Ctx, action string, next func() error) error
+}
+
+const auditorLocalsKey = "us.web.auditor"
+
+func SetAuditorLocals(c fiber.Ctx, auditor Auditor) {
+	c.Locals(auditorLocalsKey, auditor)
+}
+
+func AuditorFromLocals(c fiber.Ctx) (Auditor, bool) {
+	auditor, ok := c.Locals(auditorLocalsKey).(Auditor)
+	return auditor, ok && auditor != nil
+}
+
+// Audit returns a RouteOption that records every call to the route as action.
+//
+// Usage: r.Post("/orders/:id/cancel", h.Cancel).With(web.Audit("order.cancel"))
+func Audit(action string) RouteOption {
+	return func(b *RouteBuilder) *RouteBuilder {
+		return b.Audit(action)
+	}
+}
+
+// Audit records every call that reaches the route's handler as action,
+// through the Auditor installed by the audit package. Requests rejected by
+// earlier middleware, such as authentication, are not recorded. Without an
+// Auditor the route runs unaudited.
+func (b *RouteBuilder) Audit(action string) *RouteBuilder {
+	return b.Middleware(func(c fiber.Ctx, next func() error) error {
+		auditor, ok := AuditorFromLocals(c)
+		if !ok {
+			return next()
+		}
+		return auditor.AuditRoute(c, action, next)
+	})
+}
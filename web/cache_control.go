@@ -0,0 +1,75 @@
+package web
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// CacheControl describes a Cache-Control response header.
+type CacheControl struct {
+	Public         bool
+	Private        bool
+	NoCache        bool
+	NoStore        bool
+	MustRevalidate bool
+	Immutable      bool
+
+	MaxAge               time.Duration
+	SharedMaxAge         time.Duration
+	StaleWhileRevalidate time.Duration
+}
+
+func (cc CacheControl) String() string {
+	var directives []string
+	add := func(enabled bool, directive string) {
+		if enabled {
+			directives = append(directives, directive)
+		}
+	}
+	seconds := func(name string, d time.Duration) {
+		if d > 0 {
+			directives = append(directives, name+"="+strconv.FormatInt(int64(d/time.Second), 10))
+		}
+	}
+
+	add(cc.Public, "public")
+	add(cc.Private, "private")
+	add(cc.NoCache, "no-cache")
+	add(cc.NoStore, "no-store")
+	seconds("max-age", cc.MaxAge)
+	seconds("s-maxage", cc.SharedMaxAge)
+	seconds("stale-while-revalidate", cc.StaleWhileRevalidate)
+	add(cc.MustRevalidate, "must-revalidate")
+	add(cc.Immutable, "immutable")
+	return strings.Join(directives, ", ")
+}
+
+// Cache sets Cache-Control on successful responses of the route.
+// Usage: .With(web.Cache(web.CacheControl{Public: true, MaxAge: time.Minute}))
+func Cache(cc CacheControl) RouteOption {
+	return func(b *RouteBuilder) *RouteBuilder {
+		return b.Cache(cc)
+	}
+}
+
+// Cache sets Cache-Control on successful responses and documents it, along
+// with ETag and 304 Not Modified, in OpenAPI.
+func (b *RouteBuilder) Cache(cc CacheControl) *RouteBuilder {
+	value := cc.String()
+	b.Middleware(func(c fiber.Ctx, next func() error) error {
+		if err := next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() < fiber.StatusBadRequest {
+			c.Set(fiber.HeaderCacheControl, value)
+		}
+		return nil
+	})
+
+	b.SetHeaders(fiber.StatusOK, fiber.HeaderCacheControl, "", value)
+	b.SetHeaders(fiber.StatusOK, fiber.HeaderETag, "", "Entity tag for conditional requests")
+	return b.NoContent(fiber.StatusNotModified)
+}
@@ -0,0 +1,18 @@
+package web
+
+import "github.com/gofiber/fiber/v3"
+
+const callerIDLocalsKey = "us.web.caller_id"
+
+// SetCallerID records who made the request, e.g. "user:42", so features such
+// as idempotency can keep each caller's state apart. authn sets it for every
+// authenticated principal.
+func SetCallerID(c fiber.Ctx, id string) {
+	c.Locals(callerIDLocalsKey, id)
+}
+
+// CallerID returns the id set by SetCallerID, or "" for anonymous requests.
+func CallerID(c fiber.Ctx) string {
+	id, _ := c.Locals(callerIDLocalsKey).(string)
+	return id
+}
@@ -0,0 +1,88 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// CodecsGroupName is the DI group of codecs added to ContentNegotiation.
+const CodecsGroupName = "us.web.codecs"
+
+// Codec encodes response values and decodes request bodies in one format.
+// Provided codecs join the CodecsGroupName group and replace the built-in
+// codec serving the same media type.
+type Codec interface {
+	// MediaTypes lists the media types the codec serves. The first is the
+	// one documented and sent when a request accepts any of them.
+	MediaTypes() []string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// TypedCodec is a Codec that only encodes some types, like CSVCodec, which
+// only encodes lists. Negotiation skips it for other types.
+type TypedCodec interface {
+	Codec
+	CanMarshal(t reflect.Type) bool
+}
+
+// DefaultCodecs returns the built-in JSON, XML, MessagePack, CBOR and CSV
+// codecs.
+func DefaultCodecs() []Codec {
+	return []Codec{JSONCodec{}, XMLCodec{}, MsgpackCodec{}, CBORCodec{}, CSVCodec{}}
+}
+
+type JSONCodec struct{}
+
+func (JSONCodec) MediaTypes() []string { return []string{ContentTypeApplicationJSON} }
+
+func (JSONCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (JSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type XMLCodec struct{}
+
+func (XMLCodec) MediaTypes() []string {
+	return []string{ContentTypeApplicationXML, ContentTypeTextXML}
+}
+
+func (XMLCodec) Marshal(v any) ([]byte, error) { return xml.Marshal(v) }
+
+func (XMLCodec) Unmarshal(data []byte, v any) error { return xml.Unmarshal(data, v) }
+
+// MsgpackCodec encodes MessagePack, reading json tags for field names.
+type MsgpackCodec struct{}
+
+func (MsgpackCodec) MediaTypes() []string {
+	return []string{ContentTypeApplicationVndMsgpack, ContentTypeApplicationMsgpack, "application/x-msgpack"}
+}
+
+func (MsgpackCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (MsgpackCodec) Unmarshal(data []byte, v any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+// CBORCodec encodes CBOR, reading json tags for field names.
+type CBORCodec struct{}
+
+func (CBORCodec) MediaTypes() []string { return []string{ContentTypeApplicationCBOR} }
+
+func (CBORCodec) Marshal(v any) ([]byte, error) { return cbor.Marshal(v) }
+
+func (CBORCodec) Unmarshal(data []byte, v any) error { return cbor.Unmarshal(data, v) }
@@ -0,0 +1,226 @@
+package web
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// CSVCodec encodes lists of structs as CSV with a header row. Columns are
+// named by the csv tag, then the json tag, then the field name. It decodes
+// into a pointer to a slice of structs.
+type CSVCodec struct{}
+
+func (CSVCodec) MediaTypes() []string { return []string{ContentTypeTextCSV} }
+
+// CanMarshal reports whether t is a slice or array of structs.
+func (CSVCodec) CanMarshal(t reflect.Type) bool {
+	t = derefType(t)
+	if t == nil || (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) {
+		return false
+	}
+	return derefType(t.Elem()).Kind() == reflect.Struct
+}
+
+func (c CSVCodec) Marshal(v any) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() || !c.CanMarshal(rv.Type()) {
+		return nil, fmt.Errorf("csv: cannot encode %T, want a list of structs", v)
+	}
+
+	columns := csvColumns(derefType(rv.Type().Elem()))
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.name
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	row := make([]string, len(columns))
+	for i := 0; i < rv.Len(); i++ {
+		item := rv.Index(i)
+		for item.Kind() == reflect.Ptr {
+			if item.IsNil() {
+				break
+			}
+			item = item.Elem()
+		}
+		for j, col := range columns {
+			row[j] = ""
+			if item.Kind() == reflect.Struct {
+				row[j] = formatCSVValue(item.FieldByIndex(col.index))
+			}
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func (CSVCodec) Unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("csv: cannot decode into %T, want a pointer to a slice of structs", v)
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	structType := derefType(elemType)
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("csv: cannot decode into %T, want a pointer to a slice of structs", v)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	byName := make(map[string]csvColumn)
+	for _, col := range csvColumns(structType) {
+		byName[col.name] = col
+	}
+	header := records[0]
+	for line, record := range records[1:] {
+		item := reflect.New(structType).Elem()
+		for i, value := range record {
+			if i >= len(header) {
+				break
+			}
+			col, ok := byName[header[i]]
+			if !ok || value == "" {
+				continue
+			}
+			if err := parseCSVValue(item.FieldByIndex(col.index), value); err != nil {
+				return fmt.Errorf("csv: line %d, column %q: %w", line+2, header[i], err)
+			}
+		}
+		if elemType.Kind() == reflect.Ptr {
+			item = item.Addr()
+		}
+		slice.Set(reflect.Append(slice, item))
+	}
+	return nil
+}
+
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+func csvColumns(t reflect.Type) []csvColumn {
+	var columns []csvColumn
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name := field.Name
+		if tag := field.Tag.Get("csv"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			name = tagName(tag)
+		} else if tag := field.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if jsonName := tagName(tag); jsonName != "" {
+				name = jsonName
+			}
+		}
+		columns = append(columns, csvColumn{name: name, index: field.Index})
+	}
+	return columns
+}
+
+var errCSVUnsupportedType = errors.New("unsupported field type")
+
+func formatCSVValue(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func parseCSVValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Ptr {
+		ptr := reflect.New(v.Type().Elem())
+		if err := parseCSVValue(ptr.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return errCSVUnsupportedType
+	}
+	return nil
+}
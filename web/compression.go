@@ -0,0 +1,233 @@
+package web
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	EncodingGzip   = "gzip"
+	EncodingBrotli = "br"
+	EncodingZstd   = "zstd"
+
+	defaultCompressionMinSize = 1024
+)
+
+// defaultCompressibleTypes are compressed when compression.content_types is empty.
+// Entries ending in "/" match every subtype.
+var defaultCompressibleTypes = []string{
+	"text/",
+	ContentTypeApplicationJSON,
+	ContentTypeApplicationProblemJSON,
+	ContentTypeApplicationLDJSON,
+	ContentTypeApplicationNDJSON,
+	ContentTypeApplicationXML,
+	ContentTypeApplicationProblemXML,
+	ContentTypeApplicationYAML,
+	ContentTypeApplicationJavaScript,
+	ContentTypeApplicationGraphQLResponseJSON,
+	ContentTypeImageSVGXML,
+}
+
+type CompressionConfig struct {
+	Enabled bool `mapstructure:"enabled" default:"false"`
+	// Level is "default", "best_speed" or "best_compression".
+	Level string `mapstructure:"level" default:"default"`
+	// MinSize skips bodies smaller than this many bytes. Defaults to 1024.
+	MinSize int `mapstructure:"min_size" default:"1024"`
+	// ContentTypes lists compressible media types; "text/" matches all text types.
+	ContentTypes []string `mapstructure:"content_types"`
+	// Encodings is the server preference order. Defaults to br, zstd, gzip.
+	Encodings []string `mapstructure:"encodings"`
+}
+
+// CompressionMiddleware compresses response bodies with the best encoding
+// the client accepts.
+//
+// It runs outside ConditionalGetMiddleware, so ETags are computed over the
+// uncompressed body and 304 responses are never compressed.
+type CompressionMiddleware struct {
+	config    CompressionConfig
+	encodings []string
+	levels    map[string]int
+}
+
+func NewCompressionMiddleware(config Config) *CompressionMiddleware {
+	cfg := config.Compression
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = defaultCompressionMinSize
+	}
+	if len(cfg.ContentTypes) == 0 {
+		cfg.ContentTypes = defaultCompressibleTypes
+	}
+
+	m := &CompressionMiddleware{config: cfg}
+	for _, encoding := range cfg.Encodings {
+		switch encoding = strings.ToLower(strings.TrimSpace(encoding)); encoding {
+		case EncodingBrotli, EncodingZstd, EncodingGzip:
+			m.encodings = append(m.encodings, encoding)
+		}
+	}
+	if len(m.encodings) == 0 {
+		m.encodings = []string{EncodingBrotli, EncodingZstd, EncodingGzip}
+	}
+
+	switch cfg.Level {
+	case "best_speed":
+		m.levels = map[string]int{
+			EncodingGzip:   fasthttp.CompressBestSpeed,
+			EncodingBrotli: fasthttp.CompressBrotliBestSpeed,
+			EncodingZstd:   fasthttp.CompressZstdBestSpeed,
+		}
+	case "best_compression":
+		m.levels = map[string]int{
+			EncodingGzip:   fasthttp.CompressBestCompression,
+			EncodingBrotli: fasthttp.CompressBrotliBestCompression,
+			EncodingZstd:   fasthttp.CompressZstdBestCompression,
+		}
+	default:
+		m.levels = map[string]int{
+			EncodingGzip:   fasthttp.CompressDefaultCompression,
+			EncodingBrotli: fasthttp.CompressBrotliDefaultCompression,
+			EncodingZstd:   fasthttp.CompressZstdDefault,
+		}
+	}
+	return m
+}
+
+func (m *CompressionMiddleware) Handle(r Router) {
+	if !m.config.Enabled {
+		return
+	}
+	r.Use(m.Middleware)
+}
+
+func (m *CompressionMiddleware) Middleware(c fiber.Ctx) error {
+	if err := c.Next(); err != nil {
+		return err
+	}
+
+	res := c.Response()
+	if !m.compressible(string(res.Header.ContentType())) {
+		return nil
+	}
+	appendVary(c, fiber.HeaderAcceptEncoding)
+
+	if c.Method() == fiber.MethodHead || res.IsBodyStream() ||
+		len(res.Header.Peek(fiber.HeaderContentEncoding)) > 0 ||
+		c.Get(fiber.HeaderRange) != "" ||
+		headerHasToken(string(res.Header.Peek(fiber.HeaderCacheControl)), "no-transform") {
+		return nil
+	}
+	switch status := res.StatusCode(); {
+	case status < 200, status == fiber.StatusNoContent, status == fiber.StatusPartialContent, status == fiber.StatusNotModified:
+		return nil
+	}
+	body := res.Body()
+	if len(body) < m.config.MinSize {
+		return nil
+	}
+
+	encoding := negotiateEncoding(c.Get(fiber.HeaderAcceptEncoding), m.encodings)
+	if encoding == "" {
+		return nil
+	}
+
+	level := m.levels[encoding]
+	var compressed []byte
+	switch encoding {
+	case EncodingBrotli:
+		compressed = fasthttp.AppendBrotliBytesLevel(nil, body, level)
+	case EncodingZstd:
+		compressed = fasthttp.AppendZstdBytesLevel(nil, body, level)
+	default:
+		compressed = fasthttp.AppendGzipBytesLevel(nil, body, level)
+	}
+	if len(compressed) >= len(body) {
+		return nil
+	}
+
+	res.SetBodyRaw(compressed)
+	res.Header.Set(fiber.HeaderContentEncoding, encoding)
+	res.Header.SetContentLength(len(compressed))
+	// The compressed bytes differ from the entity the strong ETag names.
+	if tag := string(res.Header.Peek(fiber.HeaderETag)); tag != "" && !strings.HasPrefix(tag, "W/") {
+		res.Header.Set(fiber.HeaderETag, "W/"+tag)
+	}
+	return nil
+}
+
+func (m *CompressionMiddleware) compressible(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	if mediaType == "" || mediaType == ContentTypeTextEventStream {
+		return false
+	}
+	for _, allowed := range m.config.ContentTypes {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == mediaType || (strings.HasSuffix(allowed, "/") && strings.HasPrefix(mediaType, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding picks the first of preferred that the Accept-Encoding
+// header allows with the highest quality.
+func negotiateEncoding(header string, preferred []string) string {
+	if strings.TrimSpace(header) == "" {
+		return ""
+	}
+	qualities := make(map[string]float64)
+	wildcard := -1.0
+	for part := range strings.SplitSeq(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+		if name == "*" {
+			wildcard = q
+			continue
+		}
+		qualities[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range preferred {
+		q, ok := qualities[encoding]
+		if !ok {
+			q = max(wildcard, 0)
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+func appendVary(c fiber.Ctx, name string) {
+	vary := string(c.Response().Header.Peek(fiber.HeaderVary))
+	if vary == "" {
+		c.Set(fiber.HeaderVary, name)
+		return
+	}
+	if headerHasToken(vary, "*") || headerHasToken(vary, name) {
+		return
+	}
+	c.Set(fiber.HeaderVary, vary+", "+name)
+}
+
+func headerHasToken(header, token string) bool {
+	for part := range strings.SplitSeq(header, ",") {
+		if strings.EqualFold(strings.TrimSpace(part), token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package web
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func newCachingTestApp(config Config, registry *MetadataRegistry) (*fiber.App, Router) {
+	app := fiber.New()
+	app.Use(NewCompressionMiddleware(config).Middleware)
+	app.Use(NewConditionalGetMiddleware(config).Middleware)
+	return app, NewRouterWithRegistry(app, registry)
+}
+
+func TestCompressionNegotiatesEncoding(t *testing.T) {
+	payload := strings.Repeat(`{"id":1,"name":"order"},`, 200)
+	app, r := newCachingTestApp(Config{Compression: CompressionConfig{Enabled: true}}, nil)
+	r.Get("/orders", func(c fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, ContentTypeApplicationJSON)
+		return c.SendString(payload)
+	})
+	r.Get("/small", func(c fiber.Ctx) error {
+		return c.JSON(map[string]int{"id": 1})
+	})
+	r.Get("/image", func(c fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, ContentTypeImagePNG)
+		return c.SendString(payload)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(fiber.HeaderAcceptEncoding, "gzip, br;q=0")
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	if got := res.Header.Get(fiber.HeaderContentEncoding); got != EncodingGzip {
+		t.Fatalf("content encoding: got=%q want gzip", got)
+	}
+	if got := res.Header.Get(fiber.HeaderVary); got != fiber.HeaderAcceptEncoding {
+		t.Fatalf("vary: got=%q", got)
+	}
+	if got := res.Header.Get(fiber.HeaderETag); !strings.HasPrefix(got, "W/") {
+		t.Fatalf("compressed response must carry a weak etag, got=%q", got)
+	}
+	zr, err := gzip.NewReader(res.Body)
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	if string(body) != payload {
+		t.Fatal("decompressed body differs from payload")
+	}
+
+	for _, path := range []string{"/small", "/image"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(fiber.HeaderAcceptEncoding, "gzip")
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		if got := res.Header.Get(fiber.HeaderContentEncoding); got != "" {
+			t.Fatalf("%s: unexpected content encoding %q", path, got)
+		}
+	}
+}
+
+func TestNegotiateEncodingHonoursQuality(t *testing.T) {
+	preferred := []string{EncodingBrotli, EncodingZstd, EncodingGzip}
+	cases := map[string]string{
+		"":                      "",
+		"identity":              "",
+		"gzip, deflate, br":     EncodingBrotli,
+		"br;q=0.5, gzip":        EncodingGzip,
+		"*":                     EncodingBrotli,
+		"*;q=0.1, zstd;q=0.9":   EncodingZstd,
+		"br;q=0, zstd;q=0, *":   EncodingGzip,
+		"gzip;q=0, *;q=0, br;q": EncodingBrotli,
+	}
+	for header, want := range cases {
+		if got := negotiateEncoding(header, preferred); got != want {
+			t.Fatalf("negotiateEncoding(%q): got=%q want=%q", header, got, want)
+		}
+	}
+}
+
+func TestConditionalGetAnswersNotModified(t *testing.T) {
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	app, r := newCachingTestApp(Config{ETag: ETagConfig{Enabled: true}}, nil)
+	r.Get("/orders", func(c fiber.Ctx) error {
+		return c.JSON([]string{"a", "b"})
+	})
+	r.Get("/report", func(c fiber.Ctx) error {
+		c.Set(fiber.HeaderETag, `"v7"`)
+		c.Set(fiber.HeaderLastModified, modified.Format(http.TimeFormat))
+		return c.SendString("report")
+	})
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/orders", nil))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	tag := res.Header.Get(fiber.HeaderETag)
+	if tag == "" {
+		t.Fatal("expected generated etag")
+	}
+
+	tests := []struct {
+		path   string
+		header string
+		value  string
+		want   int
+	}{
+		{"/orders", fiber.HeaderIfNoneMatch, tag, http.StatusNotModified},
+		{"/orders", fiber.HeaderIfNoneMatch, "W/" + tag, http.StatusNotModified},
+		{"/orders", fiber.HeaderIfNoneMatch, `"other"`, http.StatusOK},
+		{"/report", fiber.HeaderIfNoneMatch, `"v6", "v7"`, http.StatusNotModified},
+		{"/report", fiber.HeaderIfModifiedSince, modified.Format(http.TimeFormat), http.StatusNotModified},
+		{"/report", fiber.HeaderIfModifiedSince, modified.Add(-time.Hour).Format(http.TimeFormat), http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set(tt.header, tt.value)
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		if res.StatusCode != tt.want {
+			t.Fatalf("%s %s=%s: status=%d want=%d", tt.path, tt.header, tt.value, res.StatusCode, tt.want)
+		}
+		if tt.want == http.StatusNotModified {
+			body, _ := io.ReadAll(res.Body)
+			if len(body) != 0 {
+				t.Fatalf("304 response has body %q", body)
+			}
+		}
+	}
+}
+
+func TestRouteCacheSetsHeaderAndDocumentsIt(t *testing.T) {
+	registry := NewMetadataRegistry()
+	app, r := newCachingTestApp(Config{}, registry)
+	r.Get("/products/:id", func(c fiber.Ctx) error {
+		if c.Params("id") == "missing" {
+			return NewError(http.StatusNotFound, "PRODUCT_NOT_FOUND", "product not found")
+		}
+		return c.SendString("product")
+	}).Cache(CacheControl{Public: true, MaxAge: 5 * time.Minute, StaleWhileRevalidate: time.Minute})
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/products/1", nil))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	want := "public, max-age=300, stale-while-revalidate=60"
+	if got := res.Header.Get(fiber.HeaderCacheControl); got != want {
+		t.Fatalf("cache control: got=%q want=%q", got, want)
+	}
+	res, err = app.Test(httptest.NewRequest(http.MethodGet, "/products/missing", nil))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	if got := res.Header.Get(fiber.HeaderCacheControl); got != "" {
+		t.Fatalf("error response cached: %q", got)
+	}
+
+	meta := registry.GetRoute(http.MethodGet, "/products/:id")
+	if header, ok := meta.Responses[http.StatusOK].Headers[fiber.HeaderCacheControl]; !ok || header.Description != want {
+		t.Fatalf("cache control header not documented: %+v", meta.Responses[http.StatusOK].Headers)
+	}
+	if resp, ok := meta.Responses[http.StatusNotModified]; !ok || !resp.NoContent {
+		t.Fatal("304 response not documented")
+	}
+}
@@ -7,6 +7,25 @@ type Config struct {
 	Server ServerConfig `mapstructure:"server"`
 	Listen ListenConfig `mapstructure:"listen"`
 	TLS    TLSConfig    `mapstructure:"tls"`
+	// Listeners are served in addition to server.host:server.port.
+	Listeners []ListenerConfig `mapstructure:"listeners"`
+	Errors    ErrorConfig      `mapstructure:"errors"`
+	RequestID RequestIDConfig  `mapstructure:"request_id"`
+
+	CORS            CORSConfig            `mapstructure:"cors"`
+	SecurityHeaders SecurityHeadersConfig `mapstructure:"security_headers"`
+	CSRF            CSRFConfig            `mapstructure:"csrf"`
+
+	Compression CompressionConfig `mapstructure:"compression"`
+	ETag        ETagConfig        `mapstructure:"etag"`
+
+	Pagination PaginationConfig `mapstructure:"pagination"`
+}
+
+type PaginationConfig struct {
+	// CursorSecret signs pagination cursors. Set it when running more than
+	// one replica; otherwise a random per-process key is used.
+	CursorSecret string `mapstructure:"cursor_secret"`
 }
 
 type ServerConfig struct {
@@ -15,8 +34,11 @@ type ServerConfig struct {
 }
 
 type ListenConfig struct {
-	ListenerNetwork       string        `mapstructure:"listener_network" default:"tcp"`
-	ShutdownTimeout       time.Duration `mapstructure:"shutdown_timeout" default:"3s"`
+	ListenerNetwork string        `mapstructure:"listener_network" default:"tcp"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" default:"3s"`
+	// PreStopDelay keeps serving after readiness fails so load balancers can
+	// stop routing traffic before connections are refused.
+	PreStopDelay          time.Duration `mapstructure:"pre_stop_delay" default:"0s"`
 	DisableStartupMessage bool          `mapstructure:"disable_startup_message" default:"false"`
 	EnablePrefork         bool          `mapstructure:"enable_prefork" default:"false"`
 	EnablePrintRoutes     bool          `mapstructure:"enable_print_routes" default:"false"`
@@ -27,4 +49,28 @@ type TLSConfig struct {
 	CertKeyFile    string `mapstructure:"cert_key_file"`
 	CertClientFile string `mapstructure:"cert_client_file"`
 	TLSMinVersion  string `mapstructure:"tls_min_version" default:"1.2"`
+	// Certificates are extra pairs selected by SNI server name.
+	Certificates []TLSCertificateConfig `mapstructure:"certificates"`
+	// ClientAuth is none, request, require, verify_if_given or
+	// require_and_verify. Defaults to require_and_verify with cert_client_file.
+	ClientAuth string `mapstructure:"client_auth"`
+	// Reload reloads certificates when their files change or on SIGHUP.
+	Reload bool `mapstructure:"reload" default:"false"`
+}
+
+// ListenerConfig is an extra listener for the same server, such as a plain
+// HTTP port that redirects to HTTPS or a unix socket for a sidecar.
+type ListenerConfig struct {
+	// Network is tcp, tcp4, tcp6 or unix. Defaults to listen.listener_network.
+	Network string `mapstructure:"network"`
+	// Address is host:port, or the socket path for unix.
+	Address string `mapstructure:"address"`
+	// TLS serves the listener with the web.tls certificates.
+	TLS bool `mapstructure:"tls" default:"false"`
+	// RedirectHTTPS answers every request with a redirect to the https
+	// server.port instead of serving routes.
+	RedirectHTTPS bool `mapstructure:"redirect_https" default:"false"`
+	// RedirectPort is the port in redirect locations. Defaults to server.port;
+	// 443 is omitted.
+	RedirectPort int `mapstructure:"redirect_port"`
 }
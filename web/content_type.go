@@ -11,6 +11,8 @@ const (
 	ContentTypeApplicationZIP                 = "application/zip"
 	ContentTypeApplicationGZIP                = "application/gzip"
 	ContentTypeApplicationMsgpack             = "application/msgpack"
+	ContentTypeApplicationVndMsgpack          = "application/vnd.msgpack"
+	ContentTypeApplicationCBOR                = "application/cbor"
 	ContentTypeApplicationProtobuf            = "application/protobuf"
 	ContentTypeApplicationJavaScript          = "application/javascript"
 	ContentTypeApplicationLDJSON              = "application/ld+json"
@@ -0,0 +1,172 @@
+package web
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/cors"
+)
+
+type CORSConfig struct {
+	// Enabled applies the policy to every route. Route overrides set with
+	// web.CORS apply either way.
+	Enabled bool `mapstructure:"enabled" default:"false"`
+	// AllowOrigins lists allowed origins. Entries may be "*" or use a
+	// subdomain wildcard such as "https://*.example.com". Empty allows any origin.
+	AllowOrigins []string `mapstructure:"allow_origins"`
+	// AllowMethods defaults to GET, POST, HEAD, PUT, DELETE and PATCH.
+	AllowMethods     []string `mapstructure:"allow_methods"`
+	AllowHeaders     []string `mapstructure:"allow_headers"`
+	ExposeHeaders    []string `mapstructure:"expose_headers"`
+	AllowCredentials bool     `mapstructure:"allow_credentials" default:"false"`
+	// MaxAge is how long browsers may cache preflight responses.
+	MaxAge time.Duration `mapstructure:"max_age" default:"0s"`
+}
+
+// CORSMiddleware answers preflight requests and sets CORS response headers
+// using the web.cors policy or the override of the matched route.
+//
+// It runs right after OtelMiddleware so preflights are traced, and before
+// security headers and CSRF. Error recovery runs ahead of it, so a panic
+// while answering a preflight still gets an error response.
+type CORSMiddleware struct {
+	global   fiber.Handler
+	registry *CORSRegistry
+}
+
+func NewCORSMiddleware(config Config, registries *RegistryContainer) (*CORSMiddleware, error) {
+	m := &CORSMiddleware{registry: registries.CORS}
+	if config.CORS.Enabled {
+		handler, err := newCORSHandler(config.CORS)
+		if err != nil {
+			return nil, err
+		}
+		m.global = handler
+	}
+	return m, nil
+}
+
+func (m *CORSMiddleware) Handle(r Router) {
+	r.Use(m.Middleware)
+}
+
+func (m *CORSMiddleware) Middleware(c fiber.Ctx) error {
+	if c.Get(fiber.HeaderOrigin) == "" {
+		return c.Next()
+	}
+
+	method := c.Method()
+	if method == fiber.MethodOptions {
+		if requested := c.Get(fiber.HeaderAccessControlRequestMethod); requested != "" {
+			method = requested
+		}
+	}
+	if handler, ok := m.registry.Match(c.App().Config(), method, c.Path()); ok {
+		return handler(c)
+	}
+	if m.global != nil {
+		return m.global(c)
+	}
+	return c.Next()
+}
+
+// CORS returns a RouteOption that replaces the web.cors policy for the route.
+// Preflight requests for the route are answered with the same policy.
+//
+// Usage: r.Get("/public/feed", h.Feed).With(web.CORS(web.CORSConfig{AllowOrigins: []string{"*"}}))
+func CORS(config CORSConfig) RouteOption {
+	return func(b *RouteBuilder) *RouteBuilder {
+		return b.CORS(config)
+	}
+}
+
+// CORS replaces the web.cors policy for this route. It panics on an invalid
+// policy, like registering a route with an invalid path.
+func (b *RouteBuilder) CORS(config CORSConfig) *RouteBuilder {
+	handler, err := newCORSHandler(config)
+	if err != nil {
+		panic(err)
+	}
+	b.cors.Register(b.method, b.path, handler)
+	return b
+}
+
+// CORSRegistry keeps route CORS overrides. Unlike route metadata it is kept
+// for the whole app lifetime because it is consulted on every request.
+type CORSRegistry struct {
+	mu     sync.RWMutex
+	routes []corsRoute
+}
+
+type corsRoute struct {
+	method  string
+	pattern string
+	handler fiber.Handler
+}
+
+func NewCORSRegistry() *CORSRegistry {
+	return &CORSRegistry{}
+}
+
+// Register sets the CORS handler for method and the full route pattern.
+// Later registrations for the same route win.
+func (r *CORSRegistry) Register(method, pattern string, handler fiber.Handler) {
+	if r == nil || handler == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append([]corsRoute{{method: strings.ToUpper(method), pattern: pattern, handler: handler}}, r.routes...)
+}
+
+// Match returns the handler registered for the route that serves method and path.
+func (r *CORSRegistry) Match(app fiber.Config, method, path string) (fiber.Handler, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, route := range r.routes {
+		if route.method != method && !(route.method == fiber.MethodGet && method == fiber.MethodHead) {
+			continue
+		}
+		if fiber.RoutePatternMatch(path, route.pattern, app) {
+			return route.handler, true
+		}
+	}
+	return nil, false
+}
+
+func newCORSHandler(config CORSConfig) (handler fiber.Handler, err error) {
+	if config.AllowCredentials && allowsAnyOrigin(config.AllowOrigins) {
+		return nil, fmt.Errorf("web: cors allow_credentials requires explicit allow_origins")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			handler, err = nil, fmt.Errorf("web: invalid cors config: %v", r)
+		}
+	}()
+	return cors.New(cors.Config{
+		AllowOrigins:     config.AllowOrigins,
+		AllowMethods:     config.AllowMethods,
+		AllowHeaders:     config.AllowHeaders,
+		ExposeHeaders:    config.ExposeHeaders,
+		AllowCredentials: config.AllowCredentials,
+		MaxAge:           int(config.MaxAge / time.Second),
+	}), nil
+}
+
+func allowsAnyOrigin(origins []string) bool {
+	if len(origins) == 0 {
+		return true
+	}
+	for _, origin := range origins {
+		if strings.TrimSpace(origin) == "*" {
+			return true
+		}
+	}
+	return false
+}
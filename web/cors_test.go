@@ -0,0 +1,109 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func newCORSTestApp(t *testing.T, config Config) *fiber.App {
+	t.Helper()
+
+	registries := NewRegistryContainer()
+	mw, err := NewCORSMiddleware(config, registries)
+	if err != nil {
+		t.Fatalf("NewCORSMiddleware: %v", err)
+	}
+	handler := NewErrorHandler(config, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: handler.HandleError})
+	r := NewRouterWithRegistries(app, registries)
+	mw.Handle(r)
+
+	r.Get("/orders", func(c fiber.Ctx) error { return c.SendString("orders") })
+	api := r.Group("/public")
+	api.Get("/feed/:id", func(c fiber.Ctx) error {
+		return c.SendString("feed")
+	}).With(CORS(CORSConfig{AllowOrigins: []string{"*"}, AllowMethods: []string{http.MethodGet}}))
+	return app
+}
+
+func TestCORSMiddlewareAppliesGlobalPolicy(t *testing.T) {
+	app := newCORSTestApp(t, Config{CORS: CORSConfig{
+		Enabled:          true,
+		AllowOrigins:     []string{"https://app.example.com"},
+		AllowCredentials: true,
+	}})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://app.example.com")
+	res, _ := doErrorRequest(t, app, req)
+	if got := res.Header.Get(fiber.HeaderAccessControlAllowOrigin); got != "https://app.example.com" {
+		t.Fatalf("allow origin: got=%q", got)
+	}
+	if got := res.Header.Get(fiber.HeaderAccessControlAllowCredentials); got != "true" {
+		t.Fatalf("allow credentials: got=%q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://evil.example.com")
+	res, _ = doErrorRequest(t, app, req)
+	if got := res.Header.Get(fiber.HeaderAccessControlAllowOrigin); got != "" {
+		t.Fatalf("unexpected allow origin for foreign origin: %q", got)
+	}
+}
+
+func TestCORSRouteOverrideAnswersPreflight(t *testing.T) {
+	app := newCORSTestApp(t, Config{CORS: CORSConfig{
+		Enabled:      true,
+		AllowOrigins: []string{"https://app.example.com"},
+	}})
+
+	req := httptest.NewRequest(http.MethodOptions, "/public/feed/42", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://other.example.org")
+	req.Header.Set(fiber.HeaderAccessControlRequestMethod, http.MethodGet)
+	res, _ := doErrorRequest(t, app, req)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("preflight status: got=%d", res.StatusCode)
+	}
+	if got := res.Header.Get(fiber.HeaderAccessControlAllowOrigin); got != "*" {
+		t.Fatalf("preflight allow origin: got=%q", got)
+	}
+	if got := res.Header.Get(fiber.HeaderAccessControlAllowMethods); !strings.Contains(got, http.MethodGet) {
+		t.Fatalf("preflight allow methods: got=%q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/public/feed/42", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://other.example.org")
+	res, body := doErrorRequest(t, app, req)
+	if string(body) != "feed" || res.Header.Get(fiber.HeaderAccessControlAllowOrigin) != "*" {
+		t.Fatalf("override request: body=%q allow origin=%q", body, res.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+	}
+}
+
+func TestCORSRouteOverrideAppliesWhenGlobalDisabled(t *testing.T) {
+	app := newCORSTestApp(t, Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://app.example.com")
+	res, _ := doErrorRequest(t, app, req)
+	if got := res.Header.Get(fiber.HeaderAccessControlAllowOrigin); got != "" {
+		t.Fatalf("unexpected allow origin with cors disabled: %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/public/feed/1", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://app.example.com")
+	res, _ = doErrorRequest(t, app, req)
+	if got := res.Header.Get(fiber.HeaderAccessControlAllowOrigin); got != "*" {
+		t.Fatalf("override allow origin: got=%q", got)
+	}
+}
+
+func TestNewCORSMiddlewareRejectsCredentialsWithAnyOrigin(t *testing.T) {
+	_, err := NewCORSMiddleware(Config{CORS: CORSConfig{Enabled: true, AllowCredentials: true}}, NewRegistryContainer())
+	if err == nil {
+		t.Fatal("expected error for credentials with any origin")
+	}
+}
@@ -0,0 +1,182 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	DefaultCSRFCookieName = "csrf_token"
+	DefaultCSRFHeaderName = "X-CSRF-Token"
+
+	csrfLocalsKey           = "us.web.csrf_token"
+	csrfMiddlewareLocalsKey = "us.web.csrf"
+)
+
+// ErrCSRFTokenInvalid is returned when an unsafe request carrying a session
+// cookie has no matching CSRF token.
+var ErrCSRFTokenInvalid = NewError(http.StatusForbidden, "CSRF_TOKEN_INVALID", "missing or invalid CSRF token")
+
+// defaultCSRFSessionCookies match the cookies set by session.CookiePairDeliverer.
+var defaultCSRFSessionCookies = []string{"access_token", "refresh_token"}
+
+type CSRFConfig struct {
+	Enabled bool `mapstructure:"enabled" default:"false"`
+	// CookieName is the readable cookie holding the token. Defaults to csrf_token.
+	CookieName string `mapstructure:"cookie_name" default:"csrf_token"`
+	// HeaderName carries the token back on unsafe requests. Defaults to X-CSRF-Token.
+	HeaderName string `mapstructure:"header_name" default:"X-CSRF-Token"`
+	// FormField optionally accepts the token from a form field as well.
+	FormField      string        `mapstructure:"form_field"`
+	CookiePath     string        `mapstructure:"cookie_path" default:"/"`
+	CookieDomain   string        `mapstructure:"cookie_domain"`
+	CookieSecure   bool          `mapstructure:"cookie_secure" default:"false"`
+	CookieSameSite string        `mapstructure:"cookie_same_site" default:"Lax"`
+	CookieMaxAge   time.Duration `mapstructure:"cookie_max_age" default:"0s"`
+	// SessionCookies are the cookies that authenticate a request. Requests
+	// without any of them cannot be forged by a browser and are not checked.
+	SessionCookies []string `mapstructure:"session_cookies"`
+	// ExemptPaths are route patterns that skip the check, e.g. "/webhooks/*".
+	ExemptPaths []string `mapstructure:"exempt_paths"`
+}
+
+// CSRFMiddleware implements double-submit CSRF protection for cookie-based
+// sessions. It issues a token cookie and requires unsafe requests that carry
+// a session cookie to echo the token in a header or form field.
+type CSRFMiddleware struct {
+	config CSRFConfig
+}
+
+func NewCSRFMiddleware(config Config) *CSRFMiddleware {
+	cfg := config.CSRF
+	if strings.TrimSpace(cfg.CookieName) == "" {
+		cfg.CookieName = DefaultCSRFCookieName
+	}
+	if strings.TrimSpace(cfg.HeaderName) == "" {
+		cfg.HeaderName = DefaultCSRFHeaderName
+	}
+	if cfg.CookiePath == "" {
+		cfg.CookiePath = "/"
+	}
+	if cfg.CookieSameSite == "" {
+		cfg.CookieSameSite = fiber.CookieSameSiteLaxMode
+	}
+	if len(cfg.SessionCookies) == 0 {
+		cfg.SessionCookies = defaultCSRFSessionCookies
+	}
+	return &CSRFMiddleware{config: cfg}
+}
+
+func (m *CSRFMiddleware) Handle(r Router) {
+	if !m.config.Enabled {
+		return
+	}
+	r.Use(m.Middleware)
+}
+
+func (m *CSRFMiddleware) Middleware(c fiber.Ctx) error {
+	c.Locals(csrfMiddlewareLocalsKey, m)
+
+	token := c.Cookies(m.config.CookieName)
+	if token == "" {
+		token = m.issue(c)
+	} else {
+		c.Locals(csrfLocalsKey, token)
+	}
+
+	if isSafeMethod(c.Method()) || !m.hasSessionCookie(c) || m.exempt(c) {
+		return c.Next()
+	}
+
+	sent := c.Get(m.config.HeaderName)
+	if sent == "" && m.config.FormField != "" {
+		sent = c.FormValue(m.config.FormField)
+	}
+	if sent == "" || c.Cookies(m.config.CookieName) == "" ||
+		subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+		return ErrCSRFTokenInvalid
+	}
+	return c.Next()
+}
+
+func (m *CSRFMiddleware) issue(c fiber.Ctx) string {
+	token := newCSRFToken()
+	cookie := &fiber.Cookie{
+		Name:     m.config.CookieName,
+		Value:    token,
+		Path:     m.config.CookiePath,
+		Domain:   m.config.CookieDomain,
+		Secure:   m.config.CookieSecure,
+		SameSite: m.config.CookieSameSite,
+		// The client reads the cookie to echo it back.
+		HTTPOnly: false,
+	}
+	if m.config.CookieMaxAge > 0 {
+		cookie.MaxAge = int(m.config.CookieMaxAge / time.Second)
+	}
+	c.Cookie(cookie)
+	c.Locals(csrfLocalsKey, token)
+	return token
+}
+
+func (m *CSRFMiddleware) hasSessionCookie(c fiber.Ctx) bool {
+	for _, name := range m.config.SessionCookies {
+		if c.Cookies(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *CSRFMiddleware) exempt(c fiber.Ctx) bool {
+	if len(m.config.ExemptPaths) == 0 {
+		return false
+	}
+	app := c.App().Config()
+	for _, pattern := range m.config.ExemptPaths {
+		if fiber.RoutePatternMatch(c.Path(), pattern, app) {
+			return true
+		}
+	}
+	return false
+}
+
+// CSRFToken returns the CSRF token of the current request, e.g. to render it
+// into a form. It returns "" when CSRF protection is disabled.
+func CSRFToken(c fiber.Ctx) string {
+	if token, ok := c.Locals(csrfLocalsKey).(string); ok {
+		return token
+	}
+	return ""
+}
+
+// RotateCSRFToken issues a new CSRF token cookie and returns the token. Call
+// it whenever a session is established so a token planted before login cannot
+// be reused. It does nothing and returns "" when CSRF protection is disabled.
+func RotateCSRFToken(c fiber.Ctx) string {
+	m, ok := c.Locals(csrfMiddlewareLocalsKey).(*CSRFMiddleware)
+	if !ok {
+		return ""
+	}
+	return m.issue(c)
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func newCSRFToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
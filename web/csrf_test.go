@@ -0,0 +1,103 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func newCSRFTestApp(t *testing.T, config CSRFConfig) *fiber.App {
+	t.Helper()
+
+	config.Enabled = true
+	handler := NewErrorHandler(Config{}, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: handler.HandleError})
+	app.Use(NewCSRFMiddleware(Config{CSRF: config}).Middleware)
+	app.Get("/form", func(c fiber.Ctx) error { return c.SendString(CSRFToken(c)) })
+	app.Post("/orders", func(c fiber.Ctx) error { return c.SendStatus(http.StatusCreated) })
+	app.Post("/login", func(c fiber.Ctx) error { return c.SendString(RotateCSRFToken(c)) })
+	app.Post("/webhooks/stripe", func(c fiber.Ctx) error { return c.SendStatus(http.StatusNoContent) })
+	return app
+}
+
+func csrfCookie(t *testing.T, res *http.Response) string {
+	t.Helper()
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == DefaultCSRFCookieName {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+func TestCSRFMiddlewareIssuesToken(t *testing.T) {
+	app := newCSRFTestApp(t, CSRFConfig{})
+
+	res, body := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/form", nil))
+	token := csrfCookie(t, res)
+	if token == "" || token != string(body) {
+		t.Fatalf("token: cookie=%q body=%q", token, body)
+	}
+}
+
+func TestCSRFMiddlewareRequiresTokenWithSessionCookie(t *testing.T) {
+	app := newCSRFTestApp(t, CSRFConfig{ExemptPaths: []string{"/webhooks/*"}})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: "session"})
+	req.AddCookie(&http.Cookie{Name: DefaultCSRFCookieName, Value: "token-1"})
+	res, body := doErrorRequest(t, app, req)
+	if res.StatusCode != http.StatusForbidden || decodeErrorBody(t, body).Error.Code != "CSRF_TOKEN_INVALID" {
+		t.Fatalf("missing token: status=%d body=%s", res.StatusCode, body)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: "session"})
+	req.AddCookie(&http.Cookie{Name: DefaultCSRFCookieName, Value: "token-1"})
+	req.Header.Set(DefaultCSRFHeaderName, "token-2")
+	res, _ = doErrorRequest(t, app, req)
+	if res.StatusCode != http.StatusForbidden {
+		t.Fatalf("mismatched token: status=%d", res.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: "session"})
+	req.AddCookie(&http.Cookie{Name: DefaultCSRFCookieName, Value: "token-1"})
+	req.Header.Set(DefaultCSRFHeaderName, "token-1")
+	res, _ = doErrorRequest(t, app, req)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("matching token: status=%d", res.StatusCode)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/webhooks/stripe", nil)
+	req.AddCookie(&http.Cookie{Name: "access_token", Value: "session"})
+	res, _ = doErrorRequest(t, app, req)
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("exempt path: status=%d", res.StatusCode)
+	}
+}
+
+func TestCSRFMiddlewareSkipsRequestsWithoutSessionCookie(t *testing.T) {
+	app := newCSRFTestApp(t, CSRFConfig{})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer token")
+	res, _ := doErrorRequest(t, app, req)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("bearer request: status=%d", res.StatusCode)
+	}
+}
+
+func TestRotateCSRFTokenIssuesNewCookie(t *testing.T) {
+	app := newCSRFTestApp(t, CSRFConfig{})
+
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.AddCookie(&http.Cookie{Name: DefaultCSRFCookieName, Value: "planted"})
+	res, body := doErrorRequest(t, app, req)
+	token := csrfCookie(t, res)
+	if token == "" || token == "planted" || token != string(body) {
+		t.Fatalf("rotated token: cookie=%q body=%q", token, body)
+	}
+}
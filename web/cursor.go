@@ -0,0 +1,221 @@
+package web
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// cursorVersion is bumped when the cursor payload changes shape. Older
+// cursors are rejected so clients restart from the first page.
+const cursorVersion = 1
+
+var ErrInvalidCursor = NewError(http.StatusBadRequest, "INVALID_CURSOR", "invalid pagination cursor")
+
+type CursorDirection string
+
+const (
+	CursorNext CursorDirection = "next"
+	CursorPrev CursorDirection = "prev"
+)
+
+// Cursor is the decoded position of a keyset page: the sort key values of
+// the row it points at, and whether the client is paging forward or back.
+//
+// Keys keep their Go types across a round trip for strings, bools, integers
+// (as int64), floats (as float64) and time.Time.
+type Cursor struct {
+	Direction CursorDirection
+	Keys      []any
+}
+
+// IsZero reports whether the cursor points at the first page.
+func (c Cursor) IsZero() bool {
+	return len(c.Keys) == 0
+}
+
+// Backward reports whether rows must be read in reverse sort order.
+func (c Cursor) Backward() bool {
+	return c.Direction == CursorPrev && !c.IsZero()
+}
+
+type cursorPayload struct {
+	Version   int               `json:"v"`
+	Direction CursorDirection   `json:"d"`
+	Keys      []json.RawMessage `json:"k"`
+}
+
+type cursorTime struct {
+	Time time.Time `json:"t"`
+}
+
+// CursorCodec signs cursors so clients cannot forge positions.
+type CursorCodec struct {
+	secret []byte
+}
+
+// NewCursorCodec signs cursors with secret. An empty secret uses a random
+// key, so cursors do not survive restarts or work across replicas.
+func NewCursorCodec(secret string) *CursorCodec {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	return &CursorCodec{secret: key}
+}
+
+// Encode returns an opaque token for cursor.
+func (c *CursorCodec) Encode(cursor Cursor) (string, error) {
+	payload := cursorPayload{
+		Version:   cursorVersion,
+		Direction: cursor.Direction,
+		Keys:      make([]json.RawMessage, 0, len(cursor.Keys)),
+	}
+	if payload.Direction == "" {
+		payload.Direction = CursorNext
+	}
+	for _, key := range cursor.Keys {
+		if t, ok := key.(time.Time); ok {
+			key = cursorTime{Time: t}
+		}
+		raw, err := json.Marshal(key)
+		if err != nil {
+			return "", err
+		}
+		payload.Keys = append(payload.Keys, raw)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(data) + "." + enc.EncodeToString(c.sign(data)), nil
+}
+
+// Decode verifies token and returns its cursor. An empty token is the first
+// page. Tampered, foreign or outdated tokens return ErrInvalidCursor.
+func (c *CursorCodec) Decode(token string) (Cursor, error) {
+	if token == "" {
+		return Cursor{Direction: CursorNext}, nil
+	}
+	enc := base64.RawURLEncoding
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	data, err := enc.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	sig, err := enc.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, c.sign(data)) {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Version != cursorVersion {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if payload.Direction != CursorNext && payload.Direction != CursorPrev {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	cursor := Cursor{Direction: payload.Direction, Keys: make([]any, 0, len(payload.Keys))}
+	for _, raw := range payload.Keys {
+		key, err := decodeCursorKey(raw)
+		if err != nil {
+			return Cursor{}, ErrInvalidCursor
+		}
+		cursor.Keys = append(cursor.Keys, key)
+	}
+	return cursor, nil
+}
+
+func (c *CursorCodec) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func decodeCursorKey(raw json.RawMessage) (any, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+		var t cursorTime
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return nil, err
+		}
+		return t.Time, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if number, ok := value.(json.Number); ok {
+		if i, err := number.Int64(); err == nil {
+			return i, nil
+		}
+		return number.Float64()
+	}
+	return value, nil
+}
+
+// NewCursorPage builds a page from rows read with the cursor's keyset query.
+// Query limit+1 rows so the extra row tells whether another page exists;
+// keys returns the sort key values of an item, in keyset column order.
+//
+// Usage:
+//
+//	cursor, err := codec.Decode(q.Cursor)
+//	limit := q.Size(20, 100)
+//	rows := ... // WHERE/ORDER BY from the keyset helpers, LIMIT limit+1
+//	page, err := web.NewCursorPage(codec, cursor, rows, limit, func(o Order) []any {
+//		return []any{o.CreatedAt, o.ID}
+//	})
+func NewCursorPage[T any](codec *CursorCodec, cursor Cursor, rows []T, limit int, keys func(T) []any) (CursorPage[T], error) {
+	more := limit > 0 && len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	items := slices.Clone(rows)
+	if cursor.Backward() {
+		slices.Reverse(items)
+	}
+
+	page := CursorPage[T]{Data: items, Meta: CursorMeta{Limit: limit}}
+	if cursor.Backward() {
+		page.Meta.HasPrev = more
+		page.Meta.HasNext = true
+	} else {
+		page.Meta.HasNext = more
+		page.Meta.HasPrev = !cursor.IsZero()
+	}
+	if len(items) == 0 {
+		return page, nil
+	}
+
+	var err error
+	if page.Meta.HasNext {
+		page.Meta.NextCursor, err = codec.Encode(Cursor{Direction: CursorNext, Keys: keys(items[len(items)-1])})
+		if err != nil {
+			return CursorPage[T]{}, err
+		}
+	}
+	if page.Meta.HasPrev {
+		page.Meta.PrevCursor, err = codec.Encode(Cursor{Direction: CursorPrev, Keys: keys(items[0])})
+		if err != nil {
+			return CursorPage[T]{}, err
+		}
+	}
+	return page, nil
+}
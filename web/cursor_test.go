@@ -0,0 +1,108 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestCursorCodecRoundTripsTypedKeys(t *testing.T) {
+	codec := NewCursorCodec("secret")
+	createdAt := time.Date(2026, 3, 4, 5, 6, 7, 890, time.UTC)
+	token, err := codec.Encode(Cursor{Direction: CursorPrev, Keys: []any{createdAt, 42, "a", 1.5}})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+
+	cursor, err := codec.Decode(token)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := Cursor{Direction: CursorPrev, Keys: []any{createdAt, int64(42), "a", 1.5}}
+	if !reflect.DeepEqual(cursor, want) {
+		t.Fatalf("cursor: got=%#v want=%#v", cursor, want)
+	}
+	if !cursor.Backward() {
+		t.Fatal("prev cursor must read backward")
+	}
+
+	if first, err := codec.Decode(""); err != nil || !first.IsZero() || first.Backward() {
+		t.Fatalf("empty token: cursor=%+v err=%v", first, err)
+	}
+}
+
+func TestCursorCodecRejectsForgedTokens(t *testing.T) {
+	codec := NewCursorCodec("secret")
+	token, err := codec.Encode(Cursor{Keys: []any{1}})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	payload, signature, _ := strings.Cut(token, ".")
+
+	forged := []string{
+		"garbage",
+		payload + "." + signature[:len(signature)-2] + "AA",
+		payload[:len(payload)-2] + "." + signature,
+	}
+	if other, err := NewCursorCodec("other").Encode(Cursor{Keys: []any{1}}); err == nil {
+		forged = append(forged, other)
+	}
+	for _, token := range forged {
+		if _, err := codec.Decode(token); !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("token %q: err=%v want ErrInvalidCursor", token, err)
+		}
+	}
+}
+
+func TestNewCursorPageLinksNeighbours(t *testing.T) {
+	codec := NewCursorCodec("secret")
+	keys := func(id int) []any { return []any{id} }
+
+	// First page: limit+1 rows means there is a next page.
+	page, err := NewCursorPage(codec, Cursor{}, []int{1, 2, 3}, 2, keys)
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	if !reflect.DeepEqual(page.Data, []int{1, 2}) || !page.Meta.HasNext || page.Meta.HasPrev || page.Meta.PrevCursor != "" {
+		t.Fatalf("first page: %+v", page)
+	}
+	next, err := codec.Decode(page.Meta.NextCursor)
+	if err != nil || next.Direction != CursorNext || next.Keys[0] != int64(2) {
+		t.Fatalf("next cursor: %+v err=%v", next, err)
+	}
+
+	// Paging back from id 3 reads rows in reverse order: 2, 1 and no more.
+	page, err = NewCursorPage(codec, Cursor{Direction: CursorPrev, Keys: []any{int64(3)}}, []int{2, 1}, 2, keys)
+	if err != nil {
+		t.Fatalf("prev page: %v", err)
+	}
+	if !reflect.DeepEqual(page.Data, []int{1, 2}) || page.Meta.HasPrev || !page.Meta.HasNext {
+		t.Fatalf("prev page: %+v", page)
+	}
+}
+
+func TestRouteBuilderCursorPaginatedSetsMetadata(t *testing.T) {
+	registry := NewMetadataRegistry()
+	type order struct {
+		ID string `json:"id"`
+	}
+
+	router := NewRouterWithRegistry(fiber.New(), registry)
+	router.Get("/orders", func(c fiber.Ctx) error { return c.SendStatus(http.StatusOK) }).CursorPaginated(order{})
+
+	meta := registry.GetRoute(http.MethodGet, "/orders")
+	if meta == nil || meta.Pagination == nil || !meta.Pagination.Cursor {
+		t.Fatalf("expected cursor pagination metadata: %+v", meta)
+	}
+	if meta.Pagination.ItemType != reflect.TypeOf(order{}) {
+		t.Fatalf("unexpected item type: %v", meta.Pagination.ItemType)
+	}
+	if _, ok := meta.Responses[http.StatusBadRequest]; !ok {
+		t.Fatal("expected invalid cursor response")
+	}
+}
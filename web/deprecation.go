@@ -0,0 +1,89 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	"github.com/gofiber/fiber/v3"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+)
+
+// DeprecatedRequestsMetric counts requests served by deprecated routes.
+const DeprecatedRequestsMetric = "http.server.deprecated_requests"
+
+// Deprecated marks the route deprecated. See RouteBuilder.Deprecated.
+// Usage: .With(web.Deprecated(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), "https://docs.example.com/migrate"))
+func Deprecated(sunset time.Time, link string) RouteOption {
+	return func(b *RouteBuilder) *RouteBuilder {
+		return b.Deprecated(sunset, link)
+	}
+}
+
+// DeprecatedSince marks the route deprecated as of since. See
+// RouteBuilder.DeprecatedSince.
+// Usage: .With(web.DeprecatedSince(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), sunset, link))
+func DeprecatedSince(since, sunset time.Time, link string) RouteOption {
+	return func(b *RouteBuilder) *RouteBuilder {
+		return b.DeprecatedSince(since, sunset, link)
+	}
+}
+
+// Deprecated is DeprecatedSince without a deprecation date. RFC 9745 needs
+// one, so no Deprecation header is sent; use DeprecatedSince to send it.
+func (b *RouteBuilder) Deprecated(sunset time.Time, link string) *RouteBuilder {
+	return b.DeprecatedSince(time.Time{}, sunset, link)
+}
+
+// DeprecatedSince adds Deprecation (RFC 9745), Sunset and Link headers to
+// every response of the route, counts its calls in DeprecatedRequestsMetric
+// and marks the operation deprecated in OpenAPI. A zero since or sunset, or
+// an empty link, is omitted.
+func (b *RouteBuilder) DeprecatedSince(since, sunset time.Time, link string) *RouteBuilder {
+	b.metadata.Deprecation = &DeprecationMetadata{Since: since, Sunset: sunset, Link: link}
+
+	var deprecationValue string
+	if !since.IsZero() {
+		deprecationValue = "@" + strconv.FormatInt(since.Unix(), 10)
+	}
+	method, path := b.method, b.path
+	var sunsetValue string
+	if !sunset.IsZero() {
+		sunsetValue = sunset.UTC().Format(http.TimeFormat)
+	}
+	b.Middleware(func(c fiber.Ctx, next func() error) error {
+		if deprecationValue != "" {
+			c.Set(HeaderDeprecation, deprecationValue)
+		}
+		if sunsetValue != "" {
+			c.Set(HeaderSunset, sunsetValue)
+		}
+		if link != "" {
+			c.Append(fiber.HeaderLink, "<"+link+`>; rel="deprecation"`)
+		}
+		attrs := []attribute.KeyValue{
+			attribute.String("http.request.method", method),
+			attribute.String("http.route", path),
+		}
+		if version := APIVersion(c); version != "" {
+			attrs = append(attrs, attribute.String("api.version", version))
+		}
+		otel.From(c.Context()).AddCounter(c.Context(), DeprecatedRequestsMetric, 1, attrs...)
+		return next()
+	})
+
+	if deprecationValue != "" {
+		b.SetHeaders(fiber.StatusOK, HeaderDeprecation, "", "Date the operation was deprecated, as @<unix seconds>")
+	}
+	if sunsetValue != "" {
+		b.SetHeaders(fiber.StatusOK, HeaderSunset, "", "Date after which the operation may be removed")
+	}
+	b.finalize()
+	return b
+}
@@ -10,9 +10,8 @@ func IgnoreAutoGroupHandlers() di.Option {
 
 func Init() di.Node {
 	return di.Options(
-		di.AutoGroup[Handler](HandlersGroupName),
+		InitHandlers(),
 		di.Provide(NewServeCommand),
-		di.Invoke(SetupHandlers, Priority(Earlier)),
 		di.Invoke(func(s Server) error {
 			errCh := make(chan error, 1)
 			go func() {
@@ -29,6 +28,15 @@ func Init() di.Node {
 	)
 }
 
+// InitHandlers sets up the handlers on the router without listening, for
+// commands and tests that only need the routes.
+func InitHandlers() di.Node {
+	return di.Options(
+		di.AutoGroup[Handler](HandlersGroupName),
+		di.Invoke(SetupHandlers, Priority(Earlier)),
+	)
+}
+
 // UseServeCommand is no longer needed: Init registers the serve command.
 //
 // Deprecated: web.Init provides the serve command automatically.
@@ -0,0 +1,186 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const drainLocalsKey = "us.web.drain"
+
+// DefaultShutdownTimeout bounds the drain when listen.shutdown_timeout is unset.
+const DefaultShutdownTimeout = 3 * time.Second
+
+// ErrDraining is reported by the readiness check once the server is draining.
+var ErrDraining = errors.New("web: server is draining")
+
+// Drain tracks in-flight work so FiberServer.Stop can let it finish before
+// the process exits. Requests are tracked by its middleware; long-lived
+// connections that outlive their request, such as websockets and MQTT
+// sessions, call Track.
+//
+// Drain is also a readiness check, so /readyz fails as soon as draining
+// starts and load balancers stop routing new traffic.
+type Drain struct {
+	draining atomic.Bool
+	inFlight atomic.Int64
+	requests atomic.Int64
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+func NewDrain() *Drain {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Drain{ctx: ctx, cancel: cancel}
+}
+
+// Begin marks the server as draining and cancels Context. It is idempotent.
+func (d *Drain) Begin() {
+	d.once.Do(func() {
+		d.draining.Store(true)
+		d.cancel()
+	})
+}
+
+// Draining reports whether Begin was called.
+func (d *Drain) Draining() bool {
+	return d.draining.Load()
+}
+
+// Done is closed when draining begins.
+func (d *Drain) Done() <-chan struct{} {
+	return d.ctx.Done()
+}
+
+// Context is cancelled when draining begins.
+func (d *Drain) Context() context.Context {
+	return d.ctx
+}
+
+// Track counts a unit of in-flight work until the returned release is called.
+func (d *Drain) Track() (release func()) {
+	d.inFlight.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			d.inFlight.Add(-1)
+		})
+	}
+}
+
+// InFlight returns the number of requests and tracked connections in progress.
+func (d *Drain) InFlight() int64 {
+	return d.inFlight.Load()
+}
+
+// Requests returns the number of HTTP requests in progress.
+func (d *Drain) Requests() int64 {
+	return d.requests.Load()
+}
+
+// Wait blocks until no work is in flight or ctx is done.
+func (d *Drain) Wait(ctx context.Context) error {
+	if d.InFlight() == 0 {
+		return nil
+	}
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if d.InFlight() == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+func (d *Drain) Name() string {
+	return "drain"
+}
+
+func (d *Drain) Check(context.Context) error {
+	if d.Draining() {
+		return ErrDraining
+	}
+	return nil
+}
+
+// CacheTTL disables health result caching so readiness flips immediately.
+func (d *Drain) CacheTTL() time.Duration {
+	return -1
+}
+
+func (d *Drain) Handle(r Router) {
+	r.Use(d.Middleware)
+}
+
+// Middleware counts requests in flight and asks clients to drop keep-alive
+// connections once draining began.
+func (d *Drain) Middleware(c fiber.Ctx) error {
+	release := d.Track()
+	d.requests.Add(1)
+	defer func() {
+		d.requests.Add(-1)
+		release()
+	}()
+
+	state := &drainRequest{drain: d}
+	c.Locals(drainLocalsKey, state)
+	defer state.release()
+
+	err := c.Next()
+	if d.Draining() {
+		c.Set(fiber.HeaderConnection, "close")
+	}
+	return err
+}
+
+type drainRequest struct {
+	drain *Drain
+	ctx   context.Context
+	stop  []func()
+}
+
+func (r *drainRequest) release() {
+	for _, stop := range r.stop {
+		stop()
+	}
+}
+
+// DrainContext returns a context derived from the request context that is
+// also cancelled when the server starts draining. Long-running handlers use it
+// to wind down early; it is released when the request finishes.
+func DrainContext(c fiber.Ctx) context.Context {
+	state, ok := c.Locals(drainLocalsKey).(*drainRequest)
+	if !ok {
+		return c.Context()
+	}
+	if state.ctx != nil {
+		return state.ctx
+	}
+	ctx, cancel := context.WithCancel(c.Context())
+	stop := context.AfterFunc(state.drain.ctx, cancel)
+	state.ctx = ctx
+	state.stop = append(state.stop, func() {
+		stop()
+		cancel()
+	})
+	return ctx
+}
+
+// DrainFrom returns the Drain serving c, or nil when none is installed.
+func DrainFrom(c fiber.Ctx) *Drain {
+	if state, ok := c.Locals(drainLocalsKey).(*drainRequest); ok {
+		return state.drain
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func freeTCPPort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestFiberServerStopDrainsInFlightRequests(t *testing.T) {
+	port := freeTCPPort(t)
+	server := NewFiberServer(
+		Config{
+			Server: ServerConfig{Host: "127.0.0.1", Port: port},
+			Listen: ListenConfig{
+				DisableStartupMessage: true,
+				ShutdownTimeout:       2 * time.Second,
+				PreStopDelay:          50 * time.Millisecond,
+			},
+		},
+		FiberConfig{},
+	)
+	server.App.Use(server.Drain.Middleware)
+
+	entered := make(chan struct{})
+	server.App.Get("/stream", func(c fiber.Ctx) error {
+		close(entered)
+		<-DrainContext(c).Done()
+		return c.SendString("drained")
+	})
+
+	go func() { _ = server.Listen() }()
+	select {
+	case <-server.Wait():
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not start")
+	}
+
+	type result struct {
+		res  *http.Response
+		body string
+		err  error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		res, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/stream", port))
+		if err != nil {
+			resCh <- result{err: err}
+			return
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		resCh <- result{res: res, body: string(body), err: err}
+	}()
+	<-entered
+
+	if got := server.Drain.Requests(); got != 1 {
+		t.Fatalf("requests in flight: got=%d want=1", got)
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Stop(stopCtx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if !errors.Is(server.Drain.Check(context.Background()), ErrDraining) {
+		t.Fatal("readiness check should fail while draining")
+	}
+
+	got := <-resCh
+	if got.err != nil {
+		t.Fatalf("request: %v", got.err)
+	}
+	if got.body != "drained" {
+		t.Fatalf("body: got=%q", got.body)
+	}
+	if server.Drain.InFlight() != 0 {
+		t.Fatalf("in flight after stop: %d", server.Drain.InFlight())
+	}
+}
+
+func TestDrainWaitStopsAtDeadline(t *testing.T) {
+	drain := NewDrain()
+	release := drain.Track()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := drain.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wait: got=%v want deadline exceeded", err)
+	}
+
+	release()
+	release()
+	if err := drain.Wait(context.Background()); err != nil {
+		t.Fatalf("wait after release: %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Empty is a request or response type without fields. Handlers returning
+// Empty respond with 204 No Content.
+type Empty struct{}
+
+// bindSource selects where a typed endpoint reads its request from.
+type bindSource int
+
+const (
+	bindAll bindSource = iota
+	bindQuery
+	bindParams
+	bindHeaders
+)
+
+type fiberCtxKey struct{}
+
+// FiberCtx returns the fiber.Ctx of the request a typed handler is serving,
+// for handlers that need to set headers, cookies or a custom status.
+func FiberCtx(ctx context.Context) (fiber.Ctx, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	c, ok := ctx.Value(fiberCtxKey{}).(fiber.Ctx)
+	return c, ok && c != nil
+}
+
+// Handle adapts a typed handler into a RouteOption that serves the route and
+// documents it.
+//
+// The request is bound from path params (uri tag), body, query, headers and
+// cookies, validated with the app StructValidator, and the response is encoded
+// in the format negotiated from Accept among the documented content types,
+// JSON unless the route adds Formats. Fields read from params, query or headers should be tagged
+// json:"-" so they stay out of the documented body.
+//
+// Usage: r.Post("/orders").With(web.Handle(h.CreateOrder)).Summary("Create order")
+func Handle[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) RouteOption {
+	return endpoint(bindAll, fn)
+}
+
+// HandleQuery is Handle for requests bound only from the query string.
+//
+// Usage: r.Get("/orders").With(web.HandleQuery(h.ListOrders))
+func HandleQuery[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) RouteOption {
+	return endpoint(bindQuery, fn)
+}
+
+// HandleParams is Handle for requests bound only from path params (uri tag).
+//
+// Usage: r.Get("/orders/:id").With(web.HandleParams(h.GetOrder))
+func HandleParams[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) RouteOption {
+	return endpoint(bindParams, fn)
+}
+
+// HandleHeaders is Handle for requests bound only from headers (header tag).
+//
+// Usage: r.Get("/me").With(web.HandleHeaders(h.Me))
+func HandleHeaders[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error)) RouteOption {
+	return endpoint(bindHeaders, fn)
+}
+
+func endpoint[Req, Resp any](source bindSource, fn func(ctx context.Context, req Req) (Resp, error)) RouteOption {
+	return func(b *RouteBuilder) *RouteBuilder {
+		reqType := reflect.TypeFor[Req]()
+		respType := reflect.TypeFor[Resp]()
+		status := endpointStatus(b.method, respType)
+
+		b.setEndpoint(func(c fiber.Ctx) error {
+			var req Req
+			target := any(&req)
+			if reqType.Kind() == reflect.Ptr {
+				req = reflect.New(reqType.Elem()).Interface().(Req)
+				target = req
+			}
+			if err := bindEndpointRequest(c, source, reqType, target); err != nil {
+				return err
+			}
+
+			c.Status(status)
+			ctx := context.WithValue(c.Context(), fiberCtxKey{}, c)
+			resp, err := fn(ctx, req)
+			if err != nil {
+				return err
+			}
+			return writeEndpointResponse(c, respType, resp, b.metadata)
+		})
+		b.describeEndpoint(source, reqType, respType, status)
+		return b
+	}
+}
+
+func bindEndpointRequest(c fiber.Ctx, source bindSource, reqType reflect.Type, out any) error {
+	if !hasBindableFields(reqType) {
+		return nil
+	}
+	bind := c.Bind()
+	var err error
+	switch source {
+	case bindQuery:
+		err = bind.Query(out)
+	case bindParams:
+		err = bind.URI(out)
+	case bindHeaders:
+		err = bind.Header(out)
+	default:
+		err = bind.All(out)
+	}
+	return err
+}
+
+func writeEndpointResponse(c fiber.Ctx, respType reflect.Type, resp any, meta *RouteMetadata) error {
+	if c.Response().StatusCode() == http.StatusNoContent || isEmptyType(respType) {
+		return c.SendStatus(http.StatusNoContent)
+	}
+	switch v := resp.(type) {
+	case string:
+		c.Type("txt")
+		return c.SendString(v)
+	case []byte:
+		c.Set(fiber.HeaderContentType, ContentTypeApplicationOctetStream)
+		return c.Send(v)
+	}
+	return respond(c, c.Response().StatusCode(), resp, meta)
+}
+
+func endpointStatus(method string, respType reflect.Type) int {
+	if isEmptyType(respType) {
+		return http.StatusNoContent
+	}
+	if method == http.MethodPost {
+		return http.StatusCreated
+	}
+	return http.StatusOK
+}
+
+// describeEndpoint registers request and response metadata derived from the
+// typed handler signature.
+func (b *RouteBuilder) describeEndpoint(source bindSource, reqType, respType reflect.Type, status int) {
+	reqStruct := derefType(reqType)
+	if reqStruct.Kind() == reflect.Struct {
+		switch source {
+		case bindQuery:
+			b.metadata.QueryType = reqType
+		case bindParams:
+			// Path params are documented from the route path.
+		case bindHeaders:
+			b.addStructParameters(reqStruct, "header")
+		default:
+			b.addStructParameters(reqStruct, "query")
+			b.addStructParameters(reqStruct, "header")
+			b.addStructParameters(reqStruct, "cookie")
+			if methodAcceptsBody(b.method) && hasBodyFields(reqStruct) {
+				b.setRequestBody(reflect.New(reqType).Elem().Interface(), true, false, ContentTypeApplicationJSON)
+			}
+		}
+	}
+
+	b.ensureResponseMaps()
+	if status == http.StatusNoContent {
+		resp := b.metadata.Responses[status]
+		resp.NoContent = true
+		b.metadata.Responses[status] = resp
+	} else {
+		b.setResponse(status, reflect.New(respType).Elem().Interface(), "", "", false)
+	}
+	if hasBindableFields(reqType) {
+		b.addErrorResponseIfMissing(http.StatusBadRequest, "Invalid request")
+		if hasValidateTags(reqStruct) {
+			b.addErrorResponseIfMissing(http.StatusUnprocessableEntity, "Validation failed")
+		}
+	}
+	b.addErrorResponseIfMissing(http.StatusInternalServerError, "Internal server error")
+	b.finalize()
+}
+
+// addStructParameters documents fields tagged with in (query, header, cookie)
+// as individual parameters.
+func (b *RouteBuilder) addStructParameters(t reflect.Type, in string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := tagName(field.Tag.Get(in))
+		if name == "" {
+			continue
+		}
+		b.addParameterMetadata(
+			in,
+			name,
+			reflect.New(field.Type).Elem().Interface(),
+			hasValidateRule(field, "required"),
+			field.Tag.Get("description"),
+			field.Tag.Get("extensions"),
+		)
+	}
+}
+
+func hasBindableFields(t reflect.Type) bool {
+	t = derefType(t)
+	return t.Kind() == reflect.Struct && t.NumField() > 0
+}
+
+func hasBodyFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+		if tagName(field.Tag.Get("uri")) != "" || tagName(field.Tag.Get("query")) != "" ||
+			tagName(field.Tag.Get("header")) != "" || tagName(field.Tag.Get("cookie")) != "" {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func hasValidateTags(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get("validate"); tag != "" && tag != "-" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasValidateRule(field reflect.StructField, rule string) bool {
+	for _, part := range strings.Split(field.Tag.Get("validate"), ",") {
+		if strings.TrimSpace(part) == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyType(t reflect.Type) bool {
+	return t == reflect.TypeFor[Empty]()
+}
+
+func methodAcceptsBody(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, "ALL":
+		return true
+	}
+	return false
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+func tagName(tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	name = strings.TrimSpace(name)
+	if name == "-" {
+		return ""
+	}
+	return name
+}
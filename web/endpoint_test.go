@@ -0,0 +1,178 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type endpointCreateOrder struct {
+	StoreID string `uri:"store_id" json:"-"`
+	DryRun  bool   `query:"dry_run" json:"-"`
+	Tenant  string `header:"X-Tenant-ID" json:"-" validate:"required"`
+	Item    string `json:"item" validate:"required"`
+	Count   int    `json:"count" validate:"gte=1"`
+}
+
+type endpointOrder struct {
+	StoreID string `json:"store_id"`
+	Tenant  string `json:"tenant"`
+	Item    string `json:"item"`
+	Count   int    `json:"count"`
+	DryRun  bool   `json:"dry_run"`
+}
+
+type endpointListOrders struct {
+	Status string `query:"status"`
+	Limit  int    `query:"limit" validate:"omitempty,lte=100"`
+}
+
+func newEndpointTestApp(t *testing.T) (*fiber.App, Router, *MetadataRegistry) {
+	t.Helper()
+
+	registry := NewMetadataRegistry()
+	app := fiber.New(fiber.Config{
+		StructValidator: NewFiberValidator(),
+		ErrorHandler:    NewErrorHandler(Config{}, nil).HandleError,
+	})
+	return app, NewRouterWithRegistry(app, registry), registry
+}
+
+func TestHandleBindsValidatesAndEncodes(t *testing.T) {
+	app, router, _ := newEndpointTestApp(t)
+	router.Post("/stores/:store_id/orders").With(Handle(func(ctx context.Context, req endpointCreateOrder) (endpointOrder, error) {
+		if _, ok := FiberCtx(ctx); !ok {
+			return endpointOrder{}, errors.New("missing fiber ctx")
+		}
+		return endpointOrder{StoreID: req.StoreID, Tenant: req.Tenant, Item: req.Item, Count: req.Count, DryRun: req.DryRun}, nil
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/stores/s1/orders?dry_run=true", strings.NewReader(`{"item":"tea","count":2}`))
+	req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+	req.Header.Set("X-Tenant-ID", "acme")
+	res, body := doErrorRequest(t, app, req)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("status: got=%d want=%d body=%s", res.StatusCode, http.StatusCreated, body)
+	}
+
+	var got endpointOrder
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := endpointOrder{StoreID: "s1", Tenant: "acme", Item: "tea", Count: 2, DryRun: true}
+	if got != want {
+		t.Fatalf("response: got=%+v want=%+v", got, want)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/stores/s1/orders", strings.NewReader(`{"count":0}`))
+	req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+	res, body = doErrorRequest(t, app, req)
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("status: got=%d want=%d body=%s", res.StatusCode, http.StatusUnprocessableEntity, body)
+	}
+	if got := decodeErrorBody(t, body); len(got.Error.Details) != 3 {
+		t.Fatalf("expected 3 validation details, got %v", got.Error.Details)
+	}
+}
+
+func TestHandleRegistersRouteMetadata(t *testing.T) {
+	_, router, registry := newEndpointTestApp(t)
+	router.Post("/stores/:store_id/orders").With(Handle(func(ctx context.Context, req endpointCreateOrder) (endpointOrder, error) {
+		return endpointOrder{}, nil
+	})).Summary("Create order")
+
+	meta := registry.GetRoute(http.MethodPost, "/stores/:store_id/orders")
+	if meta == nil {
+		t.Fatal("expected route metadata")
+	}
+	if meta.Summary != "Create order" {
+		t.Fatalf("summary: got=%q", meta.Summary)
+	}
+	if meta.RequestBody == nil || meta.RequestBody.Type != reflect.TypeOf(endpointCreateOrder{}) || !meta.RequestBody.Required {
+		t.Fatalf("unexpected request body metadata: %+v", meta.RequestBody)
+	}
+	if resp := meta.Responses[http.StatusCreated]; resp.Type != reflect.TypeOf(endpointOrder{}) {
+		t.Fatalf("unexpected 201 response: %+v", resp)
+	}
+	for _, code := range []int{400, 422, 500} {
+		if resp, ok := meta.Responses[code]; !ok || resp.Type != reflect.TypeOf(Error{}) {
+			t.Fatalf("expected %d error response, got %+v", code, resp)
+		}
+	}
+
+	params := map[string]ParameterMetadata{}
+	for _, p := range meta.Parameters {
+		params[p.In+":"+p.Name] = p
+	}
+	if p, ok := params["header:X-Tenant-ID"]; !ok || !p.Required {
+		t.Fatalf("expected required tenant header, got %+v", meta.Parameters)
+	}
+	if p, ok := params["query:dry_run"]; !ok || p.Type != reflect.TypeOf(false) {
+		t.Fatalf("expected dry_run query param, got %+v", meta.Parameters)
+	}
+}
+
+func TestHandleQueryDocumentsQueryType(t *testing.T) {
+	app, router, registry := newEndpointTestApp(t)
+	router.Get("/orders").With(HandleQuery(func(ctx context.Context, req endpointListOrders) ([]endpointOrder, error) {
+		return []endpointOrder{{Item: req.Status}}, nil
+	}))
+
+	res, body := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/orders?status=open", nil))
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status: got=%d want=%d body=%s", res.StatusCode, http.StatusOK, body)
+	}
+	if !strings.Contains(string(body), `"item":"open"`) {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	res, _ = doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/orders?limit=500", nil))
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusUnprocessableEntity)
+	}
+
+	meta := registry.GetRoute(http.MethodGet, "/orders")
+	if meta.QueryType != reflect.TypeOf(endpointListOrders{}) {
+		t.Fatalf("query type: got=%v", meta.QueryType)
+	}
+	if meta.RequestBody != nil {
+		t.Fatalf("expected no request body, got %+v", meta.RequestBody)
+	}
+	if resp := meta.Responses[http.StatusOK]; resp.Type != reflect.TypeOf([]endpointOrder{}) {
+		t.Fatalf("unexpected 200 response: %+v", resp)
+	}
+}
+
+func TestHandleEmptyResponseAfterRouteHandlers(t *testing.T) {
+	type deleteOrder struct {
+		ID string `uri:"id"`
+	}
+
+	app, router, registry := newEndpointTestApp(t)
+	deleted := ""
+	router.Delete("/orders/:id", func(c fiber.Ctx) error {
+		c.Set("X-Checked", "1")
+		return c.Next()
+	}).With(HandleParams(func(ctx context.Context, req deleteOrder) (Empty, error) {
+		deleted = req.ID
+		return Empty{}, nil
+	}))
+
+	res, _ := doErrorRequest(t, app, httptest.NewRequest(http.MethodDelete, "/orders/42", nil))
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusNoContent)
+	}
+	if deleted != "42" || res.Header.Get("X-Checked") != "1" {
+		t.Fatalf("deleted=%q checked=%q", deleted, res.Header.Get("X-Checked"))
+	}
+	if resp := registry.GetRoute(http.MethodDelete, "/orders/:id").Responses[http.StatusNoContent]; !resp.NoContent {
+		t.Fatalf("expected 204 no content response, got %+v", resp)
+	}
+}
@@ -1,5 +1,10 @@
 package web
 
+import (
+	"net/http"
+	"strings"
+)
+
 type ErrorDetail struct {
 	Code    string   `json:"code"`
 	Message string   `json:"message"`
@@ -10,3 +15,92 @@ type Error struct {
 	Error     ErrorDetail `json:"error"`
 	RequestID string      `json:"request_id"`
 }
+
+// Problem is the RFC 7807 application/problem+json rendering of an error.
+type Problem struct {
+	Type      string   `json:"type"`
+	Title     string   `json:"title"`
+	Status    int      `json:"status"`
+	Detail    string   `json:"detail,omitempty"`
+	Instance  string   `json:"instance,omitempty"`
+	Code      string   `json:"code"`
+	Details   []string `json:"details,omitempty"`
+	RequestID string   `json:"request_id,omitempty"`
+}
+
+// HTTPError is an application error that carries the HTTP status, code and
+// details the error handler renders it with.
+//
+// Usage: return web.NewError(404, "ORDER_NOT_FOUND", "order not found").Wrap(err)
+type HTTPError struct {
+	Status  int
+	Code    string
+	Message string
+	Details []string
+	Err     error
+}
+
+// NewError creates an HTTPError. An empty code is derived from the status text.
+func NewError(status int, code string, message string, details ...string) *HTTPError {
+	if status <= 0 {
+		status = http.StatusInternalServerError
+	}
+	code = strings.TrimSpace(code)
+	if code == "" {
+		code = ErrorCodeFromStatus(status)
+	}
+	return &HTTPError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+		Details: append([]string(nil), details...),
+	}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return http.StatusText(e.Status)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an HTTPError with the same status and code, so
+// sentinel errors keep matching after WithDetails or Wrap.
+func (e *HTTPError) Is(target error) bool {
+	t, ok := target.(*HTTPError)
+	if !ok || t == nil {
+		return false
+	}
+	return e.Status == t.Status && e.Code == t.Code
+}
+
+// WithDetails returns a copy of the error with details appended.
+func (e *HTTPError) WithDetails(details ...string) *HTTPError {
+	out := *e
+	out.Details = append(append([]string(nil), e.Details...), details...)
+	return &out
+}
+
+// Wrap returns a copy of the error that records err as its cause.
+func (e *HTTPError) Wrap(err error) *HTTPError {
+	out := *e
+	out.Err = err
+	return &out
+}
+
+// ErrorCodeFromStatus converts a status code into an error code, e.g. 404 -> NOT_FOUND.
+func ErrorCodeFromStatus(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "ERROR"
+	}
+	text = strings.NewReplacer("-", " ", "'", "").Replace(text)
+	return strings.ToUpper(strings.Join(strings.Fields(text), "_"))
+}
@@ -0,0 +1,161 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	recoverer "github.com/gofiber/fiber/v3/middleware/recover"
+	"go.uber.org/zap"
+)
+
+type ErrorConfig struct {
+	// ProblemDetails renders every error as RFC 7807 application/problem+json.
+	// Clients asking for application/problem+json get it regardless.
+	ProblemDetails bool `mapstructure:"problem_details" default:"false"`
+	// ProblemTypeBaseURL prefixes the error code to build the problem "type" URI.
+	// When empty, "about:blank" is used.
+	ProblemTypeBaseURL string `mapstructure:"problem_type_base_url"`
+	// ExposeInternalErrors keeps the original message of 5xx errors in responses.
+	ExposeInternalErrors bool `mapstructure:"expose_internal_errors" default:"false"`
+	// DisableRecover skips the panic recovery middleware.
+	DisableRecover bool `mapstructure:"disable_recover" default:"false"`
+}
+
+// ErrorHandler renders every error returned by a handler as web.Error (or
+// problem+json) and recovers panics into 500 responses.
+//
+// It is installed as fiber.Config.ErrorHandler and registers the recover
+// middleware as a web.Handler.
+type ErrorHandler struct {
+	otel.Telemetry
+
+	config   ErrorConfig
+	registry *ErrorRegistry
+}
+
+func NewErrorHandler(config Config, registry *ErrorRegistry) *ErrorHandler {
+	if registry == nil {
+		registry = NewErrorRegistry()
+	}
+	return &ErrorHandler{
+		Telemetry: otel.Nop(),
+		config:    config.Errors,
+		registry:  registry,
+	}
+}
+
+func (h *ErrorHandler) MutateFiberConfig(cfg *fiber.Config) {
+	cfg.ErrorHandler = h.HandleError
+}
+
+func (h *ErrorHandler) Handle(r Router) {
+	if h.config.DisableRecover {
+		return
+	}
+	r.Use(h.Recover())
+}
+
+// Registry returns the registry used to resolve errors.
+func (h *ErrorHandler) Registry() *ErrorRegistry {
+	return h.registry
+}
+
+// Recover returns middleware that turns panics into errors for HandleError.
+func (h *ErrorHandler) Recover() fiber.Handler {
+	return recoverer.New(recoverer.Config{
+		EnableStackTrace: true,
+		StackTraceHandler: func(c fiber.Ctx, e any) {
+			h.Obs.Error("panic recovered",
+				zap.Any("panic", e),
+				zap.String("http.request.method", c.Method()),
+				zap.String("url.path", c.Path()),
+				zap.ByteString("stack", debug.Stack()),
+			)
+		},
+	})
+}
+
+// HandleError is a fiber.ErrorHandler.
+func (h *ErrorHandler) HandleError(c fiber.Ctx, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	resolved := h.registry.Resolve(err)
+	if resolved.Status >= http.StatusInternalServerError {
+		h.Obs.Error("request failed",
+			zap.Error(err),
+			zap.Int("http.response.status_code", resolved.Status),
+			zap.String("http.request.method", c.Method()),
+			zap.String("url.path", c.Path()),
+		)
+		if !h.config.ExposeInternalErrors {
+			resolved = NewError(resolved.Status, resolved.Code, http.StatusText(resolved.Status)).Wrap(err)
+		}
+	}
+
+	return h.render(c, localize(c, resolved))
+}
+
+// localize translates the message of err by code, and its validation
+// details, when the request has a Translator.
+func localize(c fiber.Ctx, err *HTTPError) *HTTPError {
+	translator, ok := TranslatorFromLocals(c)
+	if !ok {
+		return err
+	}
+	out := *err
+	out.Message = LocalizeErrorMessage(c, err.Code, err.Error())
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		out.Details = TranslateValidationErrors(c.Context(), translator, validationErrs)
+	}
+	return &out
+}
+
+func (h *ErrorHandler) render(c fiber.Ctx, err *HTTPError) error {
+	c.Status(err.Status)
+
+	if h.config.ProblemDetails || acceptsProblemJSON(c) {
+		return c.JSON(h.problem(c, err), ContentTypeApplicationProblemJSON)
+	}
+
+	details := err.Details
+	if details == nil {
+		details = []string{}
+	}
+	return c.JSON(Error{
+		Error: ErrorDetail{
+			Code:    err.Code,
+			Message: err.Error(),
+			Details: details,
+		},
+		RequestID: RequestID(c),
+	})
+}
+
+func (h *ErrorHandler) problem(c fiber.Ctx, err *HTTPError) Problem {
+	problemType := "about:blank"
+	if base := strings.TrimSpace(h.config.ProblemTypeBaseURL); base != "" {
+		problemType = strings.TrimSuffix(base, "/") + "/" + strings.ToLower(strings.ReplaceAll(err.Code, "_", "-"))
+	}
+	return Problem{
+		Type:      problemType,
+		Title:     http.StatusText(err.Status),
+		Status:    err.Status,
+		Detail:    err.Error(),
+		Instance:  c.OriginalURL(),
+		Code:      err.Code,
+		Details:   err.Details,
+		RequestID: RequestID(c),
+	}
+}
+
+func acceptsProblemJSON(c fiber.Ctx) bool {
+	return strings.Contains(c.Get(fiber.HeaderAccept), ContentTypeApplicationProblemJSON)
+}
@@ -0,0 +1,218 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+var errOrderClosed = errors.New("order closed")
+
+func newErrorHandlerTestApp(t *testing.T, config Config, mappers ...ErrorMapper) *fiber.App {
+	t.Helper()
+
+	handler := NewErrorHandler(config, NewErrorRegistry(mappers...))
+	app := fiber.New(fiber.Config{
+		StructValidator: NewFiberValidator(),
+		ErrorHandler:    handler.HandleError,
+	})
+	app.Use(handler.Recover())
+	return app
+}
+
+func doErrorRequest(t *testing.T, app *fiber.App, req *http.Request) (*http.Response, []byte) {
+	t.Helper()
+
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return res, body
+}
+
+func decodeErrorBody(t *testing.T, body []byte) Error {
+	t.Helper()
+
+	var out Error
+	if err := json.Unmarshal(body, &out); err != nil {
+		t.Fatalf("decode error body %q: %v", body, err)
+	}
+	return out
+}
+
+func TestErrorHandlerRendersHTTPError(t *testing.T) {
+	app := newErrorHandlerTestApp(t, Config{})
+	app.Get("/orders/:id", func(c fiber.Ctx) error {
+		return NewError(http.StatusNotFound, "ORDER_NOT_FOUND", "order not found", "id=42")
+	})
+
+	res, body := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/orders/42", nil))
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusNotFound)
+	}
+	got := decodeErrorBody(t, body)
+	if got.Error.Code != "ORDER_NOT_FOUND" || got.Error.Message != "order not found" {
+		t.Fatalf("unexpected error body: %+v", got)
+	}
+	if len(got.Error.Details) != 1 || got.Error.Details[0] != "id=42" {
+		t.Fatalf("unexpected details: %+v", got.Error.Details)
+	}
+}
+
+func TestErrorHandlerRendersFiberErrorAndUnknownRoute(t *testing.T) {
+	app := newErrorHandlerTestApp(t, Config{})
+	app.Get("/teapot", func(c fiber.Ctx) error {
+		return fiber.NewError(http.StatusTeapot, "short and stout")
+	})
+
+	res, body := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/teapot", nil))
+	if res.StatusCode != http.StatusTeapot {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusTeapot)
+	}
+	if got := decodeErrorBody(t, body); got.Error.Code != "IM_A_TEAPOT" || got.Error.Message != "short and stout" {
+		t.Fatalf("unexpected error body: %+v", got)
+	}
+
+	res, body = doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusNotFound)
+	}
+	if got := decodeErrorBody(t, body); got.Error.Code != "NOT_FOUND" {
+		t.Fatalf("unexpected error body: %+v", got)
+	}
+}
+
+func TestErrorHandlerExpandsValidationErrors(t *testing.T) {
+	type createOrder struct {
+		Name  string `json:"name" validate:"required"`
+		Count int    `json:"count" validate:"gte=1"`
+	}
+
+	app := newErrorHandlerTestApp(t, Config{})
+	app.Post("/orders", func(c fiber.Ctx) error {
+		var req createOrder
+		if err := c.Bind().Body(&req); err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"count":0}`))
+	req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+	res, body := doErrorRequest(t, app, req)
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusUnprocessableEntity)
+	}
+	got := decodeErrorBody(t, body)
+	if got.Error.Code != "VALIDATION_FAILED" {
+		t.Fatalf("code: got=%q want=%q", got.Error.Code, "VALIDATION_FAILED")
+	}
+	want := []string{"Name: failed on 'required'", "Count: failed on 'gte=1'"}
+	if strings.Join(got.Error.Details, "|") != strings.Join(want, "|") {
+		t.Fatalf("details: got=%v want=%v", got.Error.Details, want)
+	}
+}
+
+func TestErrorHandlerUsesRegisteredMappings(t *testing.T) {
+	app := newErrorHandlerTestApp(t, Config{}, ErrorMappings{
+		{Target: errOrderClosed, Status: http.StatusConflict, Code: "ORDER_CLOSED"},
+	})
+	app.Post("/orders/:id/cancel", func(c fiber.Ctx) error {
+		return errors.Join(errors.New("cancel order"), errOrderClosed)
+	})
+
+	res, body := doErrorRequest(t, app, httptest.NewRequest(http.MethodPost, "/orders/1/cancel", nil))
+	if res.StatusCode != http.StatusConflict {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusConflict)
+	}
+	if got := decodeErrorBody(t, body); got.Error.Code != "ORDER_CLOSED" {
+		t.Fatalf("unexpected error body: %+v", got)
+	}
+}
+
+func TestErrorHandlerHidesInternalErrorsAndRecoversPanics(t *testing.T) {
+	app := newErrorHandlerTestApp(t, Config{})
+	app.Get("/fail", func(c fiber.Ctx) error {
+		return errors.New("dial tcp 10.0.0.1:5432: connection refused")
+	})
+	app.Get("/panic", func(c fiber.Ctx) error {
+		panic("boom")
+	})
+
+	for _, path := range []string{"/fail", "/panic"} {
+		res, body := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, path, nil))
+		if res.StatusCode != http.StatusInternalServerError {
+			t.Fatalf("%s status: got=%d want=%d", path, res.StatusCode, http.StatusInternalServerError)
+		}
+		got := decodeErrorBody(t, body)
+		if got.Error.Code != "INTERNAL_SERVER_ERROR" || got.Error.Message != "Internal Server Error" {
+			t.Fatalf("%s unexpected error body: %+v", path, got)
+		}
+	}
+}
+
+func TestErrorHandlerRendersProblemJSON(t *testing.T) {
+	app := newErrorHandlerTestApp(t, Config{})
+	app.Get("/orders/:id", func(c fiber.Ctx) error {
+		return NewError(http.StatusNotFound, "ORDER_NOT_FOUND", "order not found")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/7", nil)
+	req.Header.Set("Accept", ContentTypeApplicationProblemJSON)
+	res, body := doErrorRequest(t, app, req)
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, ContentTypeApplicationProblemJSON) {
+		t.Fatalf("content type: got=%q want prefix %q", ct, ContentTypeApplicationProblemJSON)
+	}
+
+	var problem Problem
+	if err := json.Unmarshal(body, &problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if problem.Status != http.StatusNotFound || problem.Code != "ORDER_NOT_FOUND" || problem.Type != "about:blank" {
+		t.Fatalf("unexpected problem: %+v", problem)
+	}
+	if problem.Instance != "/orders/7" || problem.Title != "Not Found" {
+		t.Fatalf("unexpected problem: %+v", problem)
+	}
+
+	app = newErrorHandlerTestApp(t, Config{Errors: ErrorConfig{
+		ProblemDetails:     true,
+		ProblemTypeBaseURL: "https://errors.example.com/",
+	}})
+	app.Get("/orders/:id", func(c fiber.Ctx) error {
+		return NewError(http.StatusNotFound, "ORDER_NOT_FOUND", "order not found")
+	})
+	_, body = doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/orders/7", nil))
+	if err := json.Unmarshal(body, &problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if problem.Type != "https://errors.example.com/order-not-found" {
+		t.Fatalf("problem type: got=%q", problem.Type)
+	}
+}
+
+func TestHTTPErrorIsMatchesAcrossCopies(t *testing.T) {
+	errNotFound := NewError(http.StatusNotFound, "ORDER_NOT_FOUND", "order not found")
+	cause := errors.New("sql: no rows")
+
+	wrapped := errNotFound.WithDetails("id=1").Wrap(cause)
+	if !errors.Is(wrapped, errNotFound) {
+		t.Fatal("expected wrapped copy to match the sentinel")
+	}
+	if !errors.Is(wrapped, cause) {
+		t.Fatal("expected wrapped copy to match its cause")
+	}
+	if len(errNotFound.Details) != 0 {
+		t.Fatalf("sentinel was mutated: %+v", errNotFound.Details)
+	}
+}
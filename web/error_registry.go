@@ -0,0 +1,159 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/bronystylecrazy/ultrastructure/di"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+const ErrorMappersGroupName = "us.web.error_mappers"
+
+// ErrorMapper translates a domain error into an HTTPError.
+// It returns false when the error is not one it knows about.
+type ErrorMapper interface {
+	MapError(err error) (*HTTPError, bool)
+}
+
+// ErrorMapperFunc adapts a function into an ErrorMapper.
+type ErrorMapperFunc func(err error) (*HTTPError, bool)
+
+func (f ErrorMapperFunc) MapError(err error) (*HTTPError, bool) {
+	return f(err)
+}
+
+// ErrorMapping maps errors matching Target (via errors.Is) to an HTTP response.
+type ErrorMapping struct {
+	Target  error
+	Status  int
+	Code    string
+	Message string
+}
+
+func (m ErrorMapping) MapError(err error) (*HTTPError, bool) {
+	if m.Target == nil || !errors.Is(err, m.Target) {
+		return nil, false
+	}
+	message := m.Message
+	if message == "" {
+		message = err.Error()
+	}
+	return NewError(m.Status, m.Code, message).Wrap(err), true
+}
+
+// ErrorMappings is an ordered list of mappings; the first match wins.
+type ErrorMappings []ErrorMapping
+
+func (m ErrorMappings) MapError(err error) (*HTTPError, bool) {
+	for _, mapping := range m {
+		if out, ok := mapping.MapError(err); ok {
+			return out, true
+		}
+	}
+	return nil, false
+}
+
+// WithErrorMappings registers mappings with the application error registry.
+//
+// Usage: web.WithErrorMappings(web.ErrorMapping{Target: ErrOrderClosed, Status: 409, Code: "ORDER_CLOSED"})
+func WithErrorMappings(mappings ...ErrorMapping) di.Node {
+	copied := append(ErrorMappings(nil), mappings...)
+	return di.Provide(
+		func() ErrorMapper { return copied },
+		di.Group(ErrorMappersGroupName),
+	)
+}
+
+// ErrorRegistry resolves any error returned by a handler into an HTTPError.
+type ErrorRegistry struct {
+	mu      sync.RWMutex
+	mappers []ErrorMapper
+}
+
+// NewErrorRegistry creates a registry consulting mappers in order.
+func NewErrorRegistry(mappers ...ErrorMapper) *ErrorRegistry {
+	r := &ErrorRegistry{}
+	r.Use(mappers...)
+	return r
+}
+
+// Use appends mappers to the registry.
+func (r *ErrorRegistry) Use(mappers ...ErrorMapper) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, mapper := range mappers {
+		if mapper != nil {
+			r.mappers = append(r.mappers, mapper)
+		}
+	}
+}
+
+// Register maps errors matching target to status and code.
+func (r *ErrorRegistry) Register(target error, status int, code string, message ...string) {
+	r.Use(ErrorMapping{
+		Target:  target,
+		Status:  status,
+		Code:    code,
+		Message: firstDescription(message...),
+	})
+}
+
+// Resolve converts err into an HTTPError. Explicit HTTPErrors win, then
+// registered mappers, validation errors, fiber errors and finally a 500.
+func (r *ErrorRegistry) Resolve(err error) *HTTPError {
+	if err == nil {
+		return nil
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr != nil {
+		return httpErr
+	}
+
+	if r != nil {
+		r.mu.RLock()
+		mappers := r.mappers
+		r.mu.RUnlock()
+		for _, mapper := range mappers {
+			if out, ok := mapper.MapError(err); ok && out != nil {
+				return out
+			}
+		}
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return NewError(
+			http.StatusUnprocessableEntity,
+			"VALIDATION_FAILED",
+			"validation failed",
+			ValidationErrorDetails(validationErrs)...,
+		).Wrap(err)
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return NewError(fiberErr.Code, "", fiberErr.Message).Wrap(err)
+	}
+
+	return NewError(http.StatusInternalServerError, "", err.Error()).Wrap(err)
+}
+
+// ValidationErrorDetails renders one detail line per failed field, named
+// by its Go struct field.
+func ValidationErrorDetails(errs validator.ValidationErrors) []string {
+	out := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		rule := fe.Tag()
+		if param := strings.TrimSpace(fe.Param()); param != "" {
+			rule += "=" + param
+		}
+		out = append(out, fmt.Sprintf("%s: failed on '%s'", fe.StructField(), rule))
+	}
+	return out
+}
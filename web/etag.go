@@ -0,0 +1,107 @@
+package web
+
+import (
+	"hash/crc32"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type ETagConfig struct {
+	Enabled bool `mapstructure:"enabled" default:"false"`
+	// Weak generates W/ prefixed ETags instead of strong ones.
+	Weak bool `mapstructure:"weak" default:"false"`
+}
+
+// ConditionalGetMiddleware tags successful GET and HEAD responses with an
+// ETag and answers If-None-Match and If-Modified-Since with 304 Not Modified.
+//
+// An ETag or Last-Modified header set by the handler is kept as is.
+type ConditionalGetMiddleware struct {
+	config ETagConfig
+}
+
+func NewConditionalGetMiddleware(config Config) *ConditionalGetMiddleware {
+	return &ConditionalGetMiddleware{config: config.ETag}
+}
+
+func (m *ConditionalGetMiddleware) Handle(r Router) {
+	if !m.config.Enabled {
+		return
+	}
+	r.Use(m.Middleware)
+}
+
+func (m *ConditionalGetMiddleware) Middleware(c fiber.Ctx) error {
+	if err := c.Next(); err != nil {
+		return err
+	}
+	if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+		return nil
+	}
+	res := c.Response()
+	if res.StatusCode() != fiber.StatusOK || res.IsBodyStream() {
+		return nil
+	}
+
+	tag := string(res.Header.Peek(fiber.HeaderETag))
+	if tag == "" {
+		tag = ComputeETag(res.Body(), m.config.Weak)
+		res.Header.Set(fiber.HeaderETag, tag)
+	}
+
+	if notModified(c, tag) {
+		res.ResetBody()
+		res.SetStatusCode(fiber.StatusNotModified)
+		res.Header.Del(fiber.HeaderContentType)
+		res.Header.Del(fiber.HeaderContentLength)
+	}
+	return nil
+}
+
+// ComputeETag returns an ETag for body. Handlers can set it themselves when
+// they know a cheaper validator, such as a row version.
+func ComputeETag(body []byte, weak bool) string {
+	tag := `"` + strconv.Itoa(len(body)) + "-" + strconv.FormatUint(uint64(crc32.ChecksumIEEE(body)), 16) + `"`
+	if weak {
+		return "W/" + tag
+	}
+	return tag
+}
+
+// notModified reports whether the request's validators match the response.
+// If-Modified-Since is ignored when If-None-Match is present (RFC 9110 13.1.3).
+func notModified(c fiber.Ctx, tag string) bool {
+	if match := c.Get(fiber.HeaderIfNoneMatch); match != "" {
+		return etagMatches(match, tag)
+	}
+
+	since := c.Get(fiber.HeaderIfModifiedSince)
+	modified := string(c.Response().Header.Peek(fiber.HeaderLastModified))
+	if since == "" || modified == "" {
+		return false
+	}
+	sinceTime, err := http.ParseTime(since)
+	if err != nil {
+		return false
+	}
+	modifiedTime, err := http.ParseTime(modified)
+	if err != nil {
+		return false
+	}
+	return !modifiedTime.After(sinceTime)
+}
+
+// etagMatches uses the weak comparison If-None-Match requires.
+func etagMatches(header, tag string) bool {
+	tag = strings.TrimPrefix(tag, "W/")
+	for candidate := range strings.SplitSeq(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
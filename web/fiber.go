@@ -2,6 +2,8 @@ package web
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"strings"
@@ -33,6 +35,7 @@ type FiberAppConfig struct {
 	WriteBufferSize    int           `mapstructure:"write_buffer_size" default:"4096"`
 	DisableKeepalive   bool          `mapstructure:"disable_keepalive" default:"false"`
 	EnableIPValidation bool          `mapstructure:"enable_ip_validation" default:"true"`
+	StreamRequestBody  bool          `mapstructure:"stream_request_body" default:"false"`
 }
 
 type FiberProxyConfig struct {
@@ -122,6 +125,12 @@ type FiberServer struct {
 	App       *fiber.App
 	Config    FiberConfig
 	WebConfig Config
+	Drain     *Drain
+	// Certificates serves web.tls once the server listens with TLS.
+	Certificates *CertificateStore
+
+	listenersMu sync.Mutex
+	listeners   []*extraListener
 
 	startedCh   chan struct{}
 	startedOnce sync.Once
@@ -147,12 +156,14 @@ func NewFiberServer(webConfig Config, config FiberConfig, configurers ...FiberCo
 		WriteBufferSize:    config.App.WriteBufferSize,
 		DisableKeepalive:   config.App.DisableKeepalive,
 		EnableIPValidation: config.App.EnableIPValidation,
+		StreamRequestBody:  config.App.StreamRequestBody,
 		TrustProxy:         config.Proxy.TrustProxy,
 		ProxyHeader:        config.Proxy.ProxyHeader,
 		TrustProxyConfig:   config.Proxy.TrustProxyConfig,
 		CaseSensitive:      config.CaseSensitive,
 		StrictRouting:      config.StrictRouting,
 		StructValidator:    NewFiberValidator(),
+		ErrorHandler:       NewErrorHandler(webConfig, nil).HandleError,
 	}
 	for _, configurer := range configurers {
 		if configurer != nil {
@@ -165,6 +176,7 @@ func NewFiberServer(webConfig Config, config FiberConfig, configurers ...FiberCo
 		App:        fiber.New(appCfg),
 		Config:     config,
 		WebConfig:  webConfig,
+		Drain:      NewDrain(),
 		startedCh:  make(chan struct{}),
 		listenDone: make(chan struct{}),
 	}
@@ -186,7 +198,43 @@ func (s *FiberServer) listen() error {
 		return err
 	}
 
+	var tlsConfig *tls.Config
+	if listenConfig.CertFile != "" {
+		store, err := NewCertificateStore(s.WebConfig.TLS)
+		if err != nil {
+			return err
+		}
+		s.Certificates = store
+		tlsConfig = store.TLSConfig()
+		listenConfig.TLSConfig = tlsConfig
+
+		if s.WebConfig.TLS.Reload {
+			ctx, cancel := context.WithCancel(context.Background())
+			err := store.Watch(ctx, func(err error) {
+				if err != nil {
+					s.Obs.Error("tls certificates reload failed", zap.Error(err))
+					return
+				}
+				s.Obs.Info("tls certificates reloaded")
+			})
+			if err != nil {
+				cancel()
+				return err
+			}
+			defer cancel()
+		}
+	}
+
+	listeners, err := s.openListeners(tlsConfig)
+	if err != nil {
+		return err
+	}
+	s.listenersMu.Lock()
+	s.listeners = listeners
+	s.listenersMu.Unlock()
+
 	s.App.Hooks().OnListen(func(data fiber.ListenData) error {
+		s.serveListeners(listeners)
 		s.markStarted()
 
 		scheme := "http"
@@ -225,6 +273,7 @@ func (s *FiberServer) listen() error {
 
 	err = s.App.Listen(listenAddr, listenConfig)
 	if err != nil {
+		closeListeners(listeners)
 		s.markStarted()
 	}
 	return err
@@ -244,6 +293,58 @@ func (s *FiberServer) markStarted() {
 	})
 }
 
+// Stop drains the server: it fails readiness, waits listen.pre_stop_delay so
+// load balancers notice, stops accepting connections and waits up to
+// listen.shutdown_timeout for in-flight requests and tracked connections.
+// It runs before other lc.Stoppers, so their dependencies are still up while
+// requests finish.
 func (s *FiberServer) Stop(ctx context.Context) error {
-	return s.App.ShutdownWithContext(ctx)
+	drain := s.Drain
+	if drain == nil {
+		return s.App.ShutdownWithContext(ctx)
+	}
+
+	drain.Begin()
+	s.Obs.Info("fiber server draining",
+		zap.Int64("in_flight", drain.InFlight()),
+		zap.Duration("pre_stop_delay", s.WebConfig.Listen.PreStopDelay),
+	)
+
+	if delay := s.WebConfig.Listen.PreStopDelay; delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}
+
+	timeout := s.WebConfig.Listen.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	s.listenersMu.Lock()
+	listeners := s.listeners
+	s.listenersMu.Unlock()
+	err := errors.Join(
+		s.App.ShutdownWithContext(shutdownCtx),
+		shutdownRedirects(shutdownCtx, listeners),
+	)
+	_ = drain.Wait(shutdownCtx)
+
+	if abandoned := drain.InFlight(); abandoned > 0 {
+		s.Obs.Warn("fiber server abandoned in-flight work",
+			zap.Int64("requests", drain.Requests()),
+			zap.Int64("connections", abandoned-drain.Requests()),
+			zap.Duration("shutdown_timeout", timeout),
+		)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		// Abandoned work is logged above; the remaining stoppers still run.
+		return nil
+	}
+	return err
 }
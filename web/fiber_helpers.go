@@ -24,6 +24,10 @@ func BuildFiberListenConfig(config Config) (fiber.ListenConfig, error) {
 		EnablePrintRoutes:     config.Listen.EnablePrintRoutes,
 	}
 
+	if err := validateListeners(config); err != nil {
+		return fiber.ListenConfig{}, err
+	}
+
 	certFile := strings.TrimSpace(config.TLS.CertFile)
 	certKeyFile := strings.TrimSpace(config.TLS.CertKeyFile)
 	certClientFile := strings.TrimSpace(config.TLS.CertClientFile)
@@ -42,6 +46,14 @@ func BuildFiberListenConfig(config Config) (fiber.ListenConfig, error) {
 	if err != nil {
 		return fiber.ListenConfig{}, err
 	}
+	if _, err := ParseTLSClientAuth(config.TLS.ClientAuth, certClientFile != ""); err != nil {
+		return fiber.ListenConfig{}, err
+	}
+	for i, pair := range config.TLS.Certificates {
+		if strings.TrimSpace(pair.CertFile) == "" || strings.TrimSpace(pair.CertKeyFile) == "" {
+			return fiber.ListenConfig{}, fmt.Errorf("tls certificates[%d]: both cert_file and cert_key_file are required", i)
+		}
+	}
 
 	out.CertFile = certFile
 	out.CertKeyFile = certKeyFile
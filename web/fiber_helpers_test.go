@@ -36,6 +36,31 @@ func TestBuildFiberListenConfigTLSValidation(t *testing.T) {
 			},
 			wantErr: "tls client CA requires server cert_file and cert_key_file",
 		},
+		{
+			name: "unknown client auth",
+			cfg: Config{
+				TLS: TLSConfig{CertFile: "server.crt", CertKeyFile: "server.key", ClientAuth: "sometimes"},
+			},
+			wantErr: "unsupported tls client_auth",
+		},
+		{
+			name: "sni pair without key",
+			cfg: Config{
+				TLS: TLSConfig{
+					CertFile:     "server.crt",
+					CertKeyFile:  "server.key",
+					Certificates: []TLSCertificateConfig{{CertFile: "admin.crt"}},
+				},
+			},
+			wantErr: "tls certificates[0]",
+		},
+		{
+			name: "tls listener without certificates",
+			cfg: Config{
+				Listeners: []ListenerConfig{{Address: ":8443", TLS: true}},
+			},
+			wantErr: "listeners[0]: tls requires",
+		},
 		{
 			name: "full tls config",
 			cfg: Config{
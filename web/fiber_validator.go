@@ -1,17 +1,37 @@
 package web
 
-import "github.com/go-playground/validator/v10"
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
 
 type FiberValidator struct {
 	validate *validator.Validate
 }
 
+// NewFiberValidator names fields by their json tag in FieldError.Field, which
+// translated validation messages use; StructField keeps the Go name.
 func NewFiberValidator() *FiberValidator {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(jsonFieldName)
 	return &FiberValidator{
-		validate: validator.New(),
+		validate: validate,
 	}
 }
 
 func (v *FiberValidator) Validate(out any) error {
 	return v.validate.Struct(out)
 }
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
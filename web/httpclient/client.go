@@ -0,0 +1,117 @@
+package httpclient
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/otel"
+)
+
+// Client is an *http.Client whose transport resolves relative URLs such as
+// "/v1/orders" against BaseURL, adds the configured headers, propagates the
+// trace context and applies retries, the circuit breaker and the per-host
+// limit.
+type Client struct {
+	*http.Client
+	Name    string
+	BaseURL *url.URL
+}
+
+// New builds a client from config. Telemetry is read from telemetry on every
+// request, so it may be attached after the client is built; nil uses a nop
+// observer.
+func New(name string, config ClientConfig, telemetry *otel.Telemetry) (*Client, error) {
+	config = config.withDefaults()
+
+	var base *url.URL
+	if config.BaseURL != "" {
+		u, err := url.Parse(config.BaseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("httpclient %q: invalid base_url %q", name, config.BaseURL)
+		}
+		base = u
+	}
+
+	tlsConfig, err := config.TLS.Load()
+	if err != nil {
+		return nil, fmt.Errorf("httpclient %q: %w", name, err)
+	}
+	dialer := &net.Dialer{Timeout: config.DialTimeout, KeepAlive: 30 * time.Second, Control: config.DialControl}
+	rt := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		ForceAttemptHTTP2:     true,
+	}
+	if telemetry == nil {
+		nop := otel.Nop()
+		telemetry = &nop
+	}
+
+	return &Client{
+		Client: &http.Client{
+			Timeout:   config.Timeout,
+			Transport: newTransport(name, base, config, rt, telemetry),
+		},
+		Name:    name,
+		BaseURL: base,
+	}, nil
+}
+
+// Clients holds the clients declared under httpclient.clients.
+type Clients struct {
+	otel.Telemetry
+
+	config  Config
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+func NewClients(config Config) (*Clients, error) {
+	c := &Clients{
+		Telemetry: otel.Nop(),
+		config:    config,
+		clients:   make(map[string]*Client, len(config.Clients)),
+	}
+	for name := range config.Clients {
+		if _, err := c.Get(name); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Get returns the named client. Names missing from the config get a client
+// built from Defaults.
+func (c *Clients) Get(name string) (*Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if client, ok := c.clients[name]; ok {
+		return client, nil
+	}
+	client, err := New(name, c.config.Client(name), &c.Telemetry)
+	if err != nil {
+		return nil, err
+	}
+	c.clients[name] = client
+	return client, nil
+}
+
+// Names returns the configured client names in order.
+func (c *Clients) Names() []string {
+	names := make([]string, 0, len(c.config.Clients))
+	for name := range c.config.Clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,155 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	otelglobal "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestClientResolvesBaseURLAndPropagatesContext(t *testing.T) {
+	otelglobal.SetTextMapPropagator(propagation.TraceContext{})
+
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	telemetry := otel.Telemetry{Obs: otel.NewObserver(zap.New(core), sdktrace.NewTracerProvider().Tracer("test"))}
+	config := Config{
+		Defaults: ClientConfig{Headers: map[string]string{"User-Agent": "us-test"}},
+		Clients: map[string]ClientConfig{
+			"partner": {BaseURL: srv.URL + "/api/", Headers: map[string]string{"Authorization": "Bearer secret"}},
+		},
+	}
+	client, err := New("partner", config.Client("partner"), &telemetry)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx := otel.WithRequestID(context.Background(), "req-1")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/orders?page=2", nil)
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	res.Body.Close()
+
+	if got.URL.Path != "/api/orders" || got.URL.RawQuery != "page=2" {
+		t.Fatalf("resolved url: %s", got.URL)
+	}
+	if got.Header.Get("User-Agent") != "us-test" || got.Header.Get("Authorization") != "Bearer secret" {
+		t.Fatalf("headers: %v", got.Header)
+	}
+	if got.Header.Get("X-Request-ID") != "req-1" || got.Header.Get("Traceparent") == "" {
+		t.Fatalf("propagation headers: %v", got.Header)
+	}
+
+	entries := logs.FilterMessage("outbound request").All()
+	if len(entries) != 1 {
+		t.Fatalf("expected one request log, got %d", len(entries))
+	}
+	headers, _ := entries[0].ContextMap()["http.request.header"].(map[string]string)
+	if headers["Authorization"] != redacted {
+		t.Fatalf("authorization must be redacted, got %q", headers["Authorization"])
+	}
+}
+
+func TestClientRetriesIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client, err := New("retry", ClientConfig{
+		BaseURL: srv.URL,
+		Retry:   RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond},
+	}, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	res, err := client.Get("/flaky")
+	if err != nil || res.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Fatalf("GET: err=%v calls=%d", err, calls.Load())
+	}
+	res.Body.Close()
+
+	calls.Store(0)
+	res, err = client.Post("/flaky", "application/json", strings.NewReader(`{}`))
+	if err != nil || res.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Fatalf("POST must not be retried: err=%v calls=%d", err, calls.Load())
+	}
+	res.Body.Close()
+}
+
+func TestClientCircuitBreakerOpensAfterFailures(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	client, err := New("breaker", ClientConfig{
+		BaseURL:        srv.URL,
+		Retry:          RetryConfig{MaxAttempts: 1},
+		CircuitBreaker: BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Hour},
+	}, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := client.Get("/down")
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		res.Body.Close()
+	}
+	if _, err := client.Get("/down"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("open circuit must not reach the server, calls=%d", calls.Load())
+	}
+}
+
+func TestCircuitBreakerCanceledProbeAllowsNextProbe(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker(BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Second})
+	breaker.now = func() time.Time { return now }
+
+	breaker.record(false)
+	now = now.Add(2 * time.Second)
+	if !breaker.allow() {
+		t.Fatal("expected a half-open probe")
+	}
+	if breaker.allow() {
+		t.Fatal("only one probe may run at a time")
+	}
+	breaker.release()
+	if !breaker.allow() {
+		t.Fatal("a canceled probe must let the next request probe")
+	}
+}
@@ -0,0 +1,187 @@
+package httpclient
+
+import (
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/otel"
+)
+
+// Config holds the named outbound clients. Every client starts from Defaults
+// and overrides the fields it sets.
+type Config struct {
+	Defaults ClientConfig            `mapstructure:"defaults"`
+	Clients  map[string]ClientConfig `mapstructure:"clients"`
+}
+
+type ClientConfig struct {
+	BaseURL               string            `mapstructure:"base_url"`
+	Headers               map[string]string `mapstructure:"headers"`
+	Timeout               time.Duration     `mapstructure:"timeout" default:"30s"`
+	DialTimeout           time.Duration     `mapstructure:"dial_timeout" default:"5s"`
+	TLSHandshakeTimeout   time.Duration     `mapstructure:"tls_handshake_timeout" default:"5s"`
+	ResponseHeaderTimeout time.Duration     `mapstructure:"response_header_timeout"`
+	IdleConnTimeout       time.Duration     `mapstructure:"idle_conn_timeout" default:"90s"`
+	MaxIdleConnsPerHost   int               `mapstructure:"max_idle_conns_per_host" default:"16"`
+	// MaxConcurrentPerHost caps in-flight requests per host. Requests over
+	// the cap wait for a slot or their context.
+	MaxConcurrentPerHost int            `mapstructure:"max_concurrent_per_host"`
+	TLS                  otel.TLSConfig `mapstructure:"tls"`
+	Retry                RetryConfig    `mapstructure:"retry"`
+	CircuitBreaker       BreakerConfig  `mapstructure:"circuit_breaker"`
+	// RedactHeaders are logged as "[REDACTED]" on top of the credential
+	// headers that are always redacted.
+	RedactHeaders []string `mapstructure:"redact_headers"`
+	// DialControl runs on every connection after the address is resolved
+	// and before it is dialed; an error aborts the dial. With a proxy it
+	// sees the proxy's address.
+	DialControl func(network, address string, conn syscall.RawConn) error `mapstructure:"-"`
+}
+
+// RetryConfig retries idempotent requests that fail with a transport error
+// or a retryable status. MaxAttempts of 1 disables retries.
+type RetryConfig struct {
+	MaxAttempts    int           `mapstructure:"max_attempts" default:"3"`
+	InitialBackoff time.Duration `mapstructure:"initial_backoff" default:"100ms"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff" default:"2s"`
+	Statuses       []int         `mapstructure:"statuses"`
+}
+
+// BreakerConfig opens the circuit after FailureThreshold consecutive
+// failures and lets one probe through once OpenTimeout has passed.
+type BreakerConfig struct {
+	Disabled         bool          `mapstructure:"disabled" default:"false"`
+	FailureThreshold int           `mapstructure:"failure_threshold" default:"5"`
+	OpenTimeout      time.Duration `mapstructure:"open_timeout" default:"30s"`
+}
+
+const (
+	DefaultTimeout             = 30 * time.Second
+	DefaultDialTimeout         = 5 * time.Second
+	DefaultTLSHandshakeTimeout = 5 * time.Second
+	DefaultIdleConnTimeout     = 90 * time.Second
+	DefaultMaxIdleConnsPerHost = 16
+	DefaultMaxAttempts         = 3
+	DefaultInitialBackoff      = 100 * time.Millisecond
+	DefaultMaxBackoff          = 2 * time.Second
+	DefaultFailureThreshold    = 5
+	DefaultOpenTimeout         = 30 * time.Second
+)
+
+// DefaultRetryStatuses are retried when RetryConfig.Statuses is empty.
+var DefaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// Client returns the configuration of the named client merged over Defaults.
+func (c Config) Client(name string) ClientConfig {
+	return c.Defaults.merge(c.Clients[name]).withDefaults()
+}
+
+// withDefaults fills in the values a zero ClientConfig leaves empty.
+func (c ClientConfig) withDefaults() ClientConfig {
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = DefaultDialTimeout
+	}
+	if c.TLSHandshakeTimeout <= 0 {
+		c.TLSHandshakeTimeout = DefaultTLSHandshakeTimeout
+	}
+	if c.IdleConnTimeout <= 0 {
+		c.IdleConnTimeout = DefaultIdleConnTimeout
+	}
+	if c.MaxIdleConnsPerHost <= 0 {
+		c.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	if c.Retry.MaxAttempts <= 0 {
+		c.Retry.MaxAttempts = DefaultMaxAttempts
+	}
+	if c.Retry.InitialBackoff <= 0 {
+		c.Retry.InitialBackoff = DefaultInitialBackoff
+	}
+	if c.Retry.MaxBackoff <= 0 {
+		c.Retry.MaxBackoff = DefaultMaxBackoff
+	}
+	if len(c.Retry.Statuses) == 0 {
+		c.Retry.Statuses = DefaultRetryStatuses
+	}
+	if c.CircuitBreaker.FailureThreshold <= 0 {
+		c.CircuitBreaker.FailureThreshold = DefaultFailureThreshold
+	}
+	if c.CircuitBreaker.OpenTimeout <= 0 {
+		c.CircuitBreaker.OpenTimeout = DefaultOpenTimeout
+	}
+	return c
+}
+
+func (c ClientConfig) merge(o ClientConfig) ClientConfig {
+	out := c
+	if o.BaseURL != "" {
+		out.BaseURL = o.BaseURL
+	}
+	if len(o.Headers) > 0 {
+		out.Headers = make(map[string]string, len(c.Headers)+len(o.Headers))
+		for k, v := range c.Headers {
+			out.Headers[k] = v
+		}
+		for k, v := range o.Headers {
+			out.Headers[k] = v
+		}
+	}
+	if o.Timeout > 0 {
+		out.Timeout = o.Timeout
+	}
+	if o.DialTimeout > 0 {
+		out.DialTimeout = o.DialTimeout
+	}
+	if o.TLSHandshakeTimeout > 0 {
+		out.TLSHandshakeTimeout = o.TLSHandshakeTimeout
+	}
+	if o.ResponseHeaderTimeout > 0 {
+		out.ResponseHeaderTimeout = o.ResponseHeaderTimeout
+	}
+	if o.IdleConnTimeout > 0 {
+		out.IdleConnTimeout = o.IdleConnTimeout
+	}
+	if o.MaxIdleConnsPerHost > 0 {
+		out.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+	}
+	if o.MaxConcurrentPerHost > 0 {
+		out.MaxConcurrentPerHost = o.MaxConcurrentPerHost
+	}
+	if !o.TLS.IsZero() {
+		out.TLS = o.TLS
+	}
+	if o.Retry.MaxAttempts > 0 {
+		out.Retry.MaxAttempts = o.Retry.MaxAttempts
+	}
+	if o.Retry.InitialBackoff > 0 {
+		out.Retry.InitialBackoff = o.Retry.InitialBackoff
+	}
+	if o.Retry.MaxBackoff > 0 {
+		out.Retry.MaxBackoff = o.Retry.MaxBackoff
+	}
+	if len(o.Retry.Statuses) > 0 {
+		out.Retry.Statuses = o.Retry.Statuses
+	}
+	if o.CircuitBreaker.Disabled {
+		out.CircuitBreaker.Disabled = true
+	}
+	if o.CircuitBreaker.FailureThreshold > 0 {
+		out.CircuitBreaker.FailureThreshold = o.CircuitBreaker.FailureThreshold
+	}
+	if o.CircuitBreaker.OpenTimeout > 0 {
+		out.CircuitBreaker.OpenTimeout = o.CircuitBreaker.OpenTimeout
+	}
+	if o.DialControl != nil {
+		out.DialControl = o.DialControl
+	}
+	out.RedactHeaders = append(append([]string(nil), c.RedactHeaders...), o.RedactHeaders...)
+	return out
+}
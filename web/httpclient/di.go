@@ -0,0 +1,32 @@
+package httpclient
+
+import (
+	"github.com/bronystylecrazy/ultrastructure/cfg"
+	"github.com/bronystylecrazy/ultrastructure/di"
+	"github.com/bronystylecrazy/ultrastructure/otel"
+)
+
+var OtelScope = "httpclient"
+
+// Providers registers the clients configured under httpclient.clients.
+func Providers(extends ...di.Node) di.Node {
+	nodes := []any{
+		cfg.Config[Config]("httpclient", cfg.WithSourceFile("config.toml"), cfg.WithType("toml")),
+		di.Provide(NewClients, otel.Layer(OtelScope)),
+	}
+	nodes = append(nodes, di.ConvertAnys(extends)...)
+	return di.Options(nodes...)
+}
+
+// Named provides the named client as a *Client tagged with its name.
+//
+// Usage: httpclient.Providers(httpclient.Named("payments")) and
+// di.Provide(NewGateway, di.Params(`name:"payments"`))
+func Named(name string) di.Node {
+	return di.Provide(
+		func(clients *Clients) (*Client, error) {
+			return clients.Get(name)
+		},
+		di.Name(name),
+	)
+}
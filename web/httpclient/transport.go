@@ -0,0 +1,363 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	otelglobal "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// ErrCircuitOpen is returned without sending the request while the client's
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("httpclient: circuit breaker is open")
+
+// Metrics recorded for every outbound request.
+const (
+	RequestsMetric        = "http.client.requests"
+	RequestDurationMetric = "http.client.request.duration"
+	RetriesMetric         = "http.client.retries"
+	CircuitOpenMetric     = "http.client.circuit_open"
+)
+
+const (
+	headerIdempotencyKey = "Idempotency-Key"
+	headerRequestID      = "X-Request-ID"
+	redacted             = "[REDACTED]"
+)
+
+// alwaysRedacted are credential headers never written to logs.
+var alwaysRedacted = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+type transport struct {
+	name      string
+	base      *url.URL
+	headers   http.Header
+	retry     RetryConfig
+	breaker   *circuitBreaker
+	hosts     *hostLimiter
+	redact    map[string]struct{}
+	next      http.RoundTripper
+	telemetry *otel.Telemetry
+}
+
+func newTransport(name string, base *url.URL, config ClientConfig, next http.RoundTripper, telemetry *otel.Telemetry) *transport {
+	t := &transport{
+		name:      name,
+		base:      base,
+		headers:   make(http.Header, len(config.Headers)),
+		retry:     config.Retry,
+		redact:    make(map[string]struct{}),
+		next:      next,
+		telemetry: telemetry,
+	}
+	for k, v := range config.Headers {
+		t.headers.Set(k, v)
+	}
+	for _, h := range append(slices.Clone(alwaysRedacted), config.RedactHeaders...) {
+		t.redact[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+	if !config.CircuitBreaker.Disabled {
+		t.breaker = newCircuitBreaker(config.CircuitBreaker)
+	}
+	if config.MaxConcurrentPerHost > 0 {
+		t.hosts = newHostLimiter(config.MaxConcurrentPerHost)
+	}
+	return t
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	startedAt := time.Now()
+	ctx, span := t.telemetry.Obs.Start(req.Context(), "HTTP "+req.Method, trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	req = t.prepare(ctx, req)
+
+	attrs := []attribute.KeyValue{
+		attribute.String("http.client.name", t.name),
+		attribute.String("http.request.method", req.Method),
+		attribute.String("server.address", req.URL.Host),
+	}
+	if t.breaker != nil && !t.breaker.allow() {
+		span.AddCounter(ctx, CircuitOpenMetric, 1, attrs...)
+		span.Warn("outbound request rejected by circuit breaker", zap.String("http.client.name", t.name), zap.String("url.full", req.URL.String()))
+		return nil, ErrCircuitOpen
+	}
+
+	res, attempts, err := t.do(ctx, req, span, attrs)
+	if t.breaker != nil {
+		if errors.Is(err, context.Canceled) {
+			t.breaker.release()
+		} else {
+			t.breaker.record(err == nil && res.StatusCode < http.StatusInternalServerError)
+		}
+	}
+
+	status := 0
+	if res != nil {
+		status = res.StatusCode
+	}
+	attrs = append(attrs, attribute.Int("http.response.status_code", status))
+	traceSpan := trace.SpanFromContext(ctx)
+	traceSpan.SetAttributes(append(attrs, attribute.String("url.full", req.URL.String()), attribute.Int("http.request.resend_count", attempts-1))...)
+	if err != nil {
+		traceSpan.RecordError(err)
+		traceSpan.SetStatus(codes.Error, err.Error())
+	} else if status >= http.StatusInternalServerError {
+		traceSpan.SetStatus(codes.Error, http.StatusText(status))
+	}
+
+	duration := time.Since(startedAt)
+	span.AddCounter(ctx, RequestsMetric, 1, attrs...)
+	span.RecordHistogram(ctx, RequestDurationMetric, float64(duration)/float64(time.Millisecond), attrs...)
+
+	fields := []zap.Field{
+		zap.String("http.client.name", t.name),
+		zap.String("http.request.method", req.Method),
+		zap.String("url.full", req.URL.String()),
+		zap.Int("http.response.status_code", status),
+		zap.Int("attempts", attempts),
+		zap.Duration("duration", duration),
+		zap.Any("http.request.header", t.redactHeaders(req.Header)),
+	}
+	if res != nil {
+		fields = append(fields, zap.Any("http.response.header", t.redactHeaders(res.Header)))
+	}
+	if err != nil {
+		span.Warn("outbound request failed", append(fields, zap.Error(err))...)
+	} else {
+		span.Debug("outbound request", fields...)
+	}
+	return res, err
+}
+
+// prepare clones req, resolves it against the base URL and adds the
+// configured, request id and trace headers.
+func (t *transport) prepare(ctx context.Context, req *http.Request) *http.Request {
+	out := req.Clone(ctx)
+	if t.base != nil && out.URL.Host == "" {
+		u := *t.base
+		u.Path = strings.TrimSuffix(t.base.Path, "/") + "/" + strings.TrimPrefix(out.URL.Path, "/")
+		u.RawPath = ""
+		u.RawQuery = out.URL.RawQuery
+		u.Fragment = out.URL.Fragment
+		out.URL = &u
+		out.Host = ""
+	}
+	for k, v := range t.headers {
+		if out.Header.Get(k) == "" {
+			out.Header[k] = v
+		}
+	}
+	if requestID := otel.RequestIDFromContext(ctx); requestID != "" && out.Header.Get(headerRequestID) == "" {
+		out.Header.Set(headerRequestID, requestID)
+	}
+	otelglobal.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(out.Header))
+	return out
+}
+
+func (t *transport) do(ctx context.Context, req *http.Request, span *otel.Span, attrs []attribute.KeyValue) (*http.Response, int, error) {
+	retryable := t.retry.MaxAttempts > 1 && idempotent(req) &&
+		(req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, attempt - 1, err
+			}
+			req.Body = body
+		}
+
+		res, err := t.send(ctx, req)
+		if !retryable || attempt >= t.retry.MaxAttempts || ctx.Err() != nil || !t.shouldRetry(res, err) {
+			return res, attempt, err
+		}
+
+		wait := t.backoff(attempt, res)
+		if res != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+			_ = res.Body.Close()
+		}
+		span.AddCounter(ctx, RetriesMetric, 1, attrs...)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempt, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (t *transport) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if t.hosts == nil {
+		return t.next.RoundTrip(req)
+	}
+	release, err := t.hosts.acquire(ctx, req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	res.Body = &releaseOnClose{ReadCloser: res.Body, release: release}
+	return res, nil
+}
+
+func (t *transport) shouldRetry(res *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return slices.Contains(t.retry.Statuses, res.StatusCode)
+}
+
+// backoff waits exponentially longer after each attempt, with equal jitter,
+// and honors a Retry-After in seconds up to MaxBackoff.
+func (t *transport) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, t.retry.MaxBackoff)
+		}
+	}
+	d := t.retry.InitialBackoff << (attempt - 1)
+	if d <= 0 || d > t.retry.MaxBackoff {
+		d = t.retry.MaxBackoff
+	}
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+func (t *transport) redactHeaders(h http.Header) map[string]string {
+	out := make(map[string]string, len(h))
+	for k, v := range h {
+		if _, ok := t.redact[http.CanonicalHeaderKey(k)]; ok {
+			out[k] = redacted
+			continue
+		}
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
+
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(headerIdempotencyKey) != ""
+}
+
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
+// hostLimiter caps in-flight requests per host.
+type hostLimiter struct {
+	mu    sync.Mutex
+	limit int
+	slots map[string]chan struct{}
+}
+
+func newHostLimiter(limit int) *hostLimiter {
+	return &hostLimiter{limit: limit, slots: make(map[string]chan struct{})}
+}
+
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	l.mu.Lock()
+	slots, ok := l.slots[host]
+	if !ok {
+		slots = make(chan struct{}, l.limit)
+		l.slots[host] = slots
+	}
+	l.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// circuitBreaker opens after consecutive failures and half-opens after
+// OpenTimeout, letting a single probe decide whether to close again.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	timeout   time.Duration
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+func newCircuitBreaker(config BreakerConfig) *circuitBreaker {
+	return &circuitBreaker{threshold: config.FailureThreshold, timeout: config.OpenTimeout, now: time.Now}
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.timeout {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// release ends a request that says nothing about the upstream, such as one
+// the caller canceled, so a canceled probe lets the next request probe again.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *circuitBreaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if success {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = b.now()
+	}
+}
@@ -0,0 +1,347 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+
+	// DefaultIdempotencySessionCookie is the session package's access token
+	// cookie, which scopes keys of cookie sessions.
+	DefaultIdempotencySessionCookie = "access_token"
+)
+
+var (
+	ErrIdempotencyKeyRequired = NewError(http.StatusBadRequest, "IDEMPOTENCY_KEY_REQUIRED", "Idempotency-Key header is required")
+	ErrIdempotencyKeyInvalid  = NewError(http.StatusBadRequest, "IDEMPOTENCY_KEY_INVALID", "Idempotency-Key header is too long")
+	ErrIdempotencyInFlight    = NewError(http.StatusConflict, "IDEMPOTENCY_KEY_IN_USE", "a request with this idempotency key is still in progress")
+	ErrIdempotencyKeyReused   = NewError(http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", "idempotency key was already used for a different request")
+	ErrIdempotencyNoCaller    = NewError(http.StatusUnauthorized, "IDEMPOTENCY_CALLER_REQUIRED", "Idempotency-Key requires a caller scope")
+)
+
+// IdempotentResponse is the stored response replayed for retries.
+type IdempotentResponse struct {
+	Status int                 `json:"status"`
+	Header map[string][]string `json:"header,omitempty"`
+	Body   []byte              `json:"body,omitempty"`
+}
+
+// IdempotencyRecord is the state kept for one idempotency key.
+type IdempotencyRecord struct {
+	Fingerprint string             `json:"fingerprint"`
+	Completed   bool               `json:"completed"`
+	Response    IdempotentResponse `json:"response"`
+}
+
+// IdempotencyStore keeps idempotency records.
+type IdempotencyStore interface {
+	// Acquire locks key for a new request with the given fingerprint and
+	// returns true. When the key is already known, it returns the existing
+	// record and false. The lock expires after lockTTL if never completed.
+	Acquire(ctx context.Context, key string, fingerprint string, lockTTL time.Duration) (IdempotencyRecord, bool, error)
+	// Complete stores the finished record for ttl.
+	Complete(ctx context.Context, key string, record IdempotencyRecord, ttl time.Duration) error
+	// Release drops the lock so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+type IdempotencyOption func(*IdempotencyConfig)
+
+type IdempotencyConfig struct {
+	TTL         time.Duration
+	LockTimeout time.Duration
+	Required    bool
+	// Store defaults to the app's IdempotencyStore, installed per request by
+	// IdempotencyStoreMiddleware, or a memory store of the route without one.
+	Store IdempotencyStore
+	// Scope separates keys of different callers, so no caller can replay
+	// another's response. It defaults to the CallerID of the authenticated
+	// principal, then the SessionCookie value, then the client IP for
+	// anonymous requests. Requests carrying a key but no scope are rejected
+	// with 401.
+	Scope func(c fiber.Ctx) string
+	// SessionCookie scopes keys of requests without a principal. Defaults to
+	// DefaultIdempotencySessionCookie.
+	SessionCookie string
+}
+
+// WithIdempotencyStore sets where the route's records are kept instead of the
+// app's IdempotencyStore.
+func WithIdempotencyStore(store IdempotencyStore) IdempotencyOption {
+	return func(c *IdempotencyConfig) {
+		c.Store = store
+	}
+}
+
+// WithIdempotencyRequired rejects requests without an Idempotency-Key header.
+func WithIdempotencyRequired() IdempotencyOption {
+	return func(c *IdempotencyConfig) {
+		c.Required = true
+	}
+}
+
+// WithIdempotencyLockTimeout bounds how long an unfinished request holds its
+// key, e.g. after the process crashed mid-request. The default is one minute.
+func WithIdempotencyLockTimeout(timeout time.Duration) IdempotencyOption {
+	return func(c *IdempotencyConfig) {
+		c.LockTimeout = timeout
+	}
+}
+
+// WithIdempotencyScope sets how keys are separated between callers. An empty
+// scope rejects the request.
+//
+// Usage: web.WithIdempotencyScope(func(c fiber.Ctx) string { return c.Get("X-Tenant-ID") })
+func WithIdempotencyScope(scope func(c fiber.Ctx) string) IdempotencyOption {
+	return func(c *IdempotencyConfig) {
+		c.Scope = scope
+	}
+}
+
+// WithIdempotencySessionCookie names the session cookie scoping keys of
+// requests without a principal.
+func WithIdempotencySessionCookie(name string) IdempotencyOption {
+	return func(c *IdempotencyConfig) {
+		c.SessionCookie = strings.TrimSpace(name)
+	}
+}
+
+// Idempotent returns a RouteOption that replays the first completed response
+// for retries carrying the same Idempotency-Key.
+//
+// Usage: r.Post("/orders", h.Create).With(web.Idempotent(24 * time.Hour))
+func Idempotent(ttl time.Duration, opts ...IdempotencyOption) RouteOption {
+	return func(b *RouteBuilder) *RouteBuilder {
+		return b.Idempotent(ttl, opts...)
+	}
+}
+
+// Idempotent stores the first completed response for each Idempotency-Key for
+// ttl and replays it for retries. Concurrent duplicates get 409 and reusing a
+// key for a different request gets 422. Failed requests (handler errors and
+// 5xx responses) release the key so they can be retried.
+func (b *RouteBuilder) Idempotent(ttl time.Duration, opts ...IdempotencyOption) *RouteBuilder {
+	cfg := resolveIdempotencyConfig(ttl, opts...)
+	route := b.method + " " + b.path
+
+	b.Middleware(idempotencyMiddleware(route, cfg))
+
+	b.addParameterMetadata("header", HeaderIdempotencyKey, "", cfg.Required, "Unique key that makes retries of this request safe", "")
+	b.ensureResponseMaps()
+	if cfg.Required {
+		b.addErrorResponseIfMissing(http.StatusBadRequest, "Bad request")
+	}
+	b.addErrorResponseIfMissing(http.StatusUnauthorized, "Unauthorized")
+	b.addErrorResponseIfMissing(http.StatusConflict, "Request in progress")
+	b.addErrorResponseIfMissing(http.StatusUnprocessableEntity, "Idempotency key reused")
+	b.finalize()
+	return b
+}
+
+func resolveIdempotencyConfig(ttl time.Duration, opts ...IdempotencyOption) IdempotencyConfig {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	cfg := IdempotencyConfig{
+		TTL:         ttl,
+		LockTimeout: time.Minute,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = time.Minute
+	}
+	if cfg.SessionCookie == "" {
+		cfg.SessionCookie = DefaultIdempotencySessionCookie
+	}
+	if cfg.Scope == nil {
+		cookie := cfg.SessionCookie
+		cfg.Scope = func(c fiber.Ctx) string {
+			if id := CallerID(c); id != "" {
+				return "caller:" + id
+			}
+			if session := c.Cookies(cookie); session != "" {
+				return "session:" + session
+			}
+			return "ip:" + c.IP()
+		}
+	}
+	return cfg
+}
+
+type idempotencyStoreKey struct{}
+
+// IdempotencyStoreMiddleware makes the DI-provided IdempotencyStore, memory or
+// x/redis, the store of idempotent routes without WithIdempotencyStore.
+type IdempotencyStoreMiddleware struct {
+	store IdempotencyStore
+}
+
+func NewIdempotencyStoreMiddleware(store IdempotencyStore) *IdempotencyStoreMiddleware {
+	return &IdempotencyStoreMiddleware{store: store}
+}
+
+func (m *IdempotencyStoreMiddleware) Handle(r Router) {
+	r.Use(m.Middleware)
+}
+
+func (m *IdempotencyStoreMiddleware) Middleware(c fiber.Ctx) error {
+	c.Locals(idempotencyStoreKey{}, m.store)
+	return c.Next()
+}
+
+func idempotencyStoreFrom(c fiber.Ctx, fallback func() IdempotencyStore) IdempotencyStore {
+	if store, ok := c.Locals(idempotencyStoreKey{}).(IdempotencyStore); ok && store != nil {
+		return store
+	}
+	return fallback()
+}
+
+func idempotencyMiddleware(route string, cfg IdempotencyConfig) RouteMiddleware {
+	storeFor := func(c fiber.Ctx) IdempotencyStore { return cfg.Store }
+	if cfg.Store == nil {
+		fallback := sync.OnceValue(func() IdempotencyStore { return NewMemoryIdempotencyStore() })
+		storeFor = func(c fiber.Ctx) IdempotencyStore { return idempotencyStoreFrom(c, fallback) }
+	}
+
+	return func(c fiber.Ctx, next func() error) error {
+		key := strings.TrimSpace(c.Get(HeaderIdempotencyKey))
+		if key == "" {
+			if cfg.Required {
+				return ErrIdempotencyKeyRequired
+			}
+			return next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return ErrIdempotencyKeyInvalid
+		}
+
+		scope := cfg.Scope(c)
+		if scope == "" {
+			return ErrIdempotencyNoCaller
+		}
+
+		ctx := c.Context()
+		store := storeFor(c)
+		storeKey := "idempotency:" + hashParts(route, scope, key)
+		fingerprint := requestFingerprint(c)
+
+		record, acquired, err := store.Acquire(ctx, storeKey, fingerprint, cfg.LockTimeout)
+		if err != nil {
+			return err
+		}
+		if !acquired {
+			switch {
+			case record.Fingerprint != fingerprint:
+				return ErrIdempotencyKeyReused
+			case !record.Completed:
+				return ErrIdempotencyInFlight
+			}
+			return replayIdempotentResponse(c, record.Response)
+		}
+
+		if err := next(); err != nil {
+			_ = store.Release(ctx, storeKey)
+			return err
+		}
+		if c.Response().StatusCode() >= http.StatusInternalServerError {
+			_ = store.Release(ctx, storeKey)
+			return nil
+		}
+
+		record = IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Response:    captureIdempotentResponse(c),
+		}
+		if err := store.Complete(ctx, storeKey, record, cfg.TTL); err != nil {
+			_ = store.Release(ctx, storeKey)
+		}
+		return nil
+	}
+}
+
+// idempotencySkippedHeaders are recomputed for every response.
+var idempotencySkippedHeaders = map[string]struct{}{
+	fiber.HeaderContentLength: {},
+	fiber.HeaderDate:          {},
+	fiber.HeaderServer:        {},
+	fiber.HeaderConnection:    {},
+	fiber.HeaderXRequestID:    {},
+	HeaderRateLimitLimit:      {},
+	HeaderRateLimitRemaining:  {},
+	HeaderRateLimitReset:      {},
+	HeaderRateLimitPolicy:     {},
+}
+
+func captureIdempotentResponse(c fiber.Ctx) IdempotentResponse {
+	res := c.Response()
+	header := make(map[string][]string)
+	for k, v := range res.Header.All() {
+		name := http.CanonicalHeaderKey(string(k))
+		if _, skip := idempotencySkippedHeaders[name]; skip {
+			continue
+		}
+		header[name] = append(header[name], string(v))
+	}
+	return IdempotentResponse{
+		Status: res.StatusCode(),
+		Header: header,
+		Body:   bytes.Clone(res.Body()),
+	}
+}
+
+func replayIdempotentResponse(c fiber.Ctx, stored IdempotentResponse) error {
+	for name, values := range stored.Header {
+		if strings.EqualFold(name, fiber.HeaderContentType) {
+			if len(values) > 0 {
+				c.Response().Header.SetContentType(values[0])
+			}
+			continue
+		}
+		c.Response().Header.Del(name)
+		for _, v := range values {
+			c.Response().Header.Add(name, v)
+		}
+	}
+	c.Set(HeaderIdempotentReplayed, "true")
+	c.Status(stored.Status)
+	return c.Send(stored.Body)
+}
+
+func requestFingerprint(c fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.OriginalURL()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Get(fiber.HeaderContentType)))
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,201 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func newIdempotencyTestApp(t *testing.T, handler fiber.Handler, opts ...IdempotencyOption) (*fiber.App, *MetadataRegistry) {
+	t.Helper()
+
+	errorHandler := NewErrorHandler(Config{}, NewErrorRegistry())
+	app := fiber.New(fiber.Config{ErrorHandler: errorHandler.HandleError})
+	registry := NewMetadataRegistry()
+	NewRouterWithRegistry(app, registry).Post("/orders", handler).Idempotent(time.Hour, opts...)
+	return app, registry
+}
+
+func newOrderRequest(key, body string) *http.Request {
+	return newSessionOrderRequest("s1", key, body)
+}
+
+func newSessionOrderRequest(session, key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", ContentTypeApplicationJSON)
+	if session != "" {
+		req.AddCookie(&http.Cookie{Name: DefaultIdempotencySessionCookie, Value: session})
+	}
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	return req
+}
+
+func TestIdempotentReplaysFirstResponse(t *testing.T) {
+	var calls atomic.Int32
+	app, registry := newIdempotencyTestApp(t, func(c fiber.Ctx) error {
+		n := calls.Add(1)
+		c.Set("Location", "/orders/1")
+		return c.Status(http.StatusCreated).JSON(fiber.Map{"call": n})
+	})
+
+	first, firstBody := doErrorRequest(t, app, newOrderRequest("k1", `{"sku":"a"}`))
+	second, secondBody := doErrorRequest(t, app, newOrderRequest("k1", `{"sku":"a"}`))
+
+	if calls.Load() != 1 {
+		t.Fatalf("handler calls: got=%d want=1", calls.Load())
+	}
+	if first.StatusCode != http.StatusCreated || second.StatusCode != http.StatusCreated {
+		t.Fatalf("status: first=%d second=%d", first.StatusCode, second.StatusCode)
+	}
+	if string(firstBody) != string(secondBody) {
+		t.Fatalf("body: first=%s second=%s", firstBody, secondBody)
+	}
+	if second.Header.Get("Location") != "/orders/1" || second.Header.Get(HeaderIdempotentReplayed) != "true" {
+		t.Fatalf("unexpected replay headers: %v", second.Header)
+	}
+	if ct := second.Header.Get("Content-Type"); !strings.HasPrefix(ct, ContentTypeApplicationJSON) {
+		t.Fatalf("content type: got=%q", ct)
+	}
+
+	meta := registry.GetRoute(http.MethodPost, "/orders")
+	found := false
+	for _, p := range meta.Parameters {
+		if p.In == "header" && p.Name == HeaderIdempotencyKey {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected Idempotency-Key header parameter: %+v", meta.Parameters)
+	}
+	for _, status := range []int{http.StatusConflict, http.StatusUnprocessableEntity} {
+		if _, ok := meta.Responses[status]; !ok {
+			t.Fatalf("expected %d response metadata", status)
+		}
+	}
+}
+
+func TestIdempotentRejectsDifferentRequestWithSameKey(t *testing.T) {
+	app, _ := newIdempotencyTestApp(t, func(c fiber.Ctx) error {
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	doErrorRequest(t, app, newOrderRequest("k1", `{"sku":"a"}`))
+	res, body := doErrorRequest(t, app, newOrderRequest("k1", `{"sku":"b"}`))
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusUnprocessableEntity)
+	}
+	if got := decodeErrorBody(t, body); got.Error.Code != "IDEMPOTENCY_KEY_REUSED" {
+		t.Fatalf("unexpected error body: %+v", got)
+	}
+}
+
+func TestIdempotentRejectsConcurrentDuplicate(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	release := make(chan struct{})
+	started := make(chan struct{})
+	app, _ := newIdempotencyTestApp(t, func(c fiber.Ctx) error {
+		close(started)
+		<-release
+		return c.SendStatus(http.StatusCreated)
+	}, WithIdempotencyStore(store))
+
+	done := make(chan int, 1)
+	go func() {
+		res, err := app.Test(newOrderRequest("k1", `{}`))
+		if err != nil {
+			done <- 0
+			return
+		}
+		done <- res.StatusCode
+	}()
+	<-started
+
+	res, body := doErrorRequest(t, app, newOrderRequest("k1", `{}`))
+	close(release)
+	if res.StatusCode != http.StatusConflict {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusConflict)
+	}
+	if got := decodeErrorBody(t, body); got.Error.Code != "IDEMPOTENCY_KEY_IN_USE" {
+		t.Fatalf("unexpected error body: %+v", got)
+	}
+	if status := <-done; status != http.StatusCreated {
+		t.Fatalf("first request status: got=%d want=%d", status, http.StatusCreated)
+	}
+}
+
+func TestIdempotentReleasesKeyOnFailure(t *testing.T) {
+	var calls atomic.Int32
+	app, _ := newIdempotencyTestApp(t, func(c fiber.Ctx) error {
+		if calls.Add(1) == 1 {
+			return NewError(http.StatusServiceUnavailable, "", "try again")
+		}
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	if res, _ := doErrorRequest(t, app, newOrderRequest("k1", `{}`)); res.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusServiceUnavailable)
+	}
+	if res, _ := doErrorRequest(t, app, newOrderRequest("k1", `{}`)); res.StatusCode != http.StatusCreated {
+		t.Fatalf("retry status: got=%d want=%d", res.StatusCode, http.StatusCreated)
+	}
+}
+
+func TestIdempotentRequiredKey(t *testing.T) {
+	app, _ := newIdempotencyTestApp(t, func(c fiber.Ctx) error {
+		return c.SendStatus(http.StatusCreated)
+	}, WithIdempotencyRequired())
+
+	res, body := doErrorRequest(t, app, newOrderRequest("", `{}`))
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusBadRequest)
+	}
+	if got := decodeErrorBody(t, body); got.Error.Code != "IDEMPOTENCY_KEY_REQUIRED" {
+		t.Fatalf("unexpected error body: %+v", got)
+	}
+}
+
+func TestIdempotentScopesKeysByCaller(t *testing.T) {
+	var calls atomic.Int32
+	errorHandler := NewErrorHandler(Config{}, NewErrorRegistry())
+	app := fiber.New(fiber.Config{ErrorHandler: errorHandler.HandleError})
+	app.Use(func(c fiber.Ctx) error {
+		if user := c.Get("X-Test-User"); user != "" {
+			SetCallerID(c, "user:"+user)
+		}
+		return c.Next()
+	})
+	NewRouterWithRegistry(app, NewMetadataRegistry()).Post("/orders", func(c fiber.Ctx) error {
+		return c.Status(http.StatusCreated).JSON(fiber.Map{"call": calls.Add(1)})
+	}).Idempotent(time.Hour)
+
+	asUser := func(user string) *http.Request {
+		req := newSessionOrderRequest("", "k1", `{}`)
+		req.Header.Set("X-Test-User", user)
+		return req
+	}
+	_, alice := doErrorRequest(t, app, asUser("alice"))
+	_, bob := doErrorRequest(t, app, asUser("bob"))
+	_, session := doErrorRequest(t, app, newSessionOrderRequest("s1", "k1", `{}`))
+	_, other := doErrorRequest(t, app, newSessionOrderRequest("s2", "k1", `{}`))
+	if calls.Load() != 4 {
+		t.Fatalf("handler calls: got=%d want=4 (alice=%s bob=%s session=%s other=%s)", calls.Load(), alice, bob, session, other)
+	}
+	if _, replay := doErrorRequest(t, app, asUser("alice")); string(replay) != string(alice) {
+		t.Fatalf("replay: got=%s want=%s", replay, alice)
+	}
+
+	res, anonymous := doErrorRequest(t, app, newSessionOrderRequest("", "k1", `{}`))
+	if res.StatusCode != http.StatusCreated || calls.Load() != 5 {
+		t.Fatalf("anonymous request must be scoped by client IP: status=%d body=%s", res.StatusCode, anonymous)
+	}
+	if _, replay := doErrorRequest(t, app, newSessionOrderRequest("", "k1", `{}`)); string(replay) != string(anonymous) {
+		t.Fatalf("anonymous replay: got=%s want=%s", replay, anonymous)
+	}
+}
@@ -0,0 +1,423 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// FilterOp is a comparison a ListQuery filter applies to a field.
+type FilterOp string
+
+const (
+	FilterEq   FilterOp = "eq"
+	FilterNe   FilterOp = "ne"
+	FilterGt   FilterOp = "gt"
+	FilterGte  FilterOp = "gte"
+	FilterLt   FilterOp = "lt"
+	FilterLte  FilterOp = "lte"
+	FilterIn   FilterOp = "in"
+	FilterLike FilterOp = "like"
+)
+
+const maxFilterInValues = 100
+
+// likeEscaper escapes with "!", which unlike "\" means nothing inside a
+// MySQL string literal, so ESCAPE '!' parses on every dialect.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+var ErrInvalidListQuery = NewError(http.StatusBadRequest, "INVALID_LIST_QUERY", "invalid sort or filter parameters")
+
+var filterOpSQL = map[FilterOp]string{
+	FilterEq:   "=",
+	FilterNe:   "<>",
+	FilterGt:   ">",
+	FilterGte:  ">=",
+	FilterLt:   "<",
+	FilterLte:  "<=",
+	FilterIn:   "IN",
+	FilterLike: "LIKE",
+}
+
+// SortField is one parsed sort= entry.
+type SortField struct {
+	Field  string
+	Column string
+	Desc   bool
+}
+
+// Filter is one parsed filter[field][op]= parameter. Value has the field's
+// Go kind (string, int64, uint64, float64, bool or time.Time); for FilterIn
+// it is a []any of those.
+type Filter struct {
+	Field  string
+	Column string
+	Op     FilterOp
+	Value  any
+}
+
+// ListQuery is the sort and filter parameters of a list request, checked
+// against the filter type T.
+//
+// T declares what clients may use with struct tags. The parameter name comes
+// from the query or json tag and the column from the column or gorm column
+// tag, falling back to the name:
+//
+//	type OrderFilter struct {
+//		Status    string    `json:"status" filter:"eq,in" validate:"omitempty,oneof=pending paid"`
+//		Total     float64   `json:"total" column:"total_amount" filter:"gte,lte" sort:"true"`
+//		CreatedAt time.Time `json:"created_at" filter:"gte,lt" sort:"true"`
+//	}
+//
+// Requests then use ?sort=-created_at,total&filter[status][in]=pending,paid.
+// filter[field]=value is shorthand for the eq operator.
+type ListQuery[T any] struct {
+	Sort    []SortField
+	Filters []Filter
+}
+
+// ParseListQuery reads sort and filter parameters from the request. Unknown
+// fields, operators or values fail with ErrInvalidListQuery listing each
+// problem.
+//
+// Usage:
+//
+//	q, err := web.ParseListQuery[OrderFilter](c)
+//	if err != nil {
+//		return err
+//	}
+//	err = db.Scopes(xgorm.ListScope(q)).Find(&orders).Error
+func ParseListQuery[T any](c fiber.Ctx) (ListQuery[T], error) {
+	spec := listSpecFor(reflect.TypeFor[T]())
+	var (
+		q       ListQuery[T]
+		details []string
+	)
+
+	for key, value := range c.Request().URI().QueryArgs().All() {
+		name := string(key)
+		switch {
+		case name == "sort":
+			for entry := range strings.SplitSeq(string(value), ",") {
+				entry = strings.TrimSpace(entry)
+				if entry == "" {
+					continue
+				}
+				field, desc := strings.CutPrefix(entry, "-")
+				f, ok := spec.fields[field]
+				if !ok || !f.sortable {
+					details = append(details, fmt.Sprintf("sort: %q is not sortable", field))
+					continue
+				}
+				q.Sort = append(q.Sort, SortField{Field: field, Column: f.column, Desc: desc})
+			}
+		case strings.HasPrefix(name, "filter["):
+			filter, err := spec.parseFilter(name, string(value))
+			if err != nil {
+				details = append(details, err.Error())
+				continue
+			}
+			q.Filters = append(q.Filters, filter)
+		}
+	}
+
+	if len(details) > 0 {
+		return ListQuery[T]{}, ErrInvalidListQuery.WithDetails(details...)
+	}
+	return q, nil
+}
+
+// Where renders the filters as an AND-ed SQL condition. placeholder renders
+// the n-th (1-based) bind argument, e.g. "?" or "$n". It returns "" when
+// there are no filters. Like values match literally: %, _ and ! are escaped
+// with !.
+func (q ListQuery[T]) Where(placeholder func(n int) string) (string, []any) {
+	var (
+		terms []string
+		args  []any
+	)
+	for _, filter := range q.Filters {
+		if values, ok := filter.Value.([]any); ok {
+			binds := make([]string, len(values))
+			for i, value := range values {
+				args = append(args, value)
+				binds[i] = placeholder(len(args))
+			}
+			terms = append(terms, filter.Column+" IN ("+strings.Join(binds, ", ")+")")
+			continue
+		}
+		if filter.Op == FilterLike {
+			args = append(args, "%"+likeEscaper.Replace(fmt.Sprint(filter.Value))+"%")
+			terms = append(terms, filter.Column+" LIKE "+placeholder(len(args))+" ESCAPE '!'")
+			continue
+		}
+		args = append(args, filter.Value)
+		terms = append(terms, filter.Column+" "+filterOpSQL[filter.Op]+" "+placeholder(len(args)))
+	}
+	return strings.Join(terms, " AND "), args
+}
+
+// OrderBy renders the sort fields as an ORDER BY list, or "" when unsorted.
+func (q ListQuery[T]) OrderBy() string {
+	parts := make([]string, len(q.Sort))
+	for i, sort := range q.Sort {
+		if sort.Desc {
+			parts[i] = sort.Column + " DESC"
+		} else {
+			parts[i] = sort.Column + " ASC"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ListParams documents the sort and filter parameters of filter type T.
+//
+// Usage: r.Get("/orders", h.List).With(web.ListParams[OrderFilter]())
+func ListParams[T any]() RouteOption {
+	var zero T
+	return func(b *RouteBuilder) *RouteBuilder {
+		return b.ListQuery(zero)
+	}
+}
+
+// ListQuery documents the sort and filter parameters of filter's type, with
+// allowed sort values and oneof values as enums.
+func (b *RouteBuilder) ListQuery(filter any) *RouteBuilder {
+	spec := listSpecFor(reflect.TypeOf(filter))
+
+	var sortValues []any
+	for _, name := range spec.order {
+		f := spec.fields[name]
+		if f.sortable {
+			sortValues = append(sortValues, name, "-"+name)
+		}
+		for _, op := range f.ops {
+			param := ParameterMetadata{
+				Name:        "filter[" + name + "][" + string(op) + "]",
+				In:          "query",
+				Type:        f.typ,
+				Description: fmt.Sprintf("Filter %s by %s", name, op),
+			}
+			switch op {
+			case FilterIn:
+				param.Type = reflect.TypeFor[string]()
+				param.Description = fmt.Sprintf("Filter %s by any of comma separated values", name)
+			case FilterLike:
+				param.Type = reflect.TypeFor[string]()
+				param.Description = fmt.Sprintf("Filter %s containing the value", name)
+			}
+			if op == FilterEq || op == FilterNe {
+				param.Enum = f.enum
+			}
+			b.putParameter(param)
+		}
+	}
+	if len(sortValues) > 0 {
+		b.putParameter(ParameterMetadata{
+			Name:        "sort",
+			In:          "query",
+			Type:        reflect.TypeFor[[]string](),
+			Description: "Comma separated sort fields; prefix with - for descending",
+			Enum:        sortValues,
+		})
+	}
+	b.ensureResponseMaps()
+	b.addErrorResponseIfMissing(400, "Invalid list query")
+	b.finalize()
+	return b
+}
+
+type listSpec struct {
+	fields map[string]listField
+	order  []string
+}
+
+type listField struct {
+	column   string
+	typ      reflect.Type
+	ops      []FilterOp
+	sortable bool
+	enum     []any
+}
+
+var listSpecs sync.Map // reflect.Type -> *listSpec
+
+func listSpecFor(t reflect.Type) *listSpec {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if cached, ok := listSpecs.Load(t); ok {
+		return cached.(*listSpec)
+	}
+
+	spec := &listSpec{fields: make(map[string]listField)}
+	if t != nil && t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := listFieldName(field)
+			if name == "" {
+				continue
+			}
+			f := listField{
+				column:   listFieldColumn(field, name),
+				typ:      field.Type,
+				sortable: field.Tag.Get("sort") == "true",
+				enum:     listFieldEnum(field),
+			}
+			for op := range strings.SplitSeq(field.Tag.Get("filter"), ",") {
+				op := FilterOp(strings.TrimSpace(op))
+				if _, ok := filterOpSQL[op]; ok && !slices.Contains(f.ops, op) {
+					f.ops = append(f.ops, op)
+				}
+			}
+			if len(f.ops) == 0 && !f.sortable {
+				continue
+			}
+			spec.fields[name] = f
+			spec.order = append(spec.order, name)
+		}
+	}
+
+	actual, _ := listSpecs.LoadOrStore(t, spec)
+	return actual.(*listSpec)
+}
+
+func (s *listSpec) parseFilter(key, raw string) (Filter, error) {
+	inner := strings.TrimPrefix(key, "filter[")
+	name, rest, ok := strings.Cut(inner, "]")
+	if !ok {
+		return Filter{}, fmt.Errorf("%s: malformed filter parameter", key)
+	}
+	op := FilterEq
+	if rest != "" {
+		if !strings.HasPrefix(rest, "[") || !strings.HasSuffix(rest, "]") {
+			return Filter{}, fmt.Errorf("%s: malformed filter parameter", key)
+		}
+		op = FilterOp(rest[1 : len(rest)-1])
+	}
+
+	f, ok := s.fields[name]
+	if !ok || len(f.ops) == 0 {
+		return Filter{}, fmt.Errorf("filter: %q is not filterable", name)
+	}
+	if !slices.Contains(f.ops, op) {
+		return Filter{}, fmt.Errorf("filter[%s]: operator %q is not allowed", name, op)
+	}
+
+	filter := Filter{Field: name, Column: f.column, Op: op}
+	switch op {
+	case FilterLike:
+		filter.Value = raw
+	case FilterIn:
+		parts := strings.Split(raw, ",")
+		if len(parts) > maxFilterInValues {
+			return Filter{}, fmt.Errorf("filter[%s][in]: at most %d values", name, maxFilterInValues)
+		}
+		values := make([]any, 0, len(parts))
+		for _, part := range parts {
+			value, err := f.parse(strings.TrimSpace(part))
+			if err != nil {
+				return Filter{}, fmt.Errorf("filter[%s][in]: %w", name, err)
+			}
+			values = append(values, value)
+		}
+		filter.Value = values
+	default:
+		value, err := f.parse(raw)
+		if err != nil {
+			return Filter{}, fmt.Errorf("filter[%s][%s]: %w", name, op, err)
+		}
+		filter.Value = value
+	}
+	return filter, nil
+}
+
+func (f listField) parse(raw string) (any, error) {
+	if len(f.enum) > 0 && !slices.Contains(f.enum, any(raw)) {
+		return nil, fmt.Errorf("%q is not one of %v", raw, f.enum)
+	}
+
+	t := f.typ
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeFor[time.Time]() {
+		for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+			if v, err := time.Parse(layout, raw); err == nil {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not an RFC 3339 time or date", raw)
+	}
+
+	var (
+		value any
+		err   error
+	)
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		value, err = strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err = strconv.ParseInt(raw, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err = strconv.ParseUint(raw, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		value, err = strconv.ParseFloat(raw, t.Bits())
+	default:
+		return raw, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid %s", raw, t.Kind())
+	}
+	return value, nil
+}
+
+func listFieldName(field reflect.StructField) string {
+	for _, key := range []string{"query", "json"} {
+		tag := field.Tag.Get(key)
+		if tag == "-" {
+			return ""
+		}
+		if name, _, _ := strings.Cut(tag, ","); name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func listFieldColumn(field reflect.StructField, name string) string {
+	if column := strings.TrimSpace(field.Tag.Get("column")); column != "" {
+		return column
+	}
+	for part := range strings.SplitSeq(field.Tag.Get("gorm"), ";") {
+		if column, ok := strings.CutPrefix(strings.TrimSpace(part), "column:"); ok && column != "" {
+			return column
+		}
+	}
+	return name
+}
+
+func listFieldEnum(field reflect.StructField) []any {
+	for rule := range strings.SplitSeq(field.Tag.Get("validate"), ",") {
+		if values, ok := strings.CutPrefix(strings.TrimSpace(rule), "oneof="); ok {
+			var out []any
+			for _, v := range strings.Fields(values) {
+				out = append(out, v)
+			}
+			return out
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type listOrderFilter struct {
+	Status    string    `json:"status" filter:"eq,in" validate:"omitempty,oneof=pending paid"`
+	Total     float64   `json:"total" column:"total_amount" filter:"gte,lte" sort:"true"`
+	CreatedAt time.Time `json:"created_at" filter:"gte" sort:"true"`
+	Customer  string    `json:"customer" gorm:"column:customer_name" filter:"like"`
+	Internal  string    `json:"internal"`
+}
+
+func TestParseListQueryBuildsSQL(t *testing.T) {
+	app := newErrorHandlerTestApp(t, Config{})
+	var got ListQuery[listOrderFilter]
+	app.Get("/orders", func(c fiber.Ctx) error {
+		q, err := ParseListQuery[listOrderFilter](c)
+		if err != nil {
+			return err
+		}
+		got = q
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	target := "/orders?sort=-created_at,total&filter[status][in]=pending,paid&filter[total][gte]=10.5" +
+		"&filter[customer][like]=a%25n_n!&filter[created_at][gte]=2026-01-02"
+	res, body := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, target, nil))
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("status=%d body=%s", res.StatusCode, body)
+	}
+
+	where, args := got.Where(func(int) string { return "?" })
+	wantWhere := "status IN (?, ?) AND total_amount >= ? AND customer_name LIKE ? ESCAPE '!' AND created_at >= ?"
+	if where != wantWhere {
+		t.Fatalf("where:\n got=%s\nwant=%s", where, wantWhere)
+	}
+	wantArgs := []any{"pending", "paid", 10.5, "%a!%n!_n!!%", time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Fatalf("args: got=%#v want=%#v", args, wantArgs)
+	}
+	if order := got.OrderBy(); order != "created_at DESC, total_amount ASC" {
+		t.Fatalf("order by: %s", order)
+	}
+}
+
+func TestParseListQueryRejectsUnlistedInput(t *testing.T) {
+	app := newErrorHandlerTestApp(t, Config{})
+	app.Get("/orders", func(c fiber.Ctx) error {
+		_, err := ParseListQuery[listOrderFilter](c)
+		return err
+	})
+
+	target := "/orders?sort=internal&filter[internal]=x&filter[status][gte]=paid&filter[status]=shipped&filter[total][lte]=abc"
+	res, body := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, target, nil))
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status=%d body=%s", res.StatusCode, body)
+	}
+	detail := decodeErrorBody(t, body).Error
+	if detail.Code != "INVALID_LIST_QUERY" || len(detail.Details) != 5 {
+		t.Fatalf("error: %+v", detail)
+	}
+	for i, want := range []string{"not sortable", "not filterable", `operator "gte"`, `"shipped" is not one of`, "not a valid float64"} {
+		if !strings.Contains(detail.Details[i], want) {
+			t.Fatalf("detail %d: got=%q want substring %q", i, detail.Details[i], want)
+		}
+	}
+}
+
+func TestRouteBuilderListQueryDocumentsParams(t *testing.T) {
+	registry := NewMetadataRegistry()
+	router := NewRouterWithRegistry(fiber.New(), registry)
+	router.Get("/orders", func(c fiber.Ctx) error { return nil }).With(ListParams[listOrderFilter]())
+
+	meta := registry.GetRoute(http.MethodGet, "/orders")
+	params := map[string]ParameterMetadata{}
+	for _, p := range meta.Parameters {
+		params[p.Name] = p
+	}
+	for _, name := range []string{"filter[status][eq]", "filter[status][in]", "filter[total][gte]", "filter[customer][like]", "sort"} {
+		if _, ok := params[name]; !ok {
+			t.Fatalf("missing parameter %s in %v", name, meta.Parameters)
+		}
+	}
+	if got := params["filter[status][eq]"].Enum; !reflect.DeepEqual(got, []any{"pending", "paid"}) {
+		t.Fatalf("status enum: %v", got)
+	}
+	if got := params["sort"].Enum; !reflect.DeepEqual(got, []any{"total", "-total", "created_at", "-created_at"}) {
+		t.Fatalf("sort enum: %v", got)
+	}
+	if _, ok := params["filter[internal][eq]"]; ok {
+		t.Fatal("untagged field documented")
+	}
+}
+
+func TestRouteBuilderListQueryDoesNotDuplicateParams(t *testing.T) {
+	registry := NewMetadataRegistry()
+	router := NewRouterWithRegistry(fiber.New(), registry)
+	router.Get("/orders", func(c fiber.Ctx) error { return nil }).
+		With(ListParams[listOrderFilter](), ListParams[listOrderFilter]())
+
+	seen := map[string]bool{}
+	for _, p := range registry.GetRoute(http.MethodGet, "/orders").Parameters {
+		key := p.In + ":" + p.Name
+		if seen[key] {
+			t.Fatalf("duplicate parameter %s", key)
+		}
+		seen[key] = true
+	}
+}
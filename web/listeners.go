@@ -0,0 +1,142 @@
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// extraListener is a web.listeners entry bound before the server starts.
+type extraListener struct {
+	config   ListenerConfig
+	ln       net.Listener
+	redirect *fasthttp.Server
+}
+
+func validateListeners(config Config) error {
+	hasTLS := strings.TrimSpace(config.TLS.CertFile) != ""
+	for i, listener := range config.Listeners {
+		if strings.TrimSpace(listener.Address) == "" {
+			return fmt.Errorf("listeners[%d]: address is required", i)
+		}
+		if listener.TLS && !hasTLS {
+			return fmt.Errorf("listeners[%d]: tls requires web.tls cert_file and cert_key_file", i)
+		}
+		if listener.TLS && listener.RedirectHTTPS {
+			return fmt.Errorf("listeners[%d]: tls and redirect_https are mutually exclusive", i)
+		}
+		if listener.RedirectHTTPS && !hasTLS {
+			return fmt.Errorf("listeners[%d]: redirect_https requires web.tls", i)
+		}
+	}
+	if len(config.Listeners) > 0 && config.Listen.EnablePrefork {
+		return fmt.Errorf("listeners are not supported with enable_prefork")
+	}
+	return nil
+}
+
+// openListeners binds every web.listeners entry, so address conflicts fail
+// the start instead of surfacing later in a goroutine.
+func (s *FiberServer) openListeners(tlsConfig *tls.Config) ([]*extraListener, error) {
+	out := make([]*extraListener, 0, len(s.WebConfig.Listeners))
+	for _, config := range s.WebConfig.Listeners {
+		network := strings.TrimSpace(config.Network)
+		if network == "" {
+			network = s.WebConfig.Listen.ListenerNetwork
+		}
+		if network == "" {
+			network = "tcp"
+		}
+		address := strings.TrimSpace(config.Address)
+		if network == "unix" {
+			if err := os.Remove(address); err != nil && !os.IsNotExist(err) {
+				closeListeners(out)
+				return nil, fmt.Errorf("remove unix socket %q: %w", address, err)
+			}
+		}
+
+		ln, err := net.Listen(network, address)
+		if err != nil {
+			closeListeners(out)
+			return nil, fmt.Errorf("listen %s %s: %w", network, address, err)
+		}
+		if config.TLS {
+			ln = tls.NewListener(ln, tlsConfig)
+		}
+		extra := &extraListener{config: config, ln: ln}
+		if config.RedirectHTTPS {
+			port := config.RedirectPort
+			if port == 0 {
+				port = s.WebConfig.Server.Port
+			}
+			extra.redirect = &fasthttp.Server{
+				Handler:               httpsRedirectHandler(port),
+				NoDefaultServerHeader: true,
+			}
+		}
+		out = append(out, extra)
+	}
+	return out, nil
+}
+
+// serveListeners serves the extra listeners once the app is started. Routes
+// are served by the app's own fasthttp server, so App.Shutdown stops them.
+func (s *FiberServer) serveListeners(listeners []*extraListener) {
+	for _, extra := range listeners {
+		s.Obs.Info("fiber server listening",
+			zap.String("address", extra.ln.Addr().String()),
+			zap.String("network", extra.ln.Addr().Network()),
+			zap.Bool("tls", extra.config.TLS),
+			zap.Bool("redirect_https", extra.config.RedirectHTTPS),
+		)
+		go func() {
+			var err error
+			if extra.redirect != nil {
+				err = extra.redirect.Serve(extra.ln)
+			} else {
+				err = s.App.Server().Serve(extra.ln)
+			}
+			if err != nil && !errors.Is(err, net.ErrClosed) {
+				s.Obs.Error("fiber listener stopped with error", zap.String("address", extra.config.Address), zap.Error(err))
+			}
+		}()
+	}
+}
+
+// shutdownRedirects stops redirect listeners, which run outside the app.
+func shutdownRedirects(ctx context.Context, listeners []*extraListener) error {
+	var errs []error
+	for _, extra := range listeners {
+		if extra.redirect != nil {
+			errs = append(errs, extra.redirect.ShutdownWithContext(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func closeListeners(listeners []*extraListener) {
+	for _, extra := range listeners {
+		_ = extra.ln.Close()
+	}
+}
+
+func httpsRedirectHandler(port int) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		host := string(ctx.Host())
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != 0 && port != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(port))
+		}
+		ctx.Redirect("https://"+host+string(ctx.RequestURI()), fasthttp.StatusPermanentRedirect)
+	}
+}
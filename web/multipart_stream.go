@@ -0,0 +1,259 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+var (
+	// ErrInvalidMultipart is returned when a multipart body cannot be parsed.
+	ErrInvalidMultipart = NewError(http.StatusBadRequest, "INVALID_MULTIPART", "invalid multipart body")
+	// ErrMultipartLimit is returned when a multipart body has too many parts
+	// or a part larger than its limit.
+	ErrMultipartLimit = NewError(http.StatusRequestEntityTooLarge, "MULTIPART_LIMIT", "multipart limit exceeded")
+	// ErrUnsupportedFileType is returned when a file part's sniffed content
+	// type is not allowed for its field.
+	ErrUnsupportedFileType = NewError(http.StatusUnsupportedMediaType, "UNSUPPORTED_FILE_TYPE", "unsupported file type")
+)
+
+const (
+	defaultMultipartMaxFiles     = 10
+	defaultMultipartMaxFields    = 100
+	defaultMultipartMaxFieldSize = 1 << 20
+	defaultMultipartMaxFileSize  = 32 << 20
+	multipartSniffLen            = 512
+)
+
+// MultipartLimits bounds what ReadMultipart accepts. Zero values use the
+// defaults: 10 files, 100 fields of at most 1MiB each, and files of at most
+// 32MiB.
+type MultipartLimits struct {
+	// Files lists the accepted file fields. When empty, files are accepted in
+	// any field with the Default limit.
+	Files        map[string]MultipartFileLimit
+	Default      MultipartFileLimit
+	MaxFiles     int
+	MaxFields    int
+	MaxFieldSize int64
+}
+
+// MultipartFileLimit bounds the files of one field. MaxCount defaults to 1 and
+// AllowedTypes accepts wildcards such as "image/*".
+type MultipartFileLimit struct {
+	MaxSize      int64
+	MaxCount     int
+	AllowedTypes []string
+}
+
+// MultipartFile is a file part read by ReadMultipart. It reads the part body
+// straight from the request and fails with ErrMultipartLimit once it passes
+// the field's MaxSize.
+type MultipartFile struct {
+	Field    string
+	Filename string
+	// ContentType is sniffed from the first 512 bytes and is what
+	// AllowedTypes is checked against.
+	ContentType string
+	// DeclaredContentType is the type sent by the client. It is kept as
+	// metadata only and must not be trusted.
+	DeclaredContentType string
+	Header              textproto.MIMEHeader
+
+	r io.Reader
+}
+
+func (f *MultipartFile) Read(p []byte) (int, error) {
+	return f.r.Read(p)
+}
+
+// MultipartStream documents a multipart/form-data body read with
+// ReadMultipart, along with the errors its limits return. Like StreamBody, it
+// keeps a streamed body unbuffered up to the route's BodyLimit.
+func (b *RouteBuilder) MultipartStream(requestType any) *RouteBuilder {
+	b.StreamBody()
+	b.Multipart(requestType)
+	b.ensureResponseMaps()
+	b.addErrorResponseIfMissing(http.StatusBadRequest, "Invalid multipart body")
+	b.addErrorResponseIfMissing(http.StatusRequestEntityTooLarge, "Multipart limit exceeded")
+	b.addErrorResponseIfMissing(http.StatusUnsupportedMediaType, "Unsupported file type")
+	return b
+}
+
+// ReadMultipart parses a multipart/form-data body part by part and calls
+// onFile for each file part, in request order. Only one part is held at a
+// time, so memory stays bounded when the body is streamed, either with
+// fiber.app.stream_request_body or under a route BodyLimit; otherwise fiber
+// has already buffered the body. Parts left unread by onFile
+// are drained. It returns the text fields.
+func ReadMultipart(c fiber.Ctx, limits MultipartLimits, onFile func(file *MultipartFile) error) (map[string][]string, error) {
+	_, params, err := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	if err != nil || params["boundary"] == "" {
+		return nil, ErrInvalidMultipart.WithDetails("content type must be multipart/form-data with a boundary")
+	}
+
+	reader := multipart.NewReader(RequestBody(c), params["boundary"])
+
+	limits = limits.withDefaults()
+	fields := map[string][]string{}
+	counts := map[string]int{}
+	files, values := 0, 0
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return fields, nil
+		}
+		if err != nil {
+			return nil, ErrInvalidMultipart.WithDetails(err.Error())
+		}
+
+		name := part.FormName()
+		if part.FileName() == "" {
+			values++
+			if values > limits.MaxFields {
+				return nil, ErrMultipartLimit.WithDetails(fmt.Sprintf("more than %d fields", limits.MaxFields))
+			}
+			value, err := io.ReadAll(&limitedPartReader{r: part, n: limits.MaxFieldSize, field: name})
+			if err != nil {
+				return nil, multipartReadError(err)
+			}
+			fields[name] = append(fields[name], string(value))
+			continue
+		}
+
+		limit, ok := limits.file(name)
+		if !ok {
+			return nil, ErrInvalidMultipart.WithDetails(fmt.Sprintf("unexpected file field %q", name))
+		}
+		files++
+		counts[name]++
+		if files > limits.MaxFiles {
+			return nil, ErrMultipartLimit.WithDetails(fmt.Sprintf("more than %d files", limits.MaxFiles))
+		}
+		if counts[name] > limit.MaxCount {
+			return nil, ErrMultipartLimit.WithDetails(fmt.Sprintf("more than %d files in %q", limit.MaxCount, name))
+		}
+
+		file, err := newMultipartFile(part, limit)
+		if err != nil {
+			return nil, err
+		}
+		if err := onFile(file); err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(io.Discard, file); err != nil {
+			return nil, multipartReadError(err)
+		}
+	}
+}
+
+func newMultipartFile(part *multipart.Part, limit MultipartFileLimit) (*MultipartFile, error) {
+	r := &limitedPartReader{r: part, n: limit.MaxSize, field: part.FormName()}
+	head := make([]byte, multipartSniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, multipartReadError(err)
+	}
+	head = head[:n]
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	declared, _, _ := mime.ParseMediaType(part.Header.Get(fiber.HeaderContentType))
+	if !mediaTypeAllowed(contentType, limit.AllowedTypes) {
+		return nil, ErrUnsupportedFileType.WithDetails(fmt.Sprintf("%s is not allowed in %q", contentType, part.FormName()))
+	}
+	return &MultipartFile{
+		Field:               part.FormName(),
+		Filename:            part.FileName(),
+		ContentType:         contentType,
+		DeclaredContentType: declared,
+		Header:              part.Header,
+		r:                   io.MultiReader(bytes.NewReader(head), r),
+	}, nil
+}
+
+func mediaTypeAllowed(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "*/*" || pattern == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func multipartReadError(err error) error {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return err
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return err
+	}
+	return ErrInvalidMultipart.WithDetails(err.Error())
+}
+
+func (l MultipartLimits) withDefaults() MultipartLimits {
+	if l.MaxFiles <= 0 {
+		l.MaxFiles = defaultMultipartMaxFiles
+	}
+	if l.MaxFields <= 0 {
+		l.MaxFields = defaultMultipartMaxFields
+	}
+	if l.MaxFieldSize <= 0 {
+		l.MaxFieldSize = defaultMultipartMaxFieldSize
+	}
+	return l
+}
+
+func (l MultipartLimits) file(field string) (MultipartFileLimit, bool) {
+	limit := l.Default
+	if len(l.Files) > 0 {
+		var ok bool
+		if limit, ok = l.Files[field]; !ok {
+			return MultipartFileLimit{}, false
+		}
+	}
+	if limit.MaxSize <= 0 {
+		limit.MaxSize = defaultMultipartMaxFileSize
+	}
+	if limit.MaxCount <= 0 {
+		limit.MaxCount = 1
+	}
+	return limit, true
+}
+
+type limitedPartReader struct {
+	r     io.Reader
+	n     int64
+	field string
+}
+
+func (l *limitedPartReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, ErrMultipartLimit.WithDetails(fmt.Sprintf("%q is too large", l.field))
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n + int(l.n), ErrMultipartLimit.WithDetails(fmt.Sprintf("%q is too large", l.field))
+	}
+	return n, err
+}
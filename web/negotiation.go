@@ -0,0 +1,363 @@
+package web
+
+import (
+	"mime"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+var (
+	// ErrNotAcceptable is returned when no offered format matches Accept.
+	ErrNotAcceptable = NewError(http.StatusNotAcceptable, "NOT_ACCEPTABLE", "none of the accepted media types can be produced")
+	// ErrUnsupportedMediaType is returned when no codec decodes the request's
+	// Content-Type.
+	ErrUnsupportedMediaType = NewError(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", "unsupported request content type")
+	// ErrInvalidBody is returned when a codec cannot decode the request body.
+	ErrInvalidBody = NewError(http.StatusBadRequest, "INVALID_BODY", "invalid request body")
+)
+
+type codecsKey struct{}
+type negotiatedRouteKey struct{}
+
+var defaultCodecs = sync.OnceValue(func() *Codecs { return NewCodecs() })
+
+// Codecs maps media types to codecs.
+type Codecs struct {
+	codecs []Codec
+	byType map[string]Codec
+}
+
+// NewCodecs returns DefaultCodecs plus codecs. A codec replaces earlier ones
+// serving the same media types.
+func NewCodecs(codecs ...Codec) *Codecs {
+	c := &Codecs{byType: make(map[string]Codec)}
+	for _, codec := range append(DefaultCodecs(), codecs...) {
+		if codec == nil || len(codec.MediaTypes()) == 0 {
+			continue
+		}
+		primary := strings.ToLower(codec.MediaTypes()[0])
+		if i := slices.IndexFunc(c.codecs, func(existing Codec) bool {
+			return strings.ToLower(existing.MediaTypes()[0]) == primary
+		}); i >= 0 {
+			c.codecs[i] = codec
+		} else {
+			c.codecs = append(c.codecs, codec)
+		}
+		for _, mediaType := range codec.MediaTypes() {
+			c.byType[strings.ToLower(mediaType)] = codec
+		}
+	}
+	return c
+}
+
+// Lookup returns the codec serving contentType; parameters are ignored.
+func (c *Codecs) Lookup(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	codec, ok := c.byType[mediaType]
+	return codec, ok
+}
+
+// MediaTypes returns the primary media type of every codec.
+func (c *Codecs) MediaTypes() []string {
+	out := make([]string, 0, len(c.codecs))
+	for _, codec := range c.codecs {
+		out = append(out, codec.MediaTypes()[0])
+	}
+	return out
+}
+
+// Negotiate picks the codec and media type for accept among offers, which
+// default to every codec. Offers whose codec cannot encode t are skipped.
+// Ties go to the earlier offer.
+func (c *Codecs) Negotiate(accept string, offers []string, t reflect.Type) (Codec, string, bool) {
+	if len(offers) == 0 {
+		offers = c.MediaTypes()
+	}
+	ranges := parseAccept(accept)
+
+	var best Codec
+	bestType, bestQ := "", 0.0
+	for _, offer := range offers {
+		codec, ok := c.Lookup(offer)
+		if !ok {
+			continue
+		}
+		if typed, ok := codec.(TypedCodec); ok && t != nil && !typed.CanMarshal(t) {
+			continue
+		}
+		// A client may ask for an alias, such as application/x-msgpack.
+		for _, mediaType := range append([]string{offer}, codec.MediaTypes()...) {
+			q, exact := acceptQuality(ranges, strings.ToLower(mediaType))
+			if q > bestQ {
+				best, bestQ, bestType = codec, q, offer
+				if exact {
+					bestType = mediaType
+				}
+			}
+		}
+	}
+	return best, bestType, best != nil
+}
+
+// Respond encodes v in the format negotiated from Accept. Offers are the
+// content types the route documents for status, or every codec when it
+// documents none. It fails with ErrNotAcceptable when nothing matches.
+//
+// Usage: return web.Respond(c, http.StatusOK, orders)
+func Respond(c fiber.Ctx, status int, v any) error {
+	meta, _ := c.Locals(negotiatedRouteKey{}).(*RouteMetadata)
+	return respond(c, status, v, meta)
+}
+
+func respond(c fiber.Ctx, status int, v any, meta *RouteMetadata) error {
+	codecs := codecsFrom(c)
+	offers := responseOffers(meta, status, codecs)
+	c.Vary(fiber.HeaderAccept)
+
+	codec, mediaType, ok := codecs.Negotiate(c.Get(fiber.HeaderAccept), offers, reflect.TypeOf(v))
+	if !ok {
+		if len(offers) == 0 {
+			offers = codecs.MediaTypes()
+		}
+		return ErrNotAcceptable.WithDetails("available: " + strings.Join(offers, ", "))
+	}
+	if _, ok := codec.(JSONCodec); ok {
+		// Keep the app's JSONEncoder for JSON.
+		return c.Status(status).JSON(v)
+	}
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.Status(status)
+	c.Set(fiber.HeaderContentType, mediaType)
+	return c.Send(data)
+}
+
+// DecodeBody decodes the request body with the codec for its Content-Type,
+// for bodies bound outside typed handlers, such as a CSV list.
+//
+// Usage: var rows []ImportRow; err := web.DecodeBody(c, &rows)
+func DecodeBody(c fiber.Ctx, out any) error {
+	contentType := c.Get(fiber.HeaderContentType)
+	codec, ok := codecsFrom(c).Lookup(contentType)
+	if !ok {
+		return ErrUnsupportedMediaType.WithDetails("unsupported content type " + strconv.Quote(contentType))
+	}
+	if err := codec.Unmarshal(c.Body(), out); err != nil {
+		return ErrInvalidBody.WithDetails(err.Error())
+	}
+	return nil
+}
+
+func codecsFrom(c fiber.Ctx) *Codecs {
+	if codecs, ok := c.Locals(codecsKey{}).(*Codecs); ok && codecs != nil {
+		return codecs
+	}
+	return defaultCodecs()
+}
+
+// responseOffers lists the documented content types for status that a codec
+// can produce, the primary content type first.
+func responseOffers(meta *RouteMetadata, status int, codecs *Codecs) []string {
+	if meta == nil {
+		return nil
+	}
+	resp, ok := meta.Responses[status]
+	if !ok {
+		return nil
+	}
+	var offers []string
+	if _, ok := codecs.Lookup(resp.ContentType); ok {
+		offers = append(offers, resp.ContentType)
+	}
+	others := make([]string, 0, len(resp.Content))
+	for contentType := range resp.Content {
+		if contentType == resp.ContentType {
+			continue
+		}
+		if _, ok := codecs.Lookup(contentType); ok {
+			others = append(others, contentType)
+		}
+	}
+	sort.Strings(others)
+	return append(offers, others...)
+}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+func parseAccept(accept string) []acceptRange {
+	if strings.TrimSpace(accept) == "" {
+		return []acceptRange{{mediaType: "*/*", q: 1}}
+	}
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+	return ranges
+}
+
+// acceptQuality returns the q of the most specific range matching mediaType
+// and whether that range named it exactly.
+func acceptQuality(ranges []acceptRange, mediaType string) (float64, bool) {
+	q, specificity := 0.0, -1
+	major, _, _ := strings.Cut(mediaType, "/")
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.mediaType == mediaType:
+			s = 2
+		case r.mediaType == major+"/*":
+			s = 1
+		case r.mediaType == "*/*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q, specificity == 2
+}
+
+// Formats returns a RouteOption that serves and documents the route's
+// responses in contentTypes as well.
+// Usage: .With(web.Formats(web.ContentTypeApplicationXML, web.ContentTypeTextCSV))
+func Formats(contentTypes ...string) RouteOption {
+	return func(b *RouteBuilder) *RouteBuilder {
+		return b.Formats(contentTypes...)
+	}
+}
+
+// Formats documents every 2xx response body, declared before or after, in
+// contentTypes as well, and lets Respond negotiate between them. A JSON
+// request body declared before is documented in contentTypes too, since
+// ContentNegotiation decodes them. CSV is only added to lists.
+func (b *RouteBuilder) Formats(contentTypes ...string) *RouteBuilder {
+	b.formats = append(b.formats, contentTypes...)
+	b.ensureResponseMaps()
+	for status, resp := range b.metadata.Responses {
+		b.addFormats(status, resp.Type)
+	}
+	if body := b.metadata.RequestBody; body != nil && body.Type != nil {
+		if _, ok := body.Content[ContentTypeApplicationJSON]; ok {
+			formats := make([]string, 0, len(contentTypes))
+			for _, contentType := range contentTypes {
+				if contentType != ContentTypeTextCSV || (CSVCodec{}).CanMarshal(body.Type) {
+					formats = append(formats, contentType)
+				}
+			}
+			b.setRequestBody(reflect.New(body.Type).Elem().Interface(), body.Required, body.RequireAtLeastOne, formats...)
+		}
+	}
+	if !b.negotiated {
+		b.negotiated = true
+		meta := b.metadata
+		b.Middleware(func(c fiber.Ctx, next func() error) error {
+			c.Locals(negotiatedRouteKey{}, meta)
+			return next()
+		})
+	}
+	b.finalize()
+	return b
+}
+
+func (b *RouteBuilder) addFormats(status int, t reflect.Type) {
+	if status < 200 || status > 299 || t == nil {
+		return
+	}
+	resp := b.metadata.Responses[status]
+	if resp.NoContent {
+		return
+	}
+	if resp.Content == nil {
+		resp.Content = make(map[string]reflect.Type)
+	}
+	for _, contentType := range b.formats {
+		if contentType == ContentTypeTextCSV && !(CSVCodec{}).CanMarshal(t) {
+			continue
+		}
+		if _, exists := resp.Content[contentType]; !exists {
+			resp.Content[contentType] = t
+		}
+	}
+	b.metadata.Responses[status] = resp
+}
+
+// ContentNegotiation decodes request bodies with the codecs of the
+// CodecsGroupName group, through fiber's binder, and makes them available to
+// Respond and DecodeBody.
+type ContentNegotiation struct {
+	codecs *Codecs
+}
+
+func NewContentNegotiation(server *FiberServer, codecs ...Codec) *ContentNegotiation {
+	n := &ContentNegotiation{codecs: NewCodecs(codecs...)}
+	for _, codec := range n.codecs.codecs {
+		// Fiber binds JSON with the app's JSONDecoder already.
+		if _, ok := codec.(JSONCodec); ok {
+			continue
+		}
+		server.App.RegisterCustomBinder(codecBinder{codec: codec})
+	}
+	return n
+}
+
+// Codecs returns the registered codecs.
+func (n *ContentNegotiation) Codecs() *Codecs {
+	return n.codecs
+}
+
+func (n *ContentNegotiation) Handle(r Router) {
+	r.Use(n.Middleware)
+}
+
+func (n *ContentNegotiation) Middleware(c fiber.Ctx) error {
+	c.Locals(codecsKey{}, n.codecs)
+	return c.Next()
+}
+
+type codecBinder struct {
+	codec Codec
+}
+
+func (b codecBinder) Name() string {
+	return b.codec.MediaTypes()[0]
+}
+
+func (b codecBinder) MIMETypes() []string {
+	out := make([]string, 0, len(b.codec.MediaTypes()))
+	for _, mediaType := range b.codec.MediaTypes() {
+		out = append(out, strings.ToLower(mediaType))
+	}
+	return out
+}
+
+func (b codecBinder) Parse(c fiber.Ctx, out any) error {
+	if err := b.codec.Unmarshal(c.Body(), out); err != nil {
+		return ErrInvalidBody.WithDetails(err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package web
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type negotiationItem struct {
+	XMLName xml.Name `json:"-" xml:"item"`
+	ID      int      `json:"id" xml:"id"`
+	Name    string   `json:"name" xml:"name"`
+}
+
+type negotiationCreateItem struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func TestRespondNegotiatesDeclaredFormats(t *testing.T) {
+	app, router, registry := newEndpointTestApp(t)
+	items := []negotiationItem{{ID: 1, Name: "tea"}, {ID: 2, Name: "milk, oat"}}
+	router.Get("/items").With(Handle(func(ctx context.Context, _ endpointListOrders) ([]negotiationItem, error) {
+		return items, nil
+	}), Formats(ContentTypeApplicationXML, ContentTypeTextCSV))
+	router.Get("/items/one").With(Handle(func(ctx context.Context, _ endpointListOrders) (negotiationItem, error) {
+		return items[0], nil
+	}), Formats(ContentTypeApplicationXML, ContentTypeTextCSV))
+
+	meta := registry.GetRoute(http.MethodGet, "/items/one")
+	if meta == nil {
+		t.Fatal("missing route metadata")
+	}
+	if _, ok := meta.Responses[http.StatusOK].Content[ContentTypeTextCSV]; ok {
+		t.Fatal("csv must only be documented for list responses")
+	}
+	if _, ok := meta.Responses[http.StatusOK].Content[ContentTypeApplicationXML]; !ok {
+		t.Fatalf("xml must be documented, got %v", meta.Responses[http.StatusOK].Content)
+	}
+
+	cases := []struct {
+		path, accept, contentType, body string
+		status                          int
+	}{
+		{"/items", "", ContentTypeApplicationJSON, `[{"id":1,"name":"tea"},{"id":2,"name":"milk, oat"}]`, http.StatusOK},
+		{"/items", "text/csv", ContentTypeTextCSV, "id,name\n1,tea\n2,\"milk, oat\"\n", http.StatusOK},
+		{"/items/one", "application/json;q=0.5, application/xml", ContentTypeApplicationXML, "<item><id>1</id><name>tea</name></item>", http.StatusOK},
+		{"/items/one", "text/csv", "", "", http.StatusNotAcceptable},
+		{"/items", "application/cbor", "", "", http.StatusNotAcceptable},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.accept != "" {
+			req.Header.Set("Accept", tc.accept)
+		}
+		res, body := doErrorRequest(t, app, req)
+		if res.StatusCode != tc.status {
+			t.Fatalf("%s %q: status=%d body=%s", tc.path, tc.accept, res.StatusCode, body)
+		}
+		if tc.status != http.StatusOK {
+			if decodeErrorBody(t, body).Error.Code != "NOT_ACCEPTABLE" {
+				t.Fatalf("%s %q: body=%s", tc.path, tc.accept, body)
+			}
+			continue
+		}
+		if got := res.Header.Get("Content-Type"); !strings.HasPrefix(got, tc.contentType) {
+			t.Fatalf("%s %q: content type %q", tc.path, tc.accept, got)
+		}
+		if strings.TrimSpace(string(body)) != strings.TrimSpace(tc.body) {
+			t.Fatalf("%s %q: body=%q", tc.path, tc.accept, body)
+		}
+		if res.Header.Get("Vary") != "Accept" {
+			t.Fatalf("%s %q: vary=%q", tc.path, tc.accept, res.Header.Get("Vary"))
+		}
+	}
+}
+
+func TestContentNegotiationDecodesRequestBodies(t *testing.T) {
+	server := NewFiberServer(Config{}, FiberConfig{})
+	registry := NewMetadataRegistry()
+	router := NewRouterWithRegistry(server.App, registry)
+	negotiation := NewContentNegotiation(server)
+	negotiation.Handle(router)
+
+	router.Post("/items").With(Handle(func(ctx context.Context, req negotiationCreateItem) (negotiationItem, error) {
+		return negotiationItem{ID: 7, Name: req.Name}, nil
+	}), Formats(ContentTypeApplicationVndMsgpack))
+	router.Post("/items/import", func(c fiber.Ctx) error {
+		var rows []negotiationItem
+		if err := DecodeBody(c, &rows); err != nil {
+			return err
+		}
+		return Respond(c, http.StatusOK, rows)
+	})
+
+	meta := registry.GetRoute(http.MethodPost, "/items")
+	if _, ok := meta.RequestBody.Content[ContentTypeApplicationVndMsgpack]; !ok {
+		t.Fatalf("msgpack request body must be documented, got %v", meta.RequestBody.ContentTypes)
+	}
+
+	msgpack := MsgpackCodec{}
+	payload, err := msgpack.Marshal(negotiationCreateItem{Name: "tea"})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(string(payload)))
+	req.Header.Set("Content-Type", "application/x-msgpack")
+	req.Header.Set("Accept", "application/x-msgpack")
+	res, body := doErrorRequest(t, server.App, req)
+	if res.StatusCode != http.StatusCreated || res.Header.Get("Content-Type") != "application/x-msgpack" {
+		t.Fatalf("msgpack: status=%d content type=%q body=%q", res.StatusCode, res.Header.Get("Content-Type"), body)
+	}
+	var created negotiationItem
+	if err := msgpack.Unmarshal(body, &created); err != nil || created.ID != 7 || created.Name != "tea" {
+		t.Fatalf("msgpack response: %+v err=%v", created, err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/items/import", strings.NewReader("name,id\ntea,1\nmilk,2\n"))
+	req.Header.Set("Content-Type", ContentTypeTextCSV)
+	res, body = doErrorRequest(t, server.App, req)
+	if res.StatusCode != http.StatusOK || string(body) != `[{"id":1,"name":"tea"},{"id":2,"name":"milk"}]` {
+		t.Fatalf("csv import: status=%d body=%s", res.StatusCode, body)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/items/import", strings.NewReader("id: 1"))
+	req.Header.Set("Content-Type", "application/yaml")
+	res, body = doErrorRequest(t, server.App, req)
+	if res.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("yaml import: status=%d body=%s", res.StatusCode, body)
+	}
+}
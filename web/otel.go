@@ -9,6 +9,7 @@ import (
 	fiberzap "github.com/gofiber/contrib/v3/zap"
 	"github.com/gofiber/fiber/v3"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -47,6 +48,9 @@ func (h *OtelMiddleware) WrapObserver(c fiber.Ctx) error {
 	ctx, span := h.Obs.Start(c.Context(), fmt.Sprintf("%s %s", c.Method(), c.Path()))
 	defer span.End()
 
+	if requestID := otel.RequestIDFromContext(ctx); requestID != "" {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request.id", requestID))
+	}
 	c.SetContext(ctx)
 
 	err := c.Next()
@@ -5,6 +5,7 @@ import (
 
 	"github.com/bronystylecrazy/ultrastructure/cfg"
 	"github.com/bronystylecrazy/ultrastructure/di"
+	"github.com/bronystylecrazy/ultrastructure/lc"
 	"github.com/bronystylecrazy/ultrastructure/otel"
 )
 
@@ -16,6 +17,8 @@ func Providers(extends ...di.Node) di.Node {
 
 	nodes := []di.Node{
 		di.AutoGroup[FiberConfigurer](FiberConfigurersGroupName),
+		di.AutoGroup[ErrorMapper](ErrorMappersGroupName),
+		di.AutoGroup[Codec](CodecsGroupName),
 
 		cfg.Config[Config]("web", cfg.WithSourceFile("config.toml"), cfg.WithType("toml")),
 
@@ -25,14 +28,68 @@ func Providers(extends ...di.Node) di.Node {
 		di.Provide(NewRegistryLifecycle),
 		di.Provide(NewModuleRouter),
 
+		di.Provide(NewErrorRegistry, di.VariadicGroup(ErrorMappersGroupName)),
+		di.Provide(func(config Config) *CursorCodec { return NewCursorCodec(config.Pagination.CursorSecret) }),
+		di.Default(NewMemoryRateLimitStore, di.As[RateLimitStore]()),
+		di.Provide(NewRateLimitStoreMiddleware, Priority(Earliest)),
+		di.Default(NewMemoryIdempotencyStore, di.As[IdempotencyStore]()),
+		di.Provide(NewIdempotencyStoreMiddleware, Priority(Earliest)),
+		di.Provide(
+			NewVersionMiddleware,
+			Priority(math.MinInt32-3),
+		),
+		di.Provide(
+			NewErrorHandler,
+			// Recover ahead of every middleware so their panics are rendered too.
+			Priority(math.MinInt32-4), otel.Layer(OtelScope),
+		),
+		di.Provide(
+			NewRequestIDMiddleware,
+			Priority(math.MinInt32-1),
+		),
 		di.Provide(
 			NewOtelMiddleware,
 			Priority(math.MinInt32), otel.Layer(OtelScope),
 		),
+		di.Provide(
+			NewCORSMiddleware,
+			Priority(math.MinInt32+1),
+		),
+		di.Provide(
+			NewSecurityHeadersMiddleware,
+			Priority(math.MinInt32+2),
+		),
+		di.Provide(
+			NewCSRFMiddleware,
+			Priority(math.MinInt32+3),
+		),
+		di.Provide(
+			NewCompressionMiddleware,
+			Priority(math.MinInt32+4),
+		),
+		di.Provide(
+			NewConditionalGetMiddleware,
+			Priority(math.MinInt32+5),
+		),
+		di.Provide(
+			NewBodyLimitMiddleware,
+			Priority(math.MinInt32+6),
+		),
+		di.Provide(
+			NewContentNegotiation,
+			di.VariadicGroup(CodecsGroupName),
+			Priority(math.MinInt32+7),
+		),
 		di.Provide(
 			NewFiberServer,
 			di.VariadicGroup(FiberConfigurersGroupName),
 			di.AsSelf[Server](),
+			// Drain before every other stopper so requests can still use them.
+			lc.StopPriority(lc.Latest),
+		),
+		di.Provide(
+			func(server *FiberServer) *Drain { return server.Drain },
+			Priority(math.MinInt32-2),
 		),
 	}
 
@@ -0,0 +1,74 @@
+package web_test
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/di"
+	"github.com/bronystylecrazy/ultrastructure/ustest"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
+
+type panickingMiddleware struct{}
+
+func (*panickingMiddleware) Handle(r web.Router) {
+	r.Use(func(c fiber.Ctx) error {
+		if c.Get("X-Panic") != "" {
+			panic("middleware failed")
+		}
+		return c.Next()
+	})
+	r.Get("/ping", func(c fiber.Ctx) error { return c.SendString("pong") })
+}
+
+func TestRecoverRunsBeforeEveryMiddleware(t *testing.T) {
+	app := ustest.Start(t,
+		di.Provide(func() *panickingMiddleware { return &panickingMiddleware{} }, web.Priority(math.MinInt32)),
+		web.InitHandlers(),
+	)
+
+	app.HTTP().Get("/ping").Header("X-Panic", "1").Expect(http.StatusInternalServerError).JSONPath("error.code", "INTERNAL_SERVER_ERROR")
+	app.HTTP().Get("/ping").Expect(http.StatusOK)
+}
+
+type recordingRateLimitStore struct {
+	web.RateLimitStore
+	mu   sync.Mutex
+	keys []string
+}
+
+func (s *recordingRateLimitStore) Take(ctx context.Context, key string, rule web.RateLimitRule) (web.RateLimitDecision, error) {
+	s.mu.Lock()
+	s.keys = append(s.keys, key)
+	s.mu.Unlock()
+	return s.RateLimitStore.Take(ctx, key, rule)
+}
+
+type limitedHandler struct{}
+
+func (*limitedHandler) Handle(r web.Router) {
+	shared := web.WithRateLimitName("login")
+	r.Post("/login", func(c fiber.Ctx) error { return c.SendStatus(http.StatusNoContent) }).NoContent(http.StatusNoContent).RateLimit(2, time.Minute, shared)
+	r.Post("/login/otp", func(c fiber.Ctx) error { return c.SendStatus(http.StatusNoContent) }).NoContent(http.StatusNoContent).RateLimit(2, time.Minute, shared)
+}
+
+func TestRateLimitUsesAppStore(t *testing.T) {
+	store := &recordingRateLimitStore{RateLimitStore: web.NewMemoryRateLimitStore()}
+	app := ustest.Start(t,
+		di.Supply(store, di.As[web.RateLimitStore]()),
+		di.Provide(func() *limitedHandler { return &limitedHandler{} }),
+		web.InitHandlers(),
+	)
+
+	app.HTTP().Post("/login").Expect(http.StatusNoContent)
+	app.HTTP().Post("/login/otp").Expect(http.StatusNoContent)
+	app.HTTP().Post("/login").Expect(http.StatusTooManyRequests)
+	if len(store.keys) != 3 {
+		t.Fatalf("app store keys = %v", store.keys)
+	}
+}
@@ -7,6 +7,25 @@ type PageQuery struct {
 	Limit int `query:"limit" json:"limit"`
 }
 
+// CursorQuery binds keyset pagination parameters. Cursor is a token from a
+// previous page's next_cursor or prev_cursor.
+type CursorQuery struct {
+	Cursor string `query:"cursor" json:"cursor"`
+	Limit  int    `query:"limit" json:"limit"`
+}
+
+// Size returns Limit bounded to [1, maxLimit], or defaultLimit when unset.
+func (q CursorQuery) Size(defaultLimit, maxLimit int) int {
+	switch {
+	case q.Limit <= 0:
+		return defaultLimit
+	case maxLimit > 0 && q.Limit > maxLimit:
+		return maxLimit
+	default:
+		return q.Limit
+	}
+}
+
 type DateQuery struct {
 	FromDate time.Time `query:"from_date" json:"from_date"`
 	ToDate   time.Time `query:"to_date" json:"to_date"`
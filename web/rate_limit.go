@@ -0,0 +1,208 @@
+package web
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+)
+
+// ErrRateLimited is returned when a key has exhausted its rate limit.
+var ErrRateLimited = NewError(http.StatusTooManyRequests, "RATE_LIMITED", "rate limit exceeded")
+
+// RateLimitKeyFunc identifies the client a request is counted against.
+// An empty key falls back to the client IP.
+type RateLimitKeyFunc func(c fiber.Ctx) string
+
+// RateLimitByIP counts requests per client IP.
+func RateLimitByIP() RateLimitKeyFunc {
+	return func(c fiber.Ctx) string {
+		return c.IP()
+	}
+}
+
+// RateLimitByHeader counts requests per value of a request header.
+func RateLimitByHeader(name string) RateLimitKeyFunc {
+	return func(c fiber.Ctx) string {
+		return strings.TrimSpace(c.Get(name))
+	}
+}
+
+type RateLimitOption func(*RateLimitConfig)
+
+type RateLimitConfig struct {
+	Rule RateLimitRule
+	Key  RateLimitKeyFunc
+	// Store defaults to the app's RateLimitStore, installed per request by
+	// RateLimitStoreMiddleware, or a memory store of the route without one.
+	Store RateLimitStore
+	// Name scopes the counters. Routes sharing a name share their limits;
+	// by default every route is limited on its own.
+	Name string
+}
+
+// WithRateLimitAlgorithm selects the algorithm. The default is a fixed window.
+func WithRateLimitAlgorithm(algorithm RateLimitAlgorithm) RateLimitOption {
+	return func(c *RateLimitConfig) {
+		c.Rule.Algorithm = algorithm
+	}
+}
+
+// WithRateLimitBurst sets the token bucket capacity.
+func WithRateLimitBurst(burst int) RateLimitOption {
+	return func(c *RateLimitConfig) {
+		c.Rule.Burst = burst
+	}
+}
+
+// WithRateLimitKey sets how requests are attributed to clients.
+func WithRateLimitKey(key RateLimitKeyFunc) RateLimitOption {
+	return func(c *RateLimitConfig) {
+		c.Key = key
+	}
+}
+
+// WithRateLimitStore sets where the route's counters are kept instead of the
+// app's RateLimitStore.
+func WithRateLimitStore(store RateLimitStore) RateLimitOption {
+	return func(c *RateLimitConfig) {
+		c.Store = store
+	}
+}
+
+// WithRateLimitName shares counters between routes using the same name.
+func WithRateLimitName(name string) RateLimitOption {
+	return func(c *RateLimitConfig) {
+		c.Name = strings.TrimSpace(name)
+	}
+}
+
+// RateLimit returns a RouteOption that allows limit requests per window for
+// each client and answers the rest with 429.
+//
+// Usage: r.Post("/login", h.Login).With(web.RateLimit(5, time.Minute, web.WithRateLimitKey(web.RateLimitByIP())))
+func RateLimit(limit int, window time.Duration, opts ...RateLimitOption) RouteOption {
+	return func(b *RouteBuilder) *RouteBuilder {
+		return b.RateLimit(limit, window, opts...)
+	}
+}
+
+// RateLimit limits the route to limit requests per window for each client.
+func (b *RouteBuilder) RateLimit(limit int, window time.Duration, opts ...RateLimitOption) *RouteBuilder {
+	cfg := resolveRateLimitConfig(limit, window, opts...)
+	if cfg.Name == "" {
+		cfg.Name = b.method + " " + b.path
+	}
+
+	b.Middleware(rateLimitMiddleware(cfg))
+
+	b.ensureResponseMaps()
+	b.addErrorResponseIfMissing(http.StatusTooManyRequests, "Too many requests")
+	b.SetHeaders(http.StatusTooManyRequests, fiber.HeaderRetryAfter, 0, "Seconds to wait before retrying")
+	b.SetHeaders(http.StatusTooManyRequests, HeaderRateLimitLimit, 0, "Requests allowed per window")
+	b.SetHeaders(http.StatusTooManyRequests, HeaderRateLimitRemaining, 0, "Requests left in the current window")
+	b.SetHeaders(http.StatusTooManyRequests, HeaderRateLimitReset, 0, "Seconds until the limit resets")
+	return b
+}
+
+func resolveRateLimitConfig(limit int, window time.Duration, opts ...RateLimitOption) RateLimitConfig {
+	if limit <= 0 {
+		limit = 1
+	}
+	if window <= 0 {
+		window = time.Minute
+	}
+	cfg := RateLimitConfig{
+		Rule: RateLimitRule{
+			Algorithm: RateLimitFixedWindow,
+			Limit:     limit,
+			Window:    window,
+		},
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	if cfg.Key == nil {
+		cfg.Key = RateLimitByIP()
+	}
+	return cfg
+}
+
+type rateLimitStoreKey struct{}
+
+// RateLimitStoreMiddleware makes the DI-provided RateLimitStore, memory or
+// x/redis, the store of routes limited without WithRateLimitStore.
+type RateLimitStoreMiddleware struct {
+	store RateLimitStore
+}
+
+func NewRateLimitStoreMiddleware(store RateLimitStore) *RateLimitStoreMiddleware {
+	return &RateLimitStoreMiddleware{store: store}
+}
+
+func (m *RateLimitStoreMiddleware) Handle(r Router) {
+	r.Use(m.Middleware)
+}
+
+func (m *RateLimitStoreMiddleware) Middleware(c fiber.Ctx) error {
+	c.Locals(rateLimitStoreKey{}, m.store)
+	return c.Next()
+}
+
+func rateLimitStoreFrom(c fiber.Ctx, fallback func() RateLimitStore) RateLimitStore {
+	if store, ok := c.Locals(rateLimitStoreKey{}).(RateLimitStore); ok && store != nil {
+		return store
+	}
+	return fallback()
+}
+
+func rateLimitMiddleware(cfg RateLimitConfig) RouteMiddleware {
+	policy := strconv.Itoa(cfg.Rule.Capacity()) + ";w=" + strconv.Itoa(ceilSeconds(cfg.Rule.Window))
+	store := func(c fiber.Ctx) RateLimitStore { return cfg.Store }
+	if cfg.Store == nil {
+		fallback := sync.OnceValue(func() RateLimitStore { return NewMemoryRateLimitStore() })
+		store = func(c fiber.Ctx) RateLimitStore { return rateLimitStoreFrom(c, fallback) }
+	}
+
+	return func(c fiber.Ctx, next func() error) error {
+		key := cfg.Key(c)
+		if key == "" {
+			key = c.IP()
+		}
+
+		decision, err := store(c).Take(c.Context(), "ratelimit:"+cfg.Name+":"+key, cfg.Rule)
+		if err != nil {
+			return err
+		}
+
+		c.Set(HeaderRateLimitPolicy, policy)
+		c.Set(HeaderRateLimitLimit, strconv.Itoa(decision.Limit))
+		c.Set(HeaderRateLimitRemaining, strconv.Itoa(max(decision.Remaining, 0)))
+		c.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(decision.ResetAfter)))
+		if !decision.Allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(max(ceilSeconds(decision.RetryAfter), 1)))
+			return ErrRateLimited
+		}
+		return next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,198 @@
+package web
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type RateLimitAlgorithm string
+
+const (
+	RateLimitFixedWindow   RateLimitAlgorithm = "fixed_window"
+	RateLimitSlidingWindow RateLimitAlgorithm = "sliding_window"
+	RateLimitTokenBucket   RateLimitAlgorithm = "token_bucket"
+)
+
+// RateLimitRule describes how many requests a key may make per window.
+// For token buckets, Burst is the bucket capacity and Limit tokens are
+// refilled evenly over Window.
+type RateLimitRule struct {
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Window    time.Duration
+	Burst     int
+}
+
+// Capacity returns the most requests a key can make at once.
+func (r RateLimitRule) Capacity() int {
+	if r.Algorithm == RateLimitTokenBucket && r.Burst > 0 {
+		return r.Burst
+	}
+	return r.Limit
+}
+
+// RateLimitDecision is the outcome of consuming one request from a key.
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimitStore consumes requests for keys. Implementations must apply the
+// rule atomically so concurrent requests cannot exceed the limit.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, rule RateLimitRule) (RateLimitDecision, error)
+}
+
+type rateLimitEntry struct {
+	// window start for fixed and sliding windows, last refill for token buckets.
+	start    time.Time
+	count    float64
+	previous float64
+	expires  time.Time
+}
+
+// MemoryRateLimitStore keeps rate limit state in process memory. It is only
+// accurate for a single instance; use x/redis for shared limits.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		entries: make(map[string]*rateLimitEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, rule RateLimitRule) (RateLimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	entry := s.entries[key]
+	if entry == nil {
+		entry = &rateLimitEntry{}
+		s.entries[key] = entry
+	}
+
+	var decision RateLimitDecision
+	switch rule.Algorithm {
+	case RateLimitSlidingWindow:
+		decision = takeSlidingWindow(entry, rule, now)
+	case RateLimitTokenBucket:
+		decision = takeTokenBucket(entry, rule, now)
+	default:
+		decision = takeFixedWindow(entry, rule, now)
+	}
+	return decision, nil
+}
+
+// sweep drops expired keys at most once a minute.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if now.After(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func takeFixedWindow(e *rateLimitEntry, rule RateLimitRule, now time.Time) RateLimitDecision {
+	start := now.Truncate(rule.Window)
+	if !e.start.Equal(start) {
+		e.start = start
+		e.count = 0
+	}
+	e.expires = start.Add(rule.Window)
+
+	reset := e.expires.Sub(now)
+	decision := RateLimitDecision{Limit: rule.Limit, ResetAfter: reset}
+	if int(e.count) >= rule.Limit {
+		decision.RetryAfter = reset
+		return decision
+	}
+	e.count++
+	decision.Allowed = true
+	decision.Remaining = rule.Limit - int(e.count)
+	return decision
+}
+
+// takeSlidingWindow approximates a sliding log by weighting the previous
+// window's count by how much of it still overlaps the sliding window.
+func takeSlidingWindow(e *rateLimitEntry, rule RateLimitRule, now time.Time) RateLimitDecision {
+	start := now.Truncate(rule.Window)
+	switch {
+	case e.start.Equal(start):
+	case e.start.Add(rule.Window).Equal(start):
+		e.previous, e.count = e.count, 0
+		e.start = start
+	default:
+		e.previous, e.count = 0, 0
+		e.start = start
+	}
+	e.expires = start.Add(2 * rule.Window)
+
+	elapsed := now.Sub(start)
+	weight := float64(rule.Window-elapsed) / float64(rule.Window)
+	estimate := e.previous*weight + e.count
+	reset := rule.Window - elapsed
+
+	decision := RateLimitDecision{Limit: rule.Limit, ResetAfter: reset}
+	if estimate+1 > float64(rule.Limit) {
+		decision.RetryAfter = slidingWindowRetryAfter(e, rule, elapsed)
+		return decision
+	}
+	e.count++
+	decision.Allowed = true
+	decision.Remaining = int(math.Floor(float64(rule.Limit) - estimate - 1))
+	return decision
+}
+
+func slidingWindowRetryAfter(e *rateLimitEntry, rule RateLimitRule, elapsed time.Duration) time.Duration {
+	free := float64(rule.Limit) - 1 - e.count
+	if free < 0 || e.previous == 0 {
+		return rule.Window - elapsed
+	}
+	// The estimate drops below the limit once previous*weight <= free.
+	target := time.Duration(float64(rule.Window) * (1 - free/e.previous))
+	if target <= elapsed {
+		return 0
+	}
+	return target - elapsed
+}
+
+func takeTokenBucket(e *rateLimitEntry, rule RateLimitRule, now time.Time) RateLimitDecision {
+	capacity := float64(rule.Capacity())
+	perToken := rule.Window / time.Duration(rule.Limit)
+	if e.start.IsZero() {
+		e.count = capacity
+	} else if elapsed := now.Sub(e.start); elapsed > 0 {
+		e.count = math.Min(capacity, e.count+float64(elapsed)/float64(perToken))
+	}
+	e.start = now
+
+	decision := RateLimitDecision{Limit: int(capacity)}
+	if e.count >= 1 {
+		e.count--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = time.Duration((1 - e.count) * float64(perToken))
+	}
+	decision.Remaining = int(math.Floor(e.count))
+	decision.ResetAfter = time.Duration((capacity - e.count) * float64(perToken))
+	e.expires = now.Add(decision.ResetAfter)
+	return decision
+}
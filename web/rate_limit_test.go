@@ -0,0 +1,141 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func newRateLimitTestApp(t *testing.T, opts ...RouteOption) (*fiber.App, *MetadataRegistry) {
+	t.Helper()
+
+	handler := NewErrorHandler(Config{}, NewErrorRegistry())
+	app := fiber.New(fiber.Config{ErrorHandler: handler.HandleError})
+	registry := NewMetadataRegistry()
+	r := NewRouterWithRegistry(app, registry).With(opts...)
+	r.Get("/orders", func(c fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	return app, registry
+}
+
+func TestRateLimitRejectsWith429AndHeaders(t *testing.T) {
+	app, registry := newRateLimitTestApp(t, RateLimit(2, time.Hour))
+
+	for i := range 2 {
+		res, _ := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/orders", nil))
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("request %d status: got=%d want=%d", i, res.StatusCode, http.StatusOK)
+		}
+		if got := res.Header.Get(HeaderRateLimitLimit); got != "2" {
+			t.Fatalf("limit header: got=%q", got)
+		}
+	}
+
+	res, body := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusTooManyRequests)
+	}
+	if got := decodeErrorBody(t, body); got.Error.Code != "RATE_LIMITED" {
+		t.Fatalf("unexpected error body: %+v", got)
+	}
+	if res.Header.Get(fiber.HeaderRetryAfter) == "" || res.Header.Get(HeaderRateLimitRemaining) != "0" {
+		t.Fatalf("unexpected headers: %v", res.Header)
+	}
+	if got := res.Header.Get(HeaderRateLimitPolicy); got != "2;w=3600" {
+		t.Fatalf("policy header: got=%q", got)
+	}
+
+	meta := registry.GetRoute(http.MethodGet, "/orders")
+	if meta == nil {
+		t.Fatal("missing route metadata")
+	}
+	resp, ok := meta.Responses[http.StatusTooManyRequests]
+	if !ok {
+		t.Fatal("expected 429 response metadata")
+	}
+	if _, ok := resp.Headers[fiber.HeaderRetryAfter]; !ok {
+		t.Fatalf("expected Retry-After header metadata: %+v", resp.Headers)
+	}
+}
+
+func TestRateLimitKeysByHeader(t *testing.T) {
+	app, _ := newRateLimitTestApp(t, RateLimit(1, time.Hour, WithRateLimitKey(RateLimitByHeader("X-Client"))))
+
+	for _, client := range []string{"a", "b"} {
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		req.Header.Set("X-Client", client)
+		if res, _ := doErrorRequest(t, app, req); res.StatusCode != http.StatusOK {
+			t.Fatalf("client %s status: got=%d want=%d", client, res.StatusCode, http.StatusOK)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("X-Client", "a")
+	if res, _ := doErrorRequest(t, app, req); res.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusTooManyRequests)
+	}
+}
+
+func TestMemoryRateLimitStoreAlgorithms(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+
+	t.Run("fixed window resets on the next window", func(t *testing.T) {
+		rule := RateLimitRule{Algorithm: RateLimitFixedWindow, Limit: 2, Window: time.Minute}
+		for i := range 2 {
+			if d, _ := store.Take(ctx, "fixed", rule); !d.Allowed || d.Remaining != 1-i {
+				t.Fatalf("take %d: %+v", i, d)
+			}
+		}
+		if d, _ := store.Take(ctx, "fixed", rule); d.Allowed || d.RetryAfter != time.Minute {
+			t.Fatalf("expected rejection: %+v", d)
+		}
+		now = now.Add(time.Minute)
+		if d, _ := store.Take(ctx, "fixed", rule); !d.Allowed {
+			t.Fatalf("expected new window: %+v", d)
+		}
+	})
+
+	t.Run("sliding window weights the previous window", func(t *testing.T) {
+		rule := RateLimitRule{Algorithm: RateLimitSlidingWindow, Limit: 4, Window: time.Minute}
+		for range 4 {
+			if d, _ := store.Take(ctx, "sliding", rule); !d.Allowed {
+				t.Fatalf("expected allowed: %+v", d)
+			}
+		}
+		// Halfway into the next window half of the previous count still applies.
+		now = now.Add(90 * time.Second)
+		for range 2 {
+			if d, _ := store.Take(ctx, "sliding", rule); !d.Allowed {
+				t.Fatalf("expected allowed: %+v", d)
+			}
+		}
+		if d, _ := store.Take(ctx, "sliding", rule); d.Allowed || d.RetryAfter <= 0 {
+			t.Fatalf("expected rejection: %+v", d)
+		}
+	})
+
+	t.Run("token bucket refills over time", func(t *testing.T) {
+		rule := RateLimitRule{Algorithm: RateLimitTokenBucket, Limit: 10, Window: 10 * time.Second, Burst: 3}
+		for range 3 {
+			if d, _ := store.Take(ctx, "bucket", rule); !d.Allowed {
+				t.Fatalf("expected allowed: %+v", d)
+			}
+		}
+		d, _ := store.Take(ctx, "bucket", rule)
+		if d.Allowed || d.RetryAfter != time.Second {
+			t.Fatalf("expected rejection with 1s retry: %+v", d)
+		}
+		now = now.Add(time.Second)
+		if d, _ := store.Take(ctx, "bucket", rule); !d.Allowed || d.Limit != 3 {
+			t.Fatalf("expected refilled token: %+v", d)
+		}
+	})
+}
@@ -3,12 +3,18 @@ package web
 // RegistryContainer groups mutable runtime registries for web routing metadata.
 // It is DI-provided and activated by web.Module lc.
 type RegistryContainer struct {
-	Metadata *MetadataRegistry
+	Metadata   *MetadataRegistry
+	CORS       *CORSRegistry
+	BodyLimits *BodyLimitRegistry
+	Versions   *VersionRegistry
 }
 
 // NewRegistryContainer creates a fresh registry set.
 func NewRegistryContainer() *RegistryContainer {
 	return &RegistryContainer{
-		Metadata: NewMetadataRegistry(),
+		Metadata:   NewMetadataRegistry(),
+		CORS:       NewCORSRegistry(),
+		BodyLimits: NewBodyLimitRegistry(),
+		Versions:   NewVersionRegistry(),
 	}
 }
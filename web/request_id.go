@@ -0,0 +1,111 @@
+package web
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const HeaderRequestID = fiber.HeaderXRequestID
+
+const requestIDLocalsKey = "us.web.request_id"
+
+// maxRequestIDLength bounds ids accepted from clients so they cannot bloat logs.
+const maxRequestIDLength = 128
+
+type RequestIDConfig struct {
+	// Header carries the request id in both directions. Defaults to X-Request-ID.
+	Header string `mapstructure:"header" default:"X-Request-ID"`
+	// IgnoreIncoming always generates a new id instead of trusting the client.
+	IgnoreIncoming bool `mapstructure:"ignore_incoming" default:"false"`
+	// Disabled skips the request id middleware.
+	Disabled bool `mapstructure:"disabled" default:"false"`
+}
+
+// RequestIDMiddleware accepts or generates a request id for every request,
+// stores it in the fiber ctx and context.Context and echoes it back.
+//
+// It runs before OtelMiddleware so access logs, spans and error responses all
+// carry the same id.
+type RequestIDMiddleware struct {
+	config   RequestIDConfig
+	generate func() string
+}
+
+func NewRequestIDMiddleware(config Config) *RequestIDMiddleware {
+	return &RequestIDMiddleware{
+		config:   config.RequestID,
+		generate: uuid.NewString,
+	}
+}
+
+func (m *RequestIDMiddleware) Handle(r Router) {
+	if m.config.Disabled {
+		return
+	}
+	r.Use(m.Middleware)
+}
+
+func (m *RequestIDMiddleware) Middleware(c fiber.Ctx) error {
+	header := strings.TrimSpace(m.config.Header)
+	if header == "" {
+		header = HeaderRequestID
+	}
+
+	requestID := ""
+	if !m.config.IgnoreIncoming {
+		requestID = sanitizeRequestID(c.Get(header))
+	}
+	if requestID == "" {
+		requestID = m.generate()
+	}
+
+	SetRequestID(c, requestID)
+	c.Set(header, requestID)
+	return c.Next()
+}
+
+// SetRequestID stores id in the fiber locals and the request context and tags
+// the active span with it.
+func SetRequestID(c fiber.Ctx, requestID string) {
+	c.Locals(requestIDLocalsKey, requestID)
+	ctx := otel.WithRequestID(c.Context(), requestID)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request.id", requestID))
+	c.SetContext(ctx)
+}
+
+// RequestID returns the id of the current request, or "" when none was assigned.
+func RequestID(c fiber.Ctx) string {
+	if requestID, ok := c.Locals(requestIDLocalsKey).(string); ok {
+		return requestID
+	}
+	return otel.RequestIDFromContext(c.Context())
+}
+
+// RequestIDFromContext returns the request id carried by ctx.
+func RequestIDFromContext(ctx context.Context) string {
+	return otel.RequestIDFromContext(ctx)
+}
+
+// WithRequestID returns a copy of ctx carrying requestID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return otel.WithRequestID(ctx, requestID)
+}
+
+func sanitizeRequestID(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" || len(value) > maxRequestIDLength {
+		return ""
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < 0x21 || value[i] > 0x7e {
+			return ""
+		}
+	}
+	return value
+}
@@ -0,0 +1,87 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	"github.com/gofiber/fiber/v3"
+)
+
+func newRequestIDTestApp(t *testing.T, config Config) *fiber.App {
+	t.Helper()
+
+	handler := NewErrorHandler(config, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: handler.HandleError})
+	app.Use(NewRequestIDMiddleware(config).Middleware)
+	app.Get("/id", func(c fiber.Ctx) error {
+		return c.SendString(RequestID(c) + "|" + otel.RequestIDFromContext(c.Context()))
+	})
+	app.Get("/fail", func(c fiber.Ctx) error {
+		return NewError(http.StatusConflict, "ORDER_CLOSED", "order closed")
+	})
+	return app
+}
+
+func TestRequestIDMiddlewareAcceptsIncomingID(t *testing.T) {
+	app := newRequestIDTestApp(t, Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/id", nil)
+	req.Header.Set(HeaderRequestID, "req-123")
+	res, body := doErrorRequest(t, app, req)
+	if got := res.Header.Get(HeaderRequestID); got != "req-123" {
+		t.Fatalf("response header: got=%q want=%q", got, "req-123")
+	}
+	if string(body) != "req-123|req-123" {
+		t.Fatalf("body: got=%q", body)
+	}
+}
+
+func TestRequestIDMiddlewareGeneratesID(t *testing.T) {
+	app := newRequestIDTestApp(t, Config{})
+
+	for _, incoming := range []string{"", "has spaces", strings.Repeat("x", maxRequestIDLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/id", nil)
+		if incoming != "" {
+			req.Header.Set(HeaderRequestID, incoming)
+		}
+		res, body := doErrorRequest(t, app, req)
+		got := res.Header.Get(HeaderRequestID)
+		if got == "" || got == incoming {
+			t.Fatalf("incoming %q: expected generated id, got %q", incoming, got)
+		}
+		if string(body) != got+"|"+got {
+			t.Fatalf("incoming %q: body=%q header=%q", incoming, body, got)
+		}
+	}
+}
+
+func TestRequestIDMiddlewareIgnoresIncomingWhenConfigured(t *testing.T) {
+	app := newRequestIDTestApp(t, Config{RequestID: RequestIDConfig{
+		Header:         "X-Correlation-ID",
+		IgnoreIncoming: true,
+	}})
+
+	req := httptest.NewRequest(http.MethodGet, "/id", nil)
+	req.Header.Set("X-Correlation-ID", "req-123")
+	res, _ := doErrorRequest(t, app, req)
+	if got := res.Header.Get("X-Correlation-ID"); got == "" || got == "req-123" {
+		t.Fatalf("expected generated id, got %q", got)
+	}
+}
+
+func TestErrorHandlerIncludesRequestID(t *testing.T) {
+	app := newRequestIDTestApp(t, Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set(HeaderRequestID, "req-123")
+	res, body := doErrorRequest(t, app, req)
+	if res.StatusCode != http.StatusConflict {
+		t.Fatalf("status: got=%d want=%d", res.StatusCode, http.StatusConflict)
+	}
+	if got := decodeErrorBody(t, body); got.RequestID != "req-123" {
+		t.Fatalf("request id: got=%q want=%q", got.RequestID, "req-123")
+	}
+}
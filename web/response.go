@@ -18,3 +18,17 @@ type PagedResponse struct {
 	Data any       `json:"data,omitempty"`
 	Meta PagedMeta `json:"meta"`
 }
+
+type CursorMeta struct {
+	Limit      int    `json:"limit,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	HasNext    bool   `json:"has_next"`
+	HasPrev    bool   `json:"has_prev"`
+}
+
+// CursorPage is the response envelope of keyset-paginated routes.
+type CursorPage[T any] struct {
+	Data []T        `json:"data"`
+	Meta CursorMeta `json:"meta"`
+}
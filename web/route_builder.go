@@ -13,11 +13,32 @@ var nonWordOperationIDChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
 
 // RouteBuilder provides fluent API for route metadata configuration
 type RouteBuilder struct {
-	method   string
-	path     string
-	router   fiber.Router
-	registry *MetadataRegistry
-	metadata *RouteMetadata
+	method    string
+	path      string
+	localPath string
+	router    fiber.Router
+	registry  *MetadataRegistry
+	metadata  *RouteMetadata
+
+	// endpoint is served by the placeholder handler registered for routes
+	// declared without handlers; see setEndpoint.
+	endpoint     fiber.Handler
+	endpointSlot bool
+
+	// cors receives route CORS overrides; see CORS.
+	cors *CORSRegistry
+	// bodyLimits receives route body limits; see BodyLimit.
+	bodyLimits *BodyLimitRegistry
+	// streamsBody is set when the handler reads the body with RequestBody;
+	// see StreamBody.
+	streamsBody bool
+
+	// middlewares run right before the route's final handler; see Middleware.
+	middlewares []RouteMiddleware
+
+	// formats are extra response content types; see Formats.
+	formats    []string
+	negotiated bool
 }
 
 // RouteOption applies reusable configuration to a RouteBuilder.
@@ -25,17 +46,45 @@ type RouteOption func(*RouteBuilder) *RouteBuilder
 
 // newRouteBuilder creates a new route builder
 func newRouteBuilder(method, path string, router fiber.Router, registry *MetadataRegistry, inheritedTags []string, handlers []fiber.Handler) *RouteBuilder {
+	// Copy inherited tags
+	tags := make([]string, len(inheritedTags))
+	copy(tags, inheritedTags)
+
+	builder := &RouteBuilder{
+		method:    strings.ToUpper(method),
+		path:      resolveRegisteredPath(router, path),
+		localPath: path,
+		router:    router,
+		registry:  registry,
+		metadata: &RouteMetadata{
+			Tags:      tags,
+			Responses: make(map[int]ResponseMetadata),
+			Examples:  make(map[int]interface{}),
+		},
+	}
+
 	// Fiber v3 API signature: (path, handler, ...handlers)
 	// We need at least one handler
 	if len(handlers) == 0 {
-		// If no handlers provided, register a dummy route
-		// The route's actual handler will be defined elsewhere
-		handlers = append(handlers, func(c fiber.Ctx) error {
-			return c.Next()
-		})
-	}
+		// If no handlers provided, register a placeholder that serves an
+		// endpoint attached later (web.Handle) or falls through.
+		builder.endpointSlot = true
+		handlers = append(handlers, builder.serveEndpoint)
+	}
+	// Route middlewares are attached after registration, so reserve their
+	// slot in front of the final handler. The final handler stays last for
+	// tooling that resolves handler names from the route.
+	last := len(handlers) - 1
+	handlers = append(append(append([]fiber.Handler{}, handlers[:last]...), builder.serveMiddlewares), handlers[last])
+	builder.register(handlers...)
 
-	// Register the route with Fiber
+	// Ensure base metadata is visible even when no RouteBuilder methods are chained.
+	builder.finalize()
+	return builder
+}
+
+// register adds handlers for the builder's method and path to the fiber router.
+func (b *RouteBuilder) register(handlers ...fiber.Handler) {
 	// Fiber v3 requires: path string, handler any, ...middleware
 	firstHandler := any(handlers[0])
 	restHandlers := make([]any, len(handlers)-1)
@@ -43,46 +92,42 @@ func newRouteBuilder(method, path string, router fiber.Router, registry *Metadat
 		restHandlers[i-1] = handlers[i]
 	}
 
-	switch strings.ToUpper(method) {
+	switch b.method {
 	case "GET":
-		router.Get(path, firstHandler, restHandlers...)
+		b.router.Get(b.localPath, firstHandler, restHandlers...)
 	case "POST":
-		router.Post(path, firstHandler, restHandlers...)
+		b.router.Post(b.localPath, firstHandler, restHandlers...)
 	case "PUT":
-		router.Put(path, firstHandler, restHandlers...)
+		b.router.Put(b.localPath, firstHandler, restHandlers...)
 	case "DELETE":
-		router.Delete(path, firstHandler, restHandlers...)
+		b.router.Delete(b.localPath, firstHandler, restHandlers...)
 	case "PATCH":
-		router.Patch(path, firstHandler, restHandlers...)
+		b.router.Patch(b.localPath, firstHandler, restHandlers...)
 	case "HEAD":
-		router.Head(path, firstHandler, restHandlers...)
+		b.router.Head(b.localPath, firstHandler, restHandlers...)
 	case "OPTIONS":
-		router.Options(path, firstHandler, restHandlers...)
+		b.router.Options(b.localPath, firstHandler, restHandlers...)
 	case "ALL":
-		router.All(path, firstHandler, restHandlers...)
+		b.router.All(b.localPath, firstHandler, restHandlers...)
 	}
+}
 
-	fullPath := resolveRegisteredPath(router, path)
-
-	// Copy inherited tags
-	tags := make([]string, len(inheritedTags))
-	copy(tags, inheritedTags)
-
-	builder := &RouteBuilder{
-		method:   strings.ToUpper(method),
-		path:     fullPath,
-		router:   router,
-		registry: registry,
-		metadata: &RouteMetadata{
-			Tags:      tags,
-			Responses: make(map[int]ResponseMetadata),
-			Examples:  make(map[int]interface{}),
-		},
+// setEndpoint attaches the final handler of the route. Routes declared without
+// handlers serve it from their placeholder; otherwise it is registered after
+// the existing handlers and runs once they call c.Next().
+func (b *RouteBuilder) setEndpoint(handler fiber.Handler) {
+	if b.endpointSlot && b.endpoint == nil {
+		b.endpoint = handler
+		return
 	}
+	b.register(handler)
+}
 
-	// Ensure base metadata is visible even when no RouteBuilder methods are chained.
-	builder.finalize()
-	return builder
+func (b *RouteBuilder) serveEndpoint(c fiber.Ctx) error {
+	if b.endpoint != nil {
+		return b.endpoint(c)
+	}
+	return c.Next()
 }
 
 func resolveRegisteredPath(router fiber.Router, path string) string {
@@ -632,6 +677,21 @@ func (b *RouteBuilder) Paginated(itemType any) *RouteBuilder {
 	return b
 }
 
+// CursorPaginated documents cursor and limit query parameters and a
+// CursorPage 200 response envelope of itemType.
+// The generated response shape is:
+// { "data": [...], "meta": { "limit": 20, "next_cursor": "...", "prev_cursor": "...", "has_next": true, "has_prev": false } }.
+func (b *RouteBuilder) CursorPaginated(itemType any) *RouteBuilder {
+	b.metadata.Pagination = &PaginationMetadata{
+		ItemType: reflect.TypeOf(itemType),
+		Cursor:   true,
+	}
+	b.ensureResponseMaps()
+	b.addErrorResponseIfMissing(400, "Invalid cursor")
+	b.finalize()
+	return b
+}
+
 func (b *RouteBuilder) setRequestBody(requestType any, required bool, requireAtLeastOne bool, contentTypes ...string) {
 	if len(contentTypes) == 0 {
 		contentTypes = []string{ContentTypeApplicationJSON}
@@ -754,6 +814,9 @@ func (b *RouteBuilder) setResponse(statusCode int, responseType any, contentType
 	}
 
 	b.metadata.Responses[statusCode] = resp
+	if len(b.formats) > 0 {
+		b.addFormats(statusCode, respType)
+	}
 }
 
 func appendUniqueResponseType(existing []reflect.Type, t reflect.Type) []reflect.Type {
@@ -801,3 +864,15 @@ func (b *RouteBuilder) addParameterMetadata(in, name string, paramType any, requ
 		Extensions:  extensions,
 	})
 }
+
+// putParameter adds param, replacing an earlier parameter with the same name
+// and location.
+func (b *RouteBuilder) putParameter(param ParameterMetadata) {
+	for i, existing := range b.metadata.Parameters {
+		if existing.Name == param.Name && existing.In == param.In {
+			b.metadata.Parameters[i] = param
+			return
+		}
+	}
+	b.metadata.Parameters = append(b.metadata.Parameters, param)
+}
@@ -0,0 +1,408 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	// ErrRouteTimeout is returned when a route set with Timeout misses its deadline.
+	ErrRouteTimeout = NewError(http.StatusGatewayTimeout, "ROUTE_TIMEOUT", "request timed out")
+	// ErrBodyTooLarge is returned when a request body exceeds the route's limit.
+	ErrBodyTooLarge = NewError(http.StatusRequestEntityTooLarge, "BODY_TOO_LARGE", "request body too large")
+	// ErrRouteSaturated is returned when a route set with MaxConcurrent is full.
+	ErrRouteSaturated = NewError(http.StatusServiceUnavailable, "ROUTE_SATURATED", "too many concurrent requests")
+)
+
+// Metrics recorded by Timeout, BodyLimit and MaxConcurrent.
+const (
+	RouteTimeoutsMetric     = "http.server.route_timeouts"
+	BodyLimitRejectedMetric = "http.server.body_limit_rejected"
+	RouteShedMetric         = "http.server.route_shed"
+	RouteInFlightMetric     = "http.server.route_in_flight"
+)
+
+// Timeout returns a RouteOption that gives the route a deadline of d.
+// Usage: .With(web.Timeout(2 * time.Second))
+func Timeout(d time.Duration) RouteOption {
+	return func(b *RouteBuilder) *RouteBuilder {
+		return b.Timeout(d)
+	}
+}
+
+// Timeout runs the handler with a context.Context that expires after d, read
+// with c.Context(). Handlers must honor the context: when the deadline has
+// passed and the handler returns an error, the request fails with 504.
+func (b *RouteBuilder) Timeout(d time.Duration) *RouteBuilder {
+	if d <= 0 {
+		return b
+	}
+	attrs := routeLimitAttributes(b)
+	b.Middleware(func(c fiber.Ctx, next func() error) error {
+		parent := c.Context()
+		ctx, cancel := context.WithTimeout(parent, d)
+		defer cancel()
+		c.SetContext(ctx)
+		defer c.SetContext(parent)
+
+		err := next()
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			otel.From(ctx).AddCounter(ctx, RouteTimeoutsMetric, 1, attrs...)
+			return ErrRouteTimeout
+		}
+		return err
+	})
+
+	b.ensureResponseMaps()
+	b.addErrorResponseIfMissing(http.StatusGatewayTimeout, "Request timed out")
+	return b
+}
+
+// BodyLimit returns a RouteOption that overrides fiber.app.body_limit for
+// the route.
+// Usage: r.Post("/uploads", h.Upload).With(web.BodyLimit("200MiB"))
+func BodyLimit(limit string) RouteOption {
+	return func(b *RouteBuilder) *RouteBuilder {
+		return b.BodyLimit(limit)
+	}
+}
+
+// BodyLimit overrides fiber.app.body_limit for the route with a size such as
+// "200MiB". The server keeps reading at most the global limit up front; for
+// limits above it, BodyLimitMiddleware turns on request body streaming and
+// the route reads the rest of the body only up to its own limit. Routes set
+// with StreamBody or MultipartStream read the stream through RequestBody and
+// never hold the body in memory; other routes get it buffered. It panics on
+// an invalid size, like registering a route with an invalid path.
+func (b *RouteBuilder) BodyLimit(limit string) *RouteBuilder {
+	size, err := ParseBodyLimit(limit)
+	if err != nil {
+		panic("web: invalid body limit " + limit + ": " + err.Error())
+	}
+	b.bodyLimits.Register(b.method, b.path, size)
+
+	attrs := routeLimitAttributes(b)
+	b.Middleware(func(c fiber.Ctx, next func() error) error {
+		rejected := func() error {
+			otel.From(c.Context()).AddCounter(c.Context(), BodyLimitRejectedMetric, 1, attrs...)
+			return ErrBodyTooLarge
+		}
+		if !b.streamsBody || !c.Request().IsBodyStream() {
+			if err := readBodyWithin(c, size); err != nil {
+				if errors.Is(err, ErrBodyTooLarge) {
+					return rejected()
+				}
+				return err
+			}
+			return next()
+		}
+
+		if c.Request().Header.ContentLength() > size {
+			c.Response().SetConnectionClose()
+			return rejected()
+		}
+		body := &limitedBody{r: c.Request().BodyStream(), n: int64(size)}
+		c.Locals(requestBodyLocalsKey, body)
+		err := next()
+		if body.exceeded {
+			// The rest of the stream is left unread.
+			c.Response().SetConnectionClose()
+			return rejected()
+		}
+		return err
+	})
+
+	b.ensureResponseMaps()
+	b.addErrorResponseIfMissing(http.StatusRequestEntityTooLarge, "Request body too large")
+	return b
+}
+
+// StreamBody returns a RouteOption that marks the route as reading its body
+// with RequestBody.
+// Usage: r.Put("/objects/:key", h.Put).With(web.StreamBody(), web.BodyLimit("1GiB"))
+func StreamBody() RouteOption {
+	return func(b *RouteBuilder) *RouteBuilder {
+		return b.StreamBody()
+	}
+}
+
+// StreamBody marks the route as reading its body with RequestBody, so a body
+// streamed under BodyLimit is handed over unread instead of being buffered.
+func (b *RouteBuilder) StreamBody() *RouteBuilder {
+	b.streamsBody = true
+	return b
+}
+
+// RequestBody returns a reader over the request body. On routes set with
+// StreamBody it reads a streamed body straight from the connection and fails
+// with ErrBodyTooLarge past the route's BodyLimit; otherwise it reads the
+// body fiber has buffered.
+func RequestBody(c fiber.Ctx) io.Reader {
+	if body, ok := c.Locals(requestBodyLocalsKey).(*limitedBody); ok {
+		return body
+	}
+	if body := c.Request().BodyStream(); body != nil {
+		return body
+	}
+	return bytes.NewReader(c.Request().Body())
+}
+
+const requestBodyLocalsKey = "us.web.request_body"
+
+// limitedBody reads a streamed body and fails with ErrBodyTooLarge once more
+// than n bytes remain to be read.
+type limitedBody struct {
+	r        io.Reader
+	n        int64
+	exceeded bool
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, ErrBodyTooLarge
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.n {
+		n, l.n, l.exceeded = int(l.n), 0, true
+		return n, ErrBodyTooLarge
+	}
+	l.n -= int64(n)
+	return n, err
+}
+
+// MaxConcurrent returns a RouteOption that caps in-flight requests of the route.
+// Usage: .With(web.MaxConcurrent(8))
+func MaxConcurrent(n int) RouteOption {
+	return func(b *RouteBuilder) *RouteBuilder {
+		return b.MaxConcurrent(n)
+	}
+}
+
+// MaxConcurrent serves at most n requests of the route at once and sheds the
+// rest with 503 instead of queueing them.
+func (b *RouteBuilder) MaxConcurrent(n int) *RouteBuilder {
+	if n <= 0 {
+		return b
+	}
+	slots := make(chan struct{}, n)
+	attrs := routeLimitAttributes(b)
+	b.Middleware(func(c fiber.Ctx, next func() error) error {
+		ctx := c.Context()
+		obs := otel.From(ctx)
+		select {
+		case slots <- struct{}{}:
+		default:
+			obs.AddCounter(ctx, RouteShedMetric, 1, attrs...)
+			c.Set(fiber.HeaderRetryAfter, "1")
+			return ErrRouteSaturated
+		}
+		obs.AddUpDownCounter(ctx, RouteInFlightMetric, 1, attrs...)
+		defer func() {
+			<-slots
+			obs.AddUpDownCounter(ctx, RouteInFlightMetric, -1, attrs...)
+		}()
+		return next()
+	})
+
+	b.ensureResponseMaps()
+	b.addErrorResponseIfMissing(http.StatusServiceUnavailable, "Too many concurrent requests")
+	b.SetHeaders(http.StatusServiceUnavailable, fiber.HeaderRetryAfter, 0, "Seconds to wait before retrying")
+	return b
+}
+
+func routeLimitAttributes(b *RouteBuilder) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("http.request.method", b.method),
+		attribute.String("http.route", b.path),
+	}
+}
+
+func exceedsBodyLimit(c fiber.Ctx, limit int) bool {
+	if n := c.Request().Header.ContentLength(); n > limit {
+		return true
+	}
+	// Reading a streamed body would buffer it; readers enforce their own limits.
+	if c.Request().IsBodyStream() {
+		return false
+	}
+	return len(c.Request().Body()) > limit
+}
+
+// readBodyWithin rejects bodies above limit, by Content-Length before reading
+// anything, and buffers a streamed body without reading past limit. A
+// rejected stream is left unread, so the connection is closed after the
+// response.
+func readBodyWithin(c fiber.Ctx, limit int) error {
+	req := c.Request()
+	if !req.IsBodyStream() {
+		if req.Header.ContentLength() > limit || len(req.Body()) > limit {
+			return ErrBodyTooLarge
+		}
+		return nil
+	}
+	if req.Header.ContentLength() > limit {
+		c.Response().SetConnectionClose()
+		return ErrBodyTooLarge
+	}
+	body, err := io.ReadAll(io.LimitReader(req.BodyStream(), int64(limit)+1))
+	if err != nil {
+		return err
+	}
+	if len(body) > limit {
+		c.Response().SetConnectionClose()
+		return ErrBodyTooLarge
+	}
+	req.SetBody(body)
+	return nil
+}
+
+// BodyLimitRegistry keeps route body limits set with RouteBuilder.BodyLimit.
+// Like CORSRegistry it lives for the whole app lifetime.
+type BodyLimitRegistry struct {
+	mu     sync.RWMutex
+	routes []bodyLimitRoute
+	max    int
+	raise  func(limit int)
+}
+
+type bodyLimitRoute struct {
+	method  string
+	pattern string
+	limit   int
+}
+
+func NewBodyLimitRegistry() *BodyLimitRegistry {
+	return &BodyLimitRegistry{}
+}
+
+// Register sets the body limit for method and the full route pattern.
+func (r *BodyLimitRegistry) Register(method, pattern string, limit int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.routes = append(r.routes, bodyLimitRoute{method: strings.ToUpper(method), pattern: pattern, limit: limit})
+	if limit > r.max {
+		r.max = limit
+	}
+	raise := r.raise
+	r.mu.Unlock()
+	if raise != nil {
+		raise(limit)
+	}
+}
+
+// Match returns the limit registered for the route that serves method and path.
+func (r *BodyLimitRegistry) Match(app fiber.Config, method, path string) (int, bool) {
+	if r == nil {
+		return 0, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, route := range r.routes {
+		if route.method != method && !(route.method == fiber.MethodGet && method == fiber.MethodHead) {
+			continue
+		}
+		if fiber.RoutePatternMatch(path, route.pattern, app) {
+			return route.limit, true
+		}
+	}
+	return 0, false
+}
+
+// Max returns the largest registered limit.
+func (r *BodyLimitRegistry) Max() int {
+	if r == nil {
+		return 0
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.max
+}
+
+func (r *BodyLimitRegistry) bind(raise func(limit int)) {
+	r.mu.Lock()
+	r.raise = raise
+	limit := r.max
+	r.mu.Unlock()
+	raise(limit)
+}
+
+// BodyLimitMiddleware lets routes accept bodies above fiber.app.body_limit
+// without raising the server-wide limit. Once a route needs more, the server
+// streams bodies beyond the global limit instead of rejecting them; routes
+// with an override read the stream up to their own limit and the middleware
+// holds every other route to the global limit.
+type BodyLimitMiddleware struct {
+	global   int
+	registry *BodyLimitRegistry
+	// streams is set when the app streams request bodies itself, in which
+	// case handlers of routes without an override read the stream.
+	streams bool
+}
+
+func NewBodyLimitMiddleware(server *FiberServer, registries *RegistryContainer) *BodyLimitMiddleware {
+	m := &BodyLimitMiddleware{
+		global:   server.App.Config().BodyLimit,
+		registry: registries.BodyLimits,
+		streams:  server.App.Config().StreamRequestBody,
+	}
+	m.registry.bind(func(limit int) {
+		if limit > m.global && !m.streams {
+			streamLargeBodies(server.App.Server())
+		}
+	})
+	return m
+}
+
+func (m *BodyLimitMiddleware) Handle(r Router) {
+	r.Use(m.Middleware)
+}
+
+func (m *BodyLimitMiddleware) Middleware(c fiber.Ctx) error {
+	if m.registry.Max() <= m.global {
+		return c.Next()
+	}
+	if _, ok := m.registry.Match(c.App().Config(), c.Method(), c.Path()); ok {
+		return c.Next()
+	}
+	if m.streams {
+		if exceedsBodyLimit(c, m.global) {
+			otel.From(c.Context()).AddCounter(c.Context(), BodyLimitRejectedMetric, 1)
+			return ErrBodyTooLarge
+		}
+		return c.Next()
+	}
+	if err := readBodyWithin(c, m.global); err != nil {
+		if errors.Is(err, ErrBodyTooLarge) {
+			otel.From(c.Context()).AddCounter(c.Context(), BodyLimitRejectedMetric, 1)
+		}
+		return err
+	}
+	return c.Next()
+}
+
+// streamLargeBodies makes the server hand bodies above MaxRequestBodySize to
+// the handler as a stream instead of rejecting them. Multipart forms are no
+// longer read ahead either, so oversized uploads are rejected before they are
+// spooled.
+func streamLargeBodies(server *fasthttp.Server) {
+	if server != nil {
+		server.StreamRequestBody = true
+		server.DisablePreParseMultipartForm = true
+	}
+}
@@ -0,0 +1,130 @@
+package web
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestRouteTimeoutReturnsGatewayTimeout(t *testing.T) {
+	app := newErrorHandlerTestApp(t, Config{})
+	r := NewRouterWithRegistry(app, nil)
+	r.Get("/slow", func(c fiber.Ctx) error {
+		<-c.Context().Done()
+		return c.Context().Err()
+	}).Timeout(20 * time.Millisecond)
+	r.Get("/fast", func(c fiber.Ctx) error {
+		if _, ok := c.Context().Deadline(); !ok {
+			t.Error("expected a deadline on the route context")
+		}
+		return c.SendString("ok")
+	}).Timeout(time.Second)
+
+	res, body := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if res.StatusCode != http.StatusGatewayTimeout || decodeErrorBody(t, body).Error.Code != "ROUTE_TIMEOUT" {
+		t.Fatalf("slow: status=%d body=%s", res.StatusCode, body)
+	}
+	res, body = doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if res.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Fatalf("fast: status=%d body=%s", res.StatusCode, body)
+	}
+}
+
+func TestRouteBodyLimitOverridesGlobalLimit(t *testing.T) {
+	server := NewFiberServer(Config{}, FiberConfig{App: FiberAppConfig{BodyLimit: "16B"}})
+	registries := NewRegistryContainer()
+	r := NewRouterWithRegistries(server.App, registries)
+	NewBodyLimitMiddleware(server, registries).Handle(r)
+
+	echo := func(c fiber.Ctx) error { return c.SendStatus(http.StatusNoContent) }
+	r.Post("/uploads", echo).BodyLimit("64B")
+	r.Post("/small", echo).BodyLimit("8B")
+	r.Post("/stream", func(c fiber.Ctx) error {
+		if !c.Request().IsBodyStream() {
+			t.Error("expected the streamed body to reach the handler unread")
+		}
+		body, err := io.ReadAll(RequestBody(c))
+		if err != nil {
+			return err
+		}
+		if len(body) != 48 {
+			t.Errorf("stream: read %d bytes", len(body))
+		}
+		return c.SendStatus(http.StatusNoContent)
+	}).StreamBody().BodyLimit("64B")
+	r.Post("/json", echo)
+
+	cases := []struct {
+		path    string
+		size    int
+		chunked bool
+		status  int
+	}{
+		{"/uploads", 48, false, http.StatusNoContent},
+		{"/uploads", 48, true, http.StatusNoContent},
+		{"/uploads", 80, false, http.StatusRequestEntityTooLarge},
+		{"/uploads", 80, true, http.StatusRequestEntityTooLarge},
+		{"/json", 12, false, http.StatusNoContent},
+		{"/json", 32, false, http.StatusRequestEntityTooLarge},
+		{"/json", 32, true, http.StatusRequestEntityTooLarge},
+		{"/small", 12, false, http.StatusRequestEntityTooLarge},
+		{"/stream", 48, true, http.StatusNoContent},
+		{"/stream", 80, true, http.StatusRequestEntityTooLarge},
+		{"/stream", 80, false, http.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(strings.Repeat("x", tc.size)))
+		if tc.chunked {
+			req.ContentLength = -1
+			req.TransferEncoding = []string{"chunked"}
+		}
+		res, body := doErrorRequest(t, server.App, req)
+		if res.StatusCode != tc.status {
+			t.Fatalf("%s with %d bytes (chunked=%t): status=%d body=%s", tc.path, tc.size, tc.chunked, res.StatusCode, body)
+		}
+	}
+	if got := server.App.Server().MaxRequestBodySize; got != 16 {
+		t.Fatalf("server body limit must stay global: got=%d", got)
+	}
+}
+
+func TestRouteMaxConcurrentShedsLoad(t *testing.T) {
+	app := newErrorHandlerTestApp(t, Config{})
+	r := NewRouterWithRegistry(app, nil)
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	r.Get("/report", func(c fiber.Ctx) error {
+		entered <- struct{}{}
+		<-release
+		return c.SendString("done")
+	}).MaxConcurrent(1)
+
+	first := make(chan int, 1)
+	go func() {
+		res, err := app.Test(httptest.NewRequest(http.MethodGet, "/report", nil), fiber.TestConfig{Timeout: 0})
+		if err != nil {
+			first <- 0
+			return
+		}
+		first <- res.StatusCode
+	}()
+	<-entered
+
+	res, body := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/report", nil))
+	if res.StatusCode != http.StatusServiceUnavailable || decodeErrorBody(t, body).Error.Code != "ROUTE_SATURATED" {
+		t.Fatalf("saturated: status=%d body=%s", res.StatusCode, body)
+	}
+	if res.Header.Get(fiber.HeaderRetryAfter) == "" {
+		t.Fatal("expected Retry-After on shed requests")
+	}
+
+	close(release)
+	if status := <-first; status != http.StatusOK {
+		t.Fatalf("first request: status=%d", status)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 )
 
 // RouteMetadata stores OpenAPI metadata for a single route
@@ -19,6 +20,7 @@ type RouteMetadata struct {
 	Security        []SecurityRequirement
 	Policies        []string
 	Pagination      *PaginationMetadata
+	Deprecation     *DeprecationMetadata
 	Responses       map[int]ResponseMetadata // statusCode -> metadata
 	Examples        map[int]interface{}      // statusCode -> example
 }
@@ -31,6 +33,7 @@ type ParameterMetadata struct {
 	Required    bool
 	Description string
 	Extensions  string // e.g. "x-nullable,x-owner=team,!x-omitempty"
+	Enum        []any  // allowed values; applied to items for array types
 }
 
 // SecurityRequirement stores OpenAPI operation security requirements.
@@ -42,6 +45,16 @@ type SecurityRequirement struct {
 // PaginationMetadata stores automatic pagination documentation settings.
 type PaginationMetadata struct {
 	ItemType reflect.Type
+	// Cursor documents cursor/limit params and a CursorPage envelope instead
+	// of offset pagination.
+	Cursor bool
+}
+
+// DeprecationMetadata marks a route deprecated; see RouteBuilder.Deprecated.
+type DeprecationMetadata struct {
+	Since  time.Time
+	Sunset time.Time
+	Link   string
 }
 
 // RequestBodyMetadata stores request body schema metadata.
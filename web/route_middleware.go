@@ -0,0 +1,35 @@
+package web
+
+import "github.com/gofiber/fiber/v3"
+
+// RouteMiddleware runs around a single route's handler. Calling next runs the
+// remaining route middlewares and then the handler.
+type RouteMiddleware func(c fiber.Ctx, next func() error) error
+
+// Middleware attaches middlewares that only apply to this route. They run in
+// order after group middlewares and the route's leading handlers, right before
+// its final handler.
+func (b *RouteBuilder) Middleware(middlewares ...RouteMiddleware) *RouteBuilder {
+	for _, mw := range middlewares {
+		if mw != nil {
+			b.middlewares = append(b.middlewares, mw)
+		}
+	}
+	return b
+}
+
+func (b *RouteBuilder) serveMiddlewares(c fiber.Ctx) error {
+	if len(b.middlewares) == 0 {
+		return c.Next()
+	}
+	return b.runMiddleware(c, 0)
+}
+
+func (b *RouteBuilder) runMiddleware(c fiber.Ctx, i int) error {
+	if i >= len(b.middlewares) {
+		return c.Next()
+	}
+	return b.middlewares[i](c, func() error {
+		return b.runMiddleware(c, i+1)
+	})
+}
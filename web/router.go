@@ -27,6 +27,9 @@ type Router interface {
 
 	// Use adds middleware
 	Use(args ...interface{}) Router
+
+	// Version creates a sub-router for an API version mounted at "/"+name
+	Version(name string, opts ...VersionOption) Router
 }
 
 // routerWrapper wraps a fiber.Router and provides fluent API
@@ -35,6 +38,10 @@ type routerWrapper struct {
 	inheritedTags []string
 	defaultOpts   []RouteOption
 	registry      *MetadataRegistry
+	cors          *CORSRegistry
+	bodyLimits    *BodyLimitRegistry
+	versions      *VersionRegistry
+	version       *apiVersion
 }
 
 // NewRouterWithRegistry creates a router wrapper bound to a specific metadata registry.
@@ -48,6 +55,19 @@ func NewRouterWithRegistry(fiberRouter fiber.Router, registry *MetadataRegistry)
 	}
 }
 
+// NewRouterWithRegistries creates a router wrapper bound to every registry in
+// the container, so route options such as web.CORS take effect.
+func NewRouterWithRegistries(fiberRouter fiber.Router, registries *RegistryContainer) Router {
+	if registries == nil {
+		return NewRouterWithRegistry(fiberRouter, nil)
+	}
+	wrapper := NewRouterWithRegistry(fiberRouter, registries.Metadata).(*routerWrapper)
+	wrapper.cors = registries.CORS
+	wrapper.bodyLimits = registries.BodyLimits
+	wrapper.versions = registries.Versions
+	return wrapper
+}
+
 // Get registers a GET route and returns RouteBuilder for chaining
 func (r *routerWrapper) Get(path string, handlers ...fiber.Handler) *RouteBuilder {
 	return r.newBuilder("GET", path, handlers...)
@@ -87,6 +107,9 @@ func (r *routerWrapper) Options(path string, handlers ...fiber.Handler) *RouteBu
 func (r *routerWrapper) All(path string, handlers ...fiber.Handler) Router {
 	if len(handlers) == 0 {
 		r.fiberRouter.All(path, func(c fiber.Ctx) error { return c.Next() })
+		if r.version != nil {
+			r.versions.record(r.version, "", resolveRegisteredPath(r.fiberRouter, path))
+		}
 		return r
 	}
 	firstHandler := any(handlers[0])
@@ -95,6 +118,9 @@ func (r *routerWrapper) All(path string, handlers ...fiber.Handler) Router {
 		restHandlers[i-1] = handlers[i]
 	}
 	r.fiberRouter.All(path, firstHandler, restHandlers...)
+	if r.version != nil {
+		r.versions.record(r.version, "", resolveRegisteredPath(r.fiberRouter, path))
+	}
 	return r
 }
 
@@ -112,6 +138,10 @@ func (r *routerWrapper) Group(prefix string, handlers ...fiber.Handler) Router {
 	}
 	// Inherit tags from parent router
 	wrapper := NewRouterWithRegistry(groupRouter, r.registry).(*routerWrapper)
+	wrapper.cors = r.cors
+	wrapper.bodyLimits = r.bodyLimits
+	wrapper.versions = r.versions
+	wrapper.version = r.version
 	wrapper.inheritedTags = append([]string{}, r.inheritedTags...)
 	wrapper.defaultOpts = append([]RouteOption{}, r.defaultOpts...)
 	return wrapper
@@ -125,6 +155,10 @@ func (r *routerWrapper) With(opts ...RouteOption) Router {
 	out := &routerWrapper{
 		fiberRouter:   r.fiberRouter,
 		registry:      r.registry,
+		cors:          r.cors,
+		bodyLimits:    r.bodyLimits,
+		versions:      r.versions,
+		version:       r.version,
 		inheritedTags: append([]string{}, r.inheritedTags...),
 		defaultOpts:   append([]RouteOption{}, r.defaultOpts...),
 	}
@@ -146,6 +180,11 @@ func (r *routerWrapper) Use(args ...interface{}) Router {
 
 func (r *routerWrapper) newBuilder(method, path string, handlers ...fiber.Handler) *RouteBuilder {
 	b := newRouteBuilder(method, path, r.fiberRouter, r.registry, r.inheritedTags, handlers)
+	b.cors = r.cors
+	b.bodyLimits = r.bodyLimits
+	if r.version != nil {
+		r.versions.record(r.version, b.method, b.path)
+	}
 	if len(r.defaultOpts) > 0 {
 		b.With(r.defaultOpts...)
 	}
@@ -1,5 +1,5 @@
 package web
 
 func NewModuleRouter(router *FiberServer, registries *RegistryContainer) Router {
-	return NewRouterWithRegistry(router.App, registries.Metadata)
+	return NewRouterWithRegistries(router.App, registries)
 }
@@ -0,0 +1,218 @@
+package web
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bronystylecrazy/ultrastructure/cmd"
+	"github.com/bronystylecrazy/ultrastructure/di"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+)
+
+// RouteInfo is one row of the routes command.
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	OperationID string   `json:"operation_id,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Schemes     []string `json:"schemes,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+	Policies    []string `json:"policies,omitempty"`
+	Public      bool     `json:"public"`
+}
+
+// Protected reports whether the route declares a security requirement.
+func (r RouteInfo) Protected() bool {
+	return len(r.Schemes) > 0
+}
+
+// Routes lists the routes of registry sorted by path and method.
+func Routes(registry *MetadataRegistry) []RouteInfo {
+	if registry == nil {
+		return nil
+	}
+	routes := registry.AllRoutes()
+	out := make([]RouteInfo, 0, len(routes))
+	for key, meta := range routes {
+		method, path, ok := strings.Cut(key, ":")
+		if !ok || meta == nil {
+			continue
+		}
+		info := RouteInfo{
+			Method:      method,
+			Path:        path,
+			OperationID: meta.OperationID,
+			Tags:        append([]string(nil), meta.Tags...),
+			Policies:    append([]string(nil), meta.Policies...),
+			// Public leaves an empty, non-nil requirement list.
+			Public: meta.Security != nil && len(meta.Security) == 0,
+		}
+		for _, sec := range meta.Security {
+			if !slices.Contains(info.Schemes, sec.Scheme) {
+				info.Schemes = append(info.Schemes, sec.Scheme)
+			}
+			for _, scope := range sec.Scopes {
+				if !slices.Contains(info.Scopes, scope) {
+					info.Scopes = append(info.Scopes, scope)
+				}
+			}
+		}
+		sort.Strings(info.Scopes)
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
+
+// RoutesCommand prints the route and security table of the app. It is a
+// Handler run last, so it sees every route before the registry is cleared
+// at start.
+type RoutesCommand struct {
+	shutdowner fx.Shutdowner
+	registries *RegistryContainer
+	routes     []RouteInfo
+}
+
+func NewRoutesCommand(shutdowner fx.Shutdowner, registries *RegistryContainer) *RoutesCommand {
+	return &RoutesCommand{
+		shutdowner: shutdowner,
+		registries: registries,
+	}
+}
+
+// UseRoutesCommand provides the routes command. When it is selected, the
+// handlers are set up without listening, so keep web.Init under cmd.Run.
+//
+// Usage: us.New(web.UseRoutesCommand(), cmd.Run(web.Init()))
+func UseRoutesCommand() di.Node {
+	return di.Options(
+		di.Provide(NewRoutesCommand, Priority(Latest)),
+		cmd.OnRun("routes", InitHandlers()),
+	)
+}
+
+func (c *RoutesCommand) Handle(r Router) {
+	if c.registries != nil {
+		c.routes = Routes(c.registries.Metadata)
+	}
+}
+
+func (c *RoutesCommand) Command() *cobra.Command {
+	command := &cobra.Command{
+		Use:           "routes",
+		Short:         "Print every route with its security requirements",
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		RunE:          c.Run,
+		PostRunE: func(cmd *cobra.Command, args []string) error {
+			if c.shutdowner == nil {
+				return nil
+			}
+			return c.shutdowner.Shutdown()
+		},
+	}
+	command.Flags().StringP("format", "o", "table", "output format: table, json or csv")
+	command.Flags().Bool("unprotected", false, "only routes without a security requirement")
+	command.Flags().String("scope", "", "only routes requiring this scope")
+	command.Flags().String("tag", "", "only routes with this tag")
+	command.Flags().Bool("require-security", false, "exit non-zero when a route has neither Security nor Public")
+	return command
+}
+
+func (c *RoutesCommand) Run(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	unprotected, _ := cmd.Flags().GetBool("unprotected")
+	scope, _ := cmd.Flags().GetString("scope")
+	tag, _ := cmd.Flags().GetString("tag")
+	requireSecurity, _ := cmd.Flags().GetBool("require-security")
+
+	routes := make([]RouteInfo, 0, len(c.routes))
+	for _, route := range c.routes {
+		if unprotected && route.Protected() {
+			continue
+		}
+		if scope != "" && !slices.Contains(route.Scopes, scope) {
+			continue
+		}
+		if tag != "" && !slices.Contains(route.Tags, tag) {
+			continue
+		}
+		routes = append(routes, route)
+	}
+
+	if err := writeRoutes(cmd.OutOrStdout(), format, routes); err != nil {
+		return err
+	}
+	if !requireSecurity {
+		return nil
+	}
+
+	var missing []string
+	for _, route := range routes {
+		if !route.Protected() && !route.Public {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	for _, route := range missing {
+		fmt.Fprintln(cmd.ErrOrStderr(), "missing security:", route)
+	}
+	return fmt.Errorf("%d routes have neither Security nor Public", len(missing))
+}
+
+func writeRoutes(w io.Writer, format string, routes []RouteInfo) error {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "METHOD\tPATH\tOPERATION\tTAGS\tSCHEMES\tSCOPES\tPOLICIES\tPUBLIC")
+		for _, r := range routes {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%t\n",
+				r.Method, r.Path, dashIfEmpty(r.OperationID), joinOrDash(r.Tags), joinOrDash(r.Schemes),
+				joinOrDash(r.Scopes), joinOrDash(r.Policies), r.Public)
+		}
+		return tw.Flush()
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(routes)
+	case "csv":
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"method", "path", "operation_id", "tags", "schemes", "scopes", "policies", "public"})
+		for _, r := range routes {
+			_ = cw.Write([]string{
+				r.Method, r.Path, r.OperationID, strings.Join(r.Tags, " "), strings.Join(r.Schemes, " "),
+				strings.Join(r.Scopes, " "), strings.Join(r.Policies, " "), strconv.FormatBool(r.Public),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown format %q, want table, json or csv", format)
+	}
+}
+
+func joinOrDash(values []string) string {
+	return dashIfEmpty(strings.Join(values, ","))
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
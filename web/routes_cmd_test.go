@@ -0,0 +1,96 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func newRoutesTestCommand(t *testing.T) *RoutesCommand {
+	t.Helper()
+
+	registries := NewRegistryContainer()
+	r := NewRouterWithRegistries(fiber.New(), registries)
+	noop := func(c fiber.Ctx) error { return nil }
+	r.Get("/orders", noop).Name("listOrders").Tags("orders").Scopes("OAuth2", "orders:read")
+	r.Post("/orders", noop).Tags("orders").Scopes("OAuth2", "orders:write").Policies("orders.create")
+	r.Get("/healthz", noop).Public()
+	r.Get("/debug", noop)
+
+	command := NewRoutesCommand(nil, registries)
+	command.Handle(r)
+	// The registry is cleared at start; the command keeps its snapshot.
+	registries.Metadata.Clear()
+	return command
+}
+
+func runRoutesCommand(t *testing.T, command *RoutesCommand, args ...string) (string, string, error) {
+	t.Helper()
+
+	c := command.Command()
+	var stdout, stderr bytes.Buffer
+	c.SetOut(&stdout)
+	c.SetErr(&stderr)
+	c.SetArgs(args)
+	err := c.Execute()
+	return stdout.String(), stderr.String(), err
+}
+
+func TestRoutesCommandPrintsFilteredJSON(t *testing.T) {
+	command := newRoutesTestCommand(t)
+
+	out, _, err := runRoutesCommand(t, command, "--format=json")
+	if err != nil {
+		t.Fatalf("routes: %v", err)
+	}
+	var routes []RouteInfo
+	if err := json.Unmarshal([]byte(out), &routes); err != nil {
+		t.Fatalf("decode %q: %v", out, err)
+	}
+	if len(routes) != 4 || routes[0].Path != "/debug" || routes[2].Method != "GET" || routes[2].Path != "/orders" {
+		t.Fatalf("routes: %+v", routes)
+	}
+	if got := routes[2]; got.OperationID != "listOrders" || got.Schemes[0] != "OAuth2" || got.Scopes[0] != "orders:read" {
+		t.Fatalf("list orders: %+v", got)
+	}
+	if !routes[1].Public || routes[0].Public {
+		t.Fatalf("public flags: %+v", routes)
+	}
+
+	out, _, err = runRoutesCommand(t, command, "--format=csv", "--scope=orders:write")
+	if err != nil {
+		t.Fatalf("routes: %v", err)
+	}
+	want := "method,path,operation_id,tags,schemes,scopes,policies,public\n" +
+		"POST,/orders,,orders,OAuth2,orders:write,orders.create,false\n"
+	if out != want {
+		t.Fatalf("csv: got %q want %q", out, want)
+	}
+
+	out, _, err = runRoutesCommand(t, command, "--unprotected")
+	if err != nil {
+		t.Fatalf("routes: %v", err)
+	}
+	if strings.Contains(out, "/orders") || !strings.Contains(out, "/healthz") || !strings.Contains(out, "/debug") {
+		t.Fatalf("unprotected table:\n%s", out)
+	}
+}
+
+func TestRoutesCommandRequireSecurityFailsOnUndeclaredRoutes(t *testing.T) {
+	command := newRoutesTestCommand(t)
+
+	_, stderr, err := runRoutesCommand(t, command, "--require-security")
+	if err == nil {
+		t.Fatal("expected an error for /debug")
+	}
+	if !strings.Contains(stderr, "GET /debug") || strings.Contains(stderr, "/healthz") {
+		t.Fatalf("stderr: %q", stderr)
+	}
+
+	if _, _, err := runRoutesCommand(t, command, "--require-security", "--tag=orders"); err != nil {
+		t.Fatalf("orders are all secured: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestSecurityHeadersMiddlewareSetsNonce(t *testing.T) {
+	mw := NewSecurityHeadersMiddleware(Config{SecurityHeaders: SecurityHeadersConfig{
+		Enabled:               true,
+		ContentSecurityPolicy: "script-src 'nonce-{nonce}'",
+	}})
+	app := fiber.New()
+	app.Use(mw.Middleware)
+	app.Get("/", func(c fiber.Ctx) error { return c.SendString(CSPNonce(c)) })
+
+	res, body := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/", nil))
+	if len(body) == 0 {
+		t.Fatal("expected nonce")
+	}
+	if got := res.Header.Get(HeaderContentSecurityPolicy); got != "script-src 'nonce-"+string(body)+"'" {
+		t.Fatalf("csp: got=%q", got)
+	}
+	if res.Header.Get(fiber.HeaderXFrameOptions) != "DENY" ||
+		res.Header.Get(fiber.HeaderReferrerPolicy) != "strict-origin-when-cross-origin" ||
+		res.Header.Get(fiber.HeaderXContentTypeOptions) != "nosniff" {
+		t.Fatalf("missing default headers: %v", res.Header)
+	}
+	if res.Header.Get(fiber.HeaderStrictTransportSecurity) != "" {
+		t.Fatal("hsts must not be sent over plain http")
+	}
+}
@@ -0,0 +1,286 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	HeaderLastEventID = "Last-Event-ID"
+
+	defaultSSEHeartbeat = 15 * time.Second
+)
+
+// SSEEvent is one Server-Sent Event. Data is written as-is when it is a
+// string or []byte and JSON-encoded otherwise.
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  any
+	// Retry tells the browser how long to wait before reconnecting.
+	Retry time.Duration
+}
+
+// SSEHandler produces events for one client until it returns or the stream
+// context is done. The fiber.Ctx is not available while streaming, so values
+// needed from the request are read from ctx or the stream.
+type SSEHandler func(ctx context.Context, stream *SSEStream) error
+
+type SSEOption func(*SSEConfig)
+
+type SSEConfig struct {
+	// Heartbeat is how often a comment is sent to keep idle connections
+	// open. The default is 15s; a negative value disables it.
+	Heartbeat time.Duration
+	// Retry is sent once when the stream opens.
+	Retry time.Duration
+}
+
+// WithSSEHeartbeat sets the keep-alive comment interval.
+func WithSSEHeartbeat(interval time.Duration) SSEOption {
+	return func(c *SSEConfig) {
+		c.Heartbeat = interval
+	}
+}
+
+// WithSSERetry sets the reconnect delay sent to clients.
+func WithSSERetry(retry time.Duration) SSEOption {
+	return func(c *SSEConfig) {
+		c.Retry = retry
+	}
+}
+
+// SSE returns a RouteOption that serves the route as an event stream.
+//
+// Usage: r.Get("/orders/events").With(web.SSE(h.OrderEvents, web.WithSSERetry(3*time.Second)))
+func SSE(handler SSEHandler, opts ...SSEOption) RouteOption {
+	return func(b *RouteBuilder) *RouteBuilder {
+		return b.SSE(handler, opts...)
+	}
+}
+
+// SSE serves the route as a text/event-stream and documents it. The stream
+// ends when the handler returns, the client goes away or the server drains.
+func (b *RouteBuilder) SSE(handler SSEHandler, opts ...SSEOption) *RouteBuilder {
+	cfg := SSEConfig{Heartbeat: defaultSSEHeartbeat}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	b.setEndpoint(sseEndpoint(handler, cfg))
+
+	b.addParameterMetadata("header", HeaderLastEventID, "", false, "Id of the last event received, to resume the stream", "")
+	b.setResponse(http.StatusOK, "", ContentTypeTextEventStream, "Event stream", true)
+	b.finalize()
+	return b
+}
+
+func sseEndpoint(handler SSEHandler, cfg SSEConfig) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		stream := &SSEStream{
+			lastEventID: strings.Clone(c.Get(HeaderLastEventID)),
+			params:      snapshotParams(c),
+		}
+
+		// The request context is recycled once the handler returns.
+		ctx, cancel := context.WithCancel(context.WithoutCancel(c.Context()))
+		var release func()
+		if drain := DrainFrom(c); drain != nil {
+			release = drain.Track()
+			stop := context.AfterFunc(drain.Context(), cancel)
+			prev := cancel
+			cancel = func() {
+				stop()
+				prev()
+			}
+		}
+
+		c.Set(fiber.HeaderContentType, ContentTypeTextEventStream)
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+		c.Set("X-Accel-Buffering", "no")
+
+		return c.SendStreamWriter(func(w *bufio.Writer) {
+			defer func() {
+				stream.close()
+				if release != nil {
+					release()
+				}
+			}()
+
+			stream.w = w
+			stream.cancel = cancel
+			stream.ctx = ctx
+			if cfg.Retry > 0 {
+				_ = stream.Send(SSEEvent{Retry: cfg.Retry})
+			} else {
+				_ = stream.Comment("")
+			}
+			if cfg.Heartbeat > 0 {
+				go stream.heartbeat(cfg.Heartbeat)
+			}
+			_ = handler(ctx, stream)
+		})
+	}
+}
+
+func snapshotParams(c fiber.Ctx) map[string]string {
+	route := c.Route()
+	if route == nil || len(route.Params) == 0 {
+		return nil
+	}
+	params := make(map[string]string, len(route.Params))
+	for _, name := range route.Params {
+		params[name] = strings.Clone(c.Params(name))
+	}
+	return params
+}
+
+// SSEStream writes events to one client. It is safe for concurrent use.
+type SSEStream struct {
+	mu          sync.Mutex
+	w           *bufio.Writer
+	ctx         context.Context
+	cancel      context.CancelFunc
+	lastEventID string
+	params      map[string]string
+}
+
+// Context is done when the client disconnects or the server drains.
+func (s *SSEStream) Context() context.Context {
+	return s.ctx
+}
+
+// LastEventID returns the Last-Event-ID the client resumed from, if any.
+func (s *SSEStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Param returns a route param captured when the stream opened.
+func (s *SSEStream) Param(name string) string {
+	return s.params[name]
+}
+
+// Send writes one event and flushes it.
+func (s *SSEStream) Send(event SSEEvent) error {
+	var buf strings.Builder
+	if event.ID != "" {
+		buf.WriteString("id: " + sseField(event.ID) + "\n")
+	}
+	if event.Event != "" {
+		buf.WriteString("event: " + sseField(event.Event) + "\n")
+	}
+	if event.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+	if event.Data != nil {
+		data, err := sseData(event.Data)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(data, "\n") {
+			buf.WriteString("data: " + strings.TrimSuffix(line, "\r") + "\n")
+		}
+	}
+	buf.WriteString("\n")
+	return s.write(buf.String())
+}
+
+// Comment writes a comment line, which clients ignore.
+func (s *SSEStream) Comment(text string) error {
+	return s.write(": " + sseField(text) + "\n\n")
+}
+
+// Forward replays buffered events after LastEventID and then sends events
+// published to buffer until the stream ends.
+func (s *SSEStream) Forward(buffer *SSEBuffer) error {
+	replay, events, unsubscribe := buffer.Subscribe(s.lastEventID)
+	defer unsubscribe()
+
+	for _, event := range replay {
+		if err := s.Send(event); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-s.ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				// Dropped for falling behind; the client resumes from its last id.
+				return nil
+			}
+			if err := s.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *SSEStream) write(frame string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx.Err() != nil {
+		return s.ctx.Err()
+	}
+	if _, err := s.w.WriteString(frame); err != nil {
+		s.cancel()
+		return err
+	}
+	if err := s.w.Flush(); err != nil {
+		s.cancel()
+		return err
+	}
+	return nil
+}
+
+// close stops further writes; the writer is invalid once streaming returns.
+func (s *SSEStream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancel()
+}
+
+func (s *SSEStream) heartbeat(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Comment("ping"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func sseField(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+func sseData(data any) (string, error) {
+	switch v := data.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
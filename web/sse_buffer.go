@@ -0,0 +1,96 @@
+package web
+
+import (
+	"strconv"
+	"sync"
+)
+
+const (
+	defaultSSEBufferSize = 100
+	sseSubscriberBacklog = 64
+)
+
+// SSEBuffer fans events out to streams and keeps the last events so clients
+// reconnecting with Last-Event-ID can resume where they left off.
+//
+// Usage:
+//
+//	events := web.NewSSEBuffer(256)
+//	r.Get("/events").With(web.SSE(func(ctx context.Context, s *web.SSEStream) error {
+//		return s.Forward(events)
+//	}))
+type SSEBuffer struct {
+	mu     sync.Mutex
+	size   int
+	events []SSEEvent
+	nextID uint64
+	subs   map[chan SSEEvent]struct{}
+}
+
+// NewSSEBuffer keeps up to size events for replay. The default is 100.
+func NewSSEBuffer(size int) *SSEBuffer {
+	if size <= 0 {
+		size = defaultSSEBufferSize
+	}
+	return &SSEBuffer{
+		size: size,
+		subs: make(map[chan SSEEvent]struct{}),
+	}
+}
+
+// Publish stores event and sends it to every subscribed stream. Events
+// without an ID get a sequential one. Streams that fall behind are dropped
+// and resume from the buffer when they reconnect.
+func (b *SSEBuffer) Publish(event SSEEvent) SSEEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	if event.ID == "" {
+		event.ID = strconv.FormatUint(b.nextID, 10)
+	}
+	b.events = append(b.events, event)
+	if over := len(b.events) - b.size; over > 0 {
+		b.events = append(b.events[:0:0], b.events[over:]...)
+	}
+
+	for ch := range b.subs {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+	return event
+}
+
+// Subscribe returns the buffered events after lastEventID and a channel of
+// new events. An unknown lastEventID replays the whole buffer; an empty one
+// replays nothing.
+func (b *SSEBuffer) Subscribe(lastEventID string) (replay []SSEEvent, events <-chan SSEEvent, unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if lastEventID != "" {
+		start := 0
+		for i, event := range b.events {
+			if event.ID == lastEventID {
+				start = i + 1
+				break
+			}
+		}
+		replay = append([]SSEEvent(nil), b.events[start:]...)
+	}
+
+	ch := make(chan SSEEvent, sseSubscriberBacklog)
+	b.subs[ch] = struct{}{}
+	return replay, ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
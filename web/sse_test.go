@@ -0,0 +1,89 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestSSEStreamWritesEvents(t *testing.T) {
+	app := fiber.New()
+	r := NewRouterWithRegistry(app, nil)
+	r.Get("/events/:room").With(SSE(func(ctx context.Context, s *SSEStream) error {
+		if err := s.Send(SSEEvent{ID: "1", Event: "joined", Data: s.Param("room")}); err != nil {
+			return err
+		}
+		return s.Send(SSEEvent{ID: "2", Data: map[string]int{"count": 2}})
+	}, WithSSERetry(3*time.Second), WithSSEHeartbeat(-1)))
+
+	res, body := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/events/lobby", nil))
+	if got := res.Header.Get(fiber.HeaderContentType); got != ContentTypeTextEventStream {
+		t.Fatalf("content type: got=%q", got)
+	}
+	want := "retry: 3000\n\n" +
+		"id: 1\nevent: joined\ndata: lobby\n\n" +
+		"id: 2\ndata: {\"count\":2}\n\n"
+	if string(body) != want {
+		t.Fatalf("body:\n%s\nwant:\n%s", body, want)
+	}
+}
+
+func TestSSEStreamResumesFromLastEventID(t *testing.T) {
+	buffer := NewSSEBuffer(2)
+	for _, data := range []string{"a", "b", "c"} {
+		buffer.Publish(SSEEvent{Data: data})
+	}
+
+	drain := NewDrain()
+	app := fiber.New()
+	app.Use(drain.Middleware)
+	r := NewRouterWithRegistry(app, nil)
+	r.Get("/events").With(SSE(func(ctx context.Context, s *SSEStream) error {
+		// Closing the stream on drain lets the test read a finite body.
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			buffer.Publish(SSEEvent{Data: "d"})
+			time.Sleep(50 * time.Millisecond)
+			drain.Begin()
+		}()
+		return s.Forward(buffer)
+	}, WithSSEHeartbeat(-1)))
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set(HeaderLastEventID, "2")
+	_, raw := doErrorRequest(t, app, req)
+	body := string(raw)
+	if !strings.Contains(body, "id: 3\ndata: c\n\n") || !strings.Contains(body, "id: 4\ndata: d\n\n") {
+		t.Fatalf("expected replay of 3 and live 4, got:\n%s", body)
+	}
+	if strings.Contains(body, "data: b") {
+		t.Fatalf("event before Last-Event-ID replayed:\n%s", body)
+	}
+	if drain.InFlight() != 0 {
+		t.Fatalf("stream still tracked after close: %d", drain.InFlight())
+	}
+}
+
+func TestSSEBufferKeepsLastEvents(t *testing.T) {
+	buffer := NewSSEBuffer(2)
+	for _, data := range []string{"a", "b", "c"} {
+		buffer.Publish(SSEEvent{Data: data})
+	}
+
+	replay, _, unsubscribe := buffer.Subscribe("unknown")
+	defer unsubscribe()
+	if len(replay) != 2 || replay[0].ID != "2" || replay[1].ID != "3" {
+		t.Fatalf("replay: %+v", replay)
+	}
+	if replay, _, unsubscribe := buffer.Subscribe(""); len(replay) != 0 {
+		unsubscribe()
+		t.Fatalf("empty Last-Event-ID replayed %d events", len(replay))
+	} else {
+		unsubscribe()
+	}
+}
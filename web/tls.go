@@ -0,0 +1,237 @@
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// TLS client authentication modes for tls.client_auth.
+const (
+	TLSClientAuthNone             = "none"
+	TLSClientAuthRequest          = "request"
+	TLSClientAuthRequire          = "require"
+	TLSClientAuthVerifyIfGiven    = "verify_if_given"
+	TLSClientAuthRequireAndVerify = "require_and_verify"
+)
+
+// TLSCertificateConfig is an additional certificate pair served by SNI.
+type TLSCertificateConfig struct {
+	CertFile    string `mapstructure:"cert_file"`
+	CertKeyFile string `mapstructure:"cert_key_file"`
+}
+
+// ParseTLSClientAuth maps tls.client_auth to a tls.ClientAuthType. An empty
+// mode requires verified client certs when a client CA is configured.
+func ParseTLSClientAuth(mode string, hasClientCA bool) (tls.ClientAuthType, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "":
+		if hasClientCA {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+		return tls.NoClientCert, nil
+	case TLSClientAuthNone:
+		return tls.NoClientCert, nil
+	case TLSClientAuthRequest:
+		return tls.RequestClientCert, nil
+	case TLSClientAuthRequire:
+		return tls.RequireAnyClientCert, nil
+	case TLSClientAuthVerifyIfGiven:
+		return tls.VerifyClientCertIfGiven, nil
+	case TLSClientAuthRequireAndVerify:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return 0, fmt.Errorf("unsupported tls client_auth %q", mode)
+	}
+}
+
+// CertificateStore serves the configured certificates through
+// tls.Config.GetCertificate, so they can be replaced without restarting.
+// Certificates are picked by SNI server name, falling back to cert_file.
+type CertificateStore struct {
+	config     TLSConfig
+	minVersion uint16
+	clientAuth tls.ClientAuthType
+
+	mu       sync.RWMutex
+	certs    []*tls.Certificate
+	clientCA *x509.CertPool
+}
+
+func NewCertificateStore(config TLSConfig) (*CertificateStore, error) {
+	minVersion, err := ParseTLSMinVersion(config.TLSMinVersion)
+	if err != nil {
+		return nil, err
+	}
+	clientAuth, err := ParseTLSClientAuth(config.ClientAuth, strings.TrimSpace(config.CertClientFile) != "")
+	if err != nil {
+		return nil, err
+	}
+	s := &CertificateStore{config: config, minVersion: minVersion, clientAuth: clientAuth}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload reads every certificate pair and the client CA again. On error the
+// previous certificates stay in use.
+func (s *CertificateStore) Reload() error {
+	pairs := append([]TLSCertificateConfig{{CertFile: s.config.CertFile, CertKeyFile: s.config.CertKeyFile}}, s.config.Certificates...)
+	certs := make([]*tls.Certificate, 0, len(pairs))
+	for _, pair := range pairs {
+		cert, err := tls.LoadX509KeyPair(strings.TrimSpace(pair.CertFile), strings.TrimSpace(pair.CertKeyFile))
+		if err != nil {
+			return fmt.Errorf("tls: load key pair %q: %w", pair.CertFile, err)
+		}
+		certs = append(certs, &cert)
+	}
+
+	var pool *x509.CertPool
+	if file := strings.TrimSpace(s.config.CertClientFile); file != "" {
+		pem, err := os.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return fmt.Errorf("tls: read client CA: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("tls: no certificates in client CA %q", file)
+		}
+	}
+
+	s.mu.Lock()
+	s.certs = certs
+	s.clientCA = pool
+	s.mu.Unlock()
+	return nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (s *CertificateStore) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.certs) == 0 {
+		return nil, fmt.Errorf("tls: no certificates loaded")
+	}
+	if hello != nil && hello.ServerName != "" {
+		for _, cert := range s.certs {
+			if cert.Leaf != nil && cert.Leaf.VerifyHostname(hello.ServerName) == nil {
+				return cert, nil
+			}
+		}
+	}
+	return s.certs[0], nil
+}
+
+// TLSConfig returns a server config that always uses the current
+// certificates and client CA.
+func (s *CertificateStore) TLSConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion:     s.minVersion,
+		GetCertificate: s.GetCertificate,
+		ClientAuth:     s.clientAuth,
+	}
+	return &tls.Config{
+		MinVersion: s.minVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cfg := base.Clone()
+			s.mu.RLock()
+			cfg.ClientCAs = s.clientCA
+			s.mu.RUnlock()
+			return cfg, nil
+		},
+	}
+}
+
+// Watch reloads the certificates when their files change or the process
+// receives SIGHUP, until ctx is done. Directories are watched rather than
+// files so atomic renames (e.g. Kubernetes secret updates) are seen.
+// onReload is called with the result of every reload.
+func (s *CertificateStore) Watch(ctx context.Context, onReload func(error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	dirs := map[string]struct{}{}
+	for _, file := range s.files() {
+		dirs[filepath.Dir(file)] = struct{}{}
+	}
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return fmt.Errorf("tls: watch %q: %w", dir, err)
+		}
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(hup)
+		defer watcher.Close()
+		reload := func() {
+			err := s.Reload()
+			if onReload != nil {
+				onReload(err)
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				reload()
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op != fsnotify.Chmod && s.watches(event.Name) {
+					reload()
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				if onReload != nil {
+					onReload(err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *CertificateStore) files() []string {
+	files := []string{s.config.CertFile, s.config.CertKeyFile, s.config.CertClientFile}
+	for _, pair := range s.config.Certificates {
+		files = append(files, pair.CertFile, pair.CertKeyFile)
+	}
+	out := files[:0]
+	for _, file := range files {
+		if file = strings.TrimSpace(file); file != "" {
+			out = append(out, filepath.Clean(file))
+		}
+	}
+	return out
+}
+
+// watches reports whether an event on name can change a watched file,
+// including Kubernetes' ..data symlink swap.
+func (s *CertificateStore) watches(name string) bool {
+	name = filepath.Clean(name)
+	for _, file := range s.files() {
+		if name == file || filepath.Base(name) == "..data" && filepath.Dir(name) == filepath.Dir(file) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package web
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// writeTestCert writes a self-signed pair for dnsName and returns the paths.
+func writeTestCert(t *testing.T, dir, name, dnsName string, serial int64) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	// Write the key first so a watcher never sees a cert without its key.
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	return certFile, keyFile
+}
+
+func TestCertificateStoreSelectsBySNIAndReloads(t *testing.T) {
+	dir := t.TempDir()
+	apiCert, apiKey := writeTestCert(t, dir, "api", "api.test", 1)
+	adminCert, adminKey := writeTestCert(t, dir, "admin", "admin.test", 2)
+
+	store, err := NewCertificateStore(TLSConfig{
+		CertFile:     apiCert,
+		CertKeyFile:  apiKey,
+		Certificates: []TLSCertificateConfig{{CertFile: adminCert, CertKeyFile: adminKey}},
+	})
+	if err != nil {
+		t.Fatalf("NewCertificateStore: %v", err)
+	}
+
+	serial := func(serverName string) int64 {
+		t.Helper()
+		cert, err := store.GetCertificate(&tls.ClientHelloInfo{ServerName: serverName})
+		if err != nil {
+			t.Fatalf("GetCertificate(%q): %v", serverName, err)
+		}
+		return cert.Leaf.SerialNumber.Int64()
+	}
+	if got := serial("admin.test"); got != 2 {
+		t.Fatalf("admin.test served serial %d", got)
+	}
+	if got := serial("unknown.test"); got != 1 {
+		t.Fatalf("unknown name must fall back to cert_file, got serial %d", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan error, 8)
+	if err := store.Watch(ctx, func(err error) { reloaded <- err }); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	writeTestCert(t, dir, "api", "api.test", 3)
+
+	deadline := time.After(5 * time.Second)
+	for serial("api.test") != 3 {
+		select {
+		case <-reloaded:
+		case <-deadline:
+			t.Fatal("certificate was not reloaded after the file changed")
+		}
+	}
+}
+
+func TestFiberServerServesExtraListeners(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir, "server", "localhost", 1)
+
+	server := NewFiberServer(Config{
+		Server: ServerConfig{Host: "127.0.0.1", Port: 0},
+		Listen: ListenConfig{DisableStartupMessage: true, ShutdownTimeout: time.Second},
+		TLS:    TLSConfig{CertFile: certFile, CertKeyFile: keyFile},
+		Listeners: []ListenerConfig{
+			{Address: "127.0.0.1:0", RedirectHTTPS: true, RedirectPort: 8443},
+			{Network: "unix", Address: filepath.Join(dir, "sidecar.sock")},
+		},
+	}, FiberConfig{})
+	server.App.Get("/ping", func(c fiber.Ctx) error { return c.SendString("pong") })
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Listen() }()
+	select {
+	case <-server.Wait():
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not start")
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Get("http://" + server.listeners[0].ln.Addr().String() + "/ping?x=1")
+	if err != nil {
+		t.Fatalf("redirect request: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusPermanentRedirect || res.Header.Get("Location") != "https://127.0.0.1:8443/ping?x=1" {
+		t.Fatalf("redirect: status=%d location=%q", res.StatusCode, res.Header.Get("Location"))
+	}
+
+	unix := &http.Client{Transport: &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, "unix", filepath.Join(dir, "sidecar.sock"))
+	}}}
+	res, err = unix.Get("http://sidecar/ping")
+	if err != nil {
+		t.Fatalf("unix socket request: %v", err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "pong" {
+		t.Fatalf("unix socket body: %q", body)
+	}
+
+	if err := server.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("listen did not return after stop")
+	}
+}
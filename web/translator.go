@@ -0,0 +1,75 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+)
+
+// Translator localizes messages for the locale carried by ctx. The i18n
+// package provides one and installs it per request with SetTranslatorLocals.
+//
+// Keys used by web: "errors.<CODE>" for ErrorDetail.Message,
+// "validation.<tag>" and "field_validation.<field>.<tag>" for validation
+// details, and "fields.<field>" for field labels. Fields are named by their
+// json tag.
+type Translator interface {
+	Translate(ctx context.Context, key string, args map[string]string) (string, bool)
+}
+
+const translatorLocalsKey = "us.web.translator"
+
+func SetTranslatorLocals(c fiber.Ctx, translator Translator) {
+	c.Locals(translatorLocalsKey, translator)
+}
+
+func TranslatorFromLocals(c fiber.Ctx) (Translator, bool) {
+	translator, ok := c.Locals(translatorLocalsKey).(Translator)
+	return translator, ok && translator != nil
+}
+
+// ErrorMessageKey is the translation key of the message for an error code.
+func ErrorMessageKey(code string) string {
+	return "errors." + code
+}
+
+// LocalizeErrorMessage returns the message for code in the request's
+// locale, or message when there is no Translator or translation.
+func LocalizeErrorMessage(c fiber.Ctx, code, message string) string {
+	translator, ok := TranslatorFromLocals(c)
+	if !ok {
+		return message
+	}
+	if out, ok := translator.Translate(c.Context(), ErrorMessageKey(code), map[string]string{"message": message}); ok {
+		return out
+	}
+	return message
+}
+
+// TranslateValidationErrors renders one localized detail line per failed
+// field, falling back to the ValidationErrorDetails line of fields without a
+// translation.
+func TranslateValidationErrors(ctx context.Context, translator Translator, errs validator.ValidationErrors) []string {
+	fallback := ValidationErrorDetails(errs)
+	out := make([]string, 0, len(errs))
+	for i, fe := range errs {
+		field := fe.Field()
+		label, ok := translator.Translate(ctx, "fields."+field, nil)
+		if !ok {
+			label = field
+		}
+		args := map[string]string{"field": label, "param": strings.TrimSpace(fe.Param())}
+		message, ok := translator.Translate(ctx, fmt.Sprintf("field_validation.%s.%s", field, fe.Tag()), args)
+		if !ok {
+			message, ok = translator.Translate(ctx, "validation."+fe.Tag(), args)
+		}
+		if !ok {
+			message = fallback[i]
+		}
+		out = append(out, message)
+	}
+	return out
+}
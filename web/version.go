@@ -0,0 +1,405 @@
+package web
+
+import (
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// HeaderAcceptVersion selects the API version with web.VersionByHeader.
+const HeaderAcceptVersion = "Accept-Version"
+
+const (
+	versionLocalsKey   = "us.web.version"
+	versionResolvedKey = "us.web.version.resolved"
+)
+
+// ErrUnsupportedVersion is returned when a request asks for a version that
+// is not registered.
+var ErrUnsupportedVersion = NewError(http.StatusBadRequest, "UNSUPPORTED_VERSION", "unsupported API version")
+
+// VersionOption configures a version created with Router.Version.
+type VersionOption func(*apiVersion)
+
+// VersionInherit serves routes of parent that the version does not declare
+// itself. Inheritance is transitive.
+func VersionInherit(parent string) VersionOption {
+	return func(v *apiVersion) {
+		v.parent = strings.TrimSpace(parent)
+	}
+}
+
+// VersionByHeader also selects the version from a request header on
+// unprefixed paths. An empty name uses Accept-Version.
+func VersionByHeader(name string) VersionOption {
+	return func(v *apiVersion) {
+		if name = strings.TrimSpace(name); name == "" {
+			name = HeaderAcceptVersion
+		}
+		v.header = name
+	}
+}
+
+// VersionByMediaType also selects the version from the Accept header on
+// unprefixed paths, either as application/vnd.<vendor>.<version>+json or as
+// a version parameter such as application/json; version=2.
+func VersionByMediaType(vendor string) VersionOption {
+	return func(v *apiVersion) {
+		v.vendor = strings.ToLower(strings.TrimSpace(vendor))
+		v.mediaType = true
+	}
+}
+
+// VersionDefault serves unprefixed requests that name no version with this
+// version. It only applies together with VersionByHeader or VersionByMediaType.
+func VersionDefault() VersionOption {
+	return func(v *apiVersion) {
+		v.isDefault = true
+	}
+}
+
+// APIVersion returns the version that served the request, or "".
+func APIVersion(c fiber.Ctx) string {
+	version, _ := c.Locals(versionLocalsKey).(string)
+	return version
+}
+
+type versionRoute struct {
+	method  string // empty for All
+	pattern string // relative to the version prefix
+}
+
+type apiVersion struct {
+	name      string
+	base      string
+	prefix    string
+	parent    string
+	header    string
+	vendor    string
+	mediaType bool
+	isDefault bool
+	routes    []versionRoute
+}
+
+// VersionRegistry records the routes of every version so the version
+// resolver can rewrite requests to the version that serves them.
+type VersionRegistry struct {
+	mu       sync.RWMutex
+	versions []*apiVersion
+}
+
+func NewVersionRegistry() *VersionRegistry {
+	return &VersionRegistry{}
+}
+
+func (r *VersionRegistry) add(name, base string, opts []VersionOption) *apiVersion {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range r.versions {
+		if v.base == base && v.name == name {
+			for _, opt := range opts {
+				opt(v)
+			}
+			return v
+		}
+	}
+	v := &apiVersion{name: name, base: base, prefix: base + "/" + name}
+	for _, opt := range opts {
+		opt(v)
+	}
+	r.versions = append(r.versions, v)
+	return v
+}
+
+func (r *VersionRegistry) record(v *apiVersion, method, path string) {
+	pattern := strings.TrimPrefix(path, v.prefix)
+	if pattern == "" {
+		pattern = "/"
+	}
+	r.mu.Lock()
+	v.routes = append(v.routes, versionRoute{method: method, pattern: pattern})
+	r.mu.Unlock()
+}
+
+// InheritedRoute is a route a version serves from an ancestor version.
+// Method is empty for routes registered with All.
+type InheritedRoute struct {
+	Method string
+	Path   string // the path under the inheriting version
+	Source string // the path the ancestor registered
+}
+
+// InheritedRoutes returns the routes each version inherits and does not
+// declare itself, so OpenAPI documents can list them under the version.
+func (r *VersionRegistry) InheritedRoutes() []InheritedRoute {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []InheritedRoute
+	for _, v := range r.versions {
+		declared := append([]versionRoute(nil), v.routes...)
+		for current, seen := r.lookup(v.base, v.parent), 0; current != nil && seen < len(r.versions); current, seen = r.lookup(current.base, current.parent), seen+1 {
+			for _, route := range current.routes {
+				if slices.ContainsFunc(declared, func(d versionRoute) bool {
+					return d.pattern == route.pattern && (d.method == "" || d.method == route.method)
+				}) {
+					continue
+				}
+				declared = append(declared, route)
+				out = append(out, InheritedRoute{
+					Method: route.method,
+					Path:   joinVersionPath(v.prefix, route.pattern),
+					Source: joinVersionPath(current.prefix, route.pattern),
+				})
+			}
+		}
+	}
+	return out
+}
+
+func joinVersionPath(prefix, pattern string) string {
+	if pattern == "/" {
+		return prefix
+	}
+	return prefix + pattern
+}
+
+func (r *VersionRegistry) empty() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.versions) == 0
+}
+
+// resolve returns the path serving method and path, and the version it
+// belongs to. ok is false when the request is not versioned.
+func (r *VersionRegistry) resolve(c fiber.Ctx, method, path string) (target, version string, ok bool, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, rest := r.byPath(path)
+	negotiated := v == nil
+	if negotiated {
+		v, rest, err = r.byNegotiation(c, path)
+		if v == nil || err != nil {
+			return "", "", false, err
+		}
+	}
+
+	cfg := c.App().Config()
+	for current, seen := v, 0; current != nil && seen <= len(r.versions); current, seen = r.lookup(current.base, current.parent), seen+1 {
+		if current.declares(method, rest, cfg) {
+			return current.prefix + rest, v.name, true, nil
+		}
+	}
+	if negotiated {
+		// Leave unversioned routes such as health checks alone.
+		return "", "", false, nil
+	}
+	return path, v.name, true, nil
+}
+
+func (r *VersionRegistry) byPath(path string) (*apiVersion, string) {
+	for _, v := range r.versions {
+		if path == v.prefix {
+			return v, "/"
+		}
+		if strings.HasPrefix(path, v.prefix+"/") {
+			return v, path[len(v.prefix):]
+		}
+	}
+	return nil, ""
+}
+
+func (r *VersionRegistry) byNegotiation(c fiber.Ctx, path string) (*apiVersion, string, error) {
+	var (
+		fallback  *apiVersion
+		requested string
+		varies    []string
+	)
+	for _, v := range r.versions {
+		if v.header == "" && !v.mediaType {
+			continue
+		}
+		if v.base != "" && path != v.base && !strings.HasPrefix(path, v.base+"/") {
+			continue
+		}
+		if v.header != "" {
+			varies = append(varies, v.header)
+			if name := strings.TrimSpace(c.Get(v.header)); name != "" {
+				requested = name
+				if versionNameMatches(v.name, name) {
+					appendVaries(c, varies)
+					return v, path[len(v.base):], nil
+				}
+			}
+		}
+		if v.mediaType {
+			varies = append(varies, fiber.HeaderAccept)
+			if name := acceptedVersion(c.Get(fiber.HeaderAccept), v.vendor); name != "" {
+				requested = name
+				if versionNameMatches(v.name, name) {
+					appendVaries(c, varies)
+					return v, path[len(v.base):], nil
+				}
+			}
+		}
+		if v.isDefault && fallback == nil {
+			fallback = v
+		}
+	}
+	appendVaries(c, varies)
+	if requested != "" {
+		return nil, "", ErrUnsupportedVersion.WithDetails("version " + requested + " is not supported")
+	}
+	if fallback == nil {
+		return nil, "", nil
+	}
+	return fallback, path[len(fallback.base):], nil
+}
+
+func (r *VersionRegistry) lookup(base, name string) *apiVersion {
+	if name == "" {
+		return nil
+	}
+	for _, v := range r.versions {
+		if v.base == base && v.name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+func (v *apiVersion) declares(method, path string, cfg fiber.Config) bool {
+	if path == "" {
+		path = "/"
+	}
+	for _, route := range v.routes {
+		if route.method != "" && route.method != method && (method != fiber.MethodHead || route.method != fiber.MethodGet) {
+			continue
+		}
+		if fiber.RoutePatternMatch(path, route.pattern, cfg) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendVaries(c fiber.Ctx, names []string) {
+	for _, name := range names {
+		appendVary(c, name)
+	}
+}
+
+// versionNameMatches accepts "v2" for "2" and the other way around.
+func versionNameMatches(name, requested string) bool {
+	name, requested = strings.ToLower(name), strings.ToLower(requested)
+	return name == requested || strings.TrimPrefix(name, "v") == strings.TrimPrefix(requested, "v")
+}
+
+// acceptedVersion returns the version named by the first Accept entry that
+// carries one.
+func acceptedVersion(accept, vendor string) string {
+	for entry := range strings.SplitSeq(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(entry))
+		if err != nil {
+			continue
+		}
+		if version := strings.TrimSpace(params["version"]); version != "" {
+			return version
+		}
+		if vendor == "" {
+			continue
+		}
+		subtype, ok := strings.CutPrefix(mediaType, "application/vnd."+vendor+".")
+		if !ok {
+			continue
+		}
+		if i := strings.IndexByte(subtype, '+'); i >= 0 {
+			subtype = subtype[:i]
+		}
+		if subtype != "" {
+			return subtype
+		}
+	}
+	return ""
+}
+
+// VersionMiddleware routes versioned requests. Header and media-type
+// versioned requests are rewritten to the version prefix, and requests for
+// routes a version inherits are rewritten to the version declaring them.
+//
+// It runs before every other middleware so they see the rewritten path.
+type VersionMiddleware struct {
+	registry *VersionRegistry
+}
+
+func NewVersionMiddleware(registries *RegistryContainer) *VersionMiddleware {
+	return &VersionMiddleware{registry: registries.Versions}
+}
+
+func (m *VersionMiddleware) Handle(r Router) {
+	if m.registry == nil {
+		return
+	}
+	r.Use(m.Middleware)
+}
+
+func (m *VersionMiddleware) Middleware(c fiber.Ctx) error {
+	return versionResolver(m.registry)(c)
+}
+
+func versionResolver(registry *VersionRegistry) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if c.Locals(versionResolvedKey) != nil || registry.empty() {
+			return c.Next()
+		}
+		c.Locals(versionResolvedKey, true)
+
+		path := c.Path()
+		target, version, ok, err := registry.resolve(c, c.Method(), path)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return c.Next()
+		}
+		c.Locals(versionLocalsKey, version)
+		if target == path {
+			return c.Next()
+		}
+		// Routing already picked routes for the original path, so start over.
+		c.Path(target)
+		return c.RestartRouting()
+	}
+}
+
+// Version returns a router for the API version name, mounted at "/"+name
+// under this router. See VersionInherit, VersionByHeader,
+// VersionByMediaType and VersionDefault.
+//
+// Usage:
+//
+//	v1 := r.Version("v1", web.VersionByHeader(""), web.VersionDefault())
+//	v2 := r.Version("v2", web.VersionByHeader(""), web.VersionInherit("v1"))
+func (r *routerWrapper) Version(name string, opts ...VersionOption) Router {
+	name = strings.Trim(strings.TrimSpace(name), "/")
+	if r.versions == nil {
+		// Routers without a RegistryContainer have no VersionMiddleware, so
+		// resolve versions from here.
+		r.versions = NewVersionRegistry()
+		r.fiberRouter.Use(versionResolver(r.versions))
+	}
+	base := resolveRegisteredPath(r.fiberRouter, "")
+	if base == "/" {
+		base = ""
+	}
+	version := r.versions.add(name, base, opts)
+
+	group := r.Group("/" + name).(*routerWrapper)
+	group.version = version
+	return group
+}
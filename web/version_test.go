@@ -0,0 +1,131 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func newVersionTestApp(t *testing.T) (*fiber.App, *RegistryContainer) {
+	t.Helper()
+
+	registries := NewRegistryContainer()
+	handler := NewErrorHandler(Config{}, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: handler.HandleError})
+	r := NewRouterWithRegistries(app, registries)
+	NewVersionMiddleware(registries).Handle(r)
+
+	r.Get("/healthz", func(c fiber.Ctx) error { return c.SendString("ok") })
+	api := r.Group("/api")
+	v1 := api.Version("v1", VersionByHeader(""), VersionByMediaType("acme"), VersionDefault())
+	v2 := api.Version("v2", VersionByHeader(""), VersionByMediaType("acme"), VersionInherit("v1"))
+
+	users := v1.Group("/users")
+	users.Get("/", func(c fiber.Ctx) error { return c.SendString("v1 list " + APIVersion(c)) })
+	users.Get("/:id", func(c fiber.Ctx) error { return c.SendString("v1 user " + c.Params("id")) }).
+		DeprecatedSince(time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC), "https://docs.example.com/v2")
+	v2.Get("/users/:id", func(c fiber.Ctx) error { return c.SendString("v2 user " + c.Params("id")) })
+	return app, registries
+}
+
+func TestVersionRoutesByPathAndInheritsUndeclaredRoutes(t *testing.T) {
+	app, _ := newVersionTestApp(t)
+
+	cases := map[string]string{
+		"/api/v1/users/7": "v1 user 7",
+		"/api/v2/users/7": "v2 user 7",
+		"/api/v2/users":   "v1 list v2",
+		"/healthz":        "ok",
+	}
+	for path, want := range cases {
+		res, body := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, path, nil))
+		if res.StatusCode != http.StatusOK || string(body) != want {
+			t.Fatalf("%s: status=%d body=%q want %q", path, res.StatusCode, body, want)
+		}
+	}
+
+	res, _ := doErrorRequest(t, app, httptest.NewRequest(http.MethodPost, "/api/v2/users", nil))
+	if res.StatusCode != http.StatusMethodNotAllowed && res.StatusCode != http.StatusNotFound {
+		t.Fatalf("undeclared method must not be served, got %d", res.StatusCode)
+	}
+}
+
+func TestVersionNegotiatesHeaderAndMediaType(t *testing.T) {
+	app, _ := newVersionTestApp(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/7", nil)
+	req.Header.Set(HeaderAcceptVersion, "v2")
+	res, body := doErrorRequest(t, app, req)
+	if string(body) != "v2 user 7" {
+		t.Fatalf("header version: status=%d body=%q", res.StatusCode, body)
+	}
+	if !headerHasToken(res.Header.Get(fiber.HeaderVary), HeaderAcceptVersion) {
+		t.Fatalf("vary must name Accept-Version, got %q", res.Header.Get(fiber.HeaderVary))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/users/7", nil)
+	req.Header.Set(fiber.HeaderAccept, "application/vnd.acme.v2+json")
+	if _, body = doErrorRequest(t, app, req); string(body) != "v2 user 7" {
+		t.Fatalf("media type version: body=%q", body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/users/7", nil)
+	req.Header.Set(fiber.HeaderAccept, "application/json; version=1")
+	if _, body = doErrorRequest(t, app, req); string(body) != "v1 user 7" {
+		t.Fatalf("version parameter: body=%q", body)
+	}
+
+	if _, body = doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/api/users", nil)); string(body) != "v1 list v1" {
+		t.Fatalf("default version: body=%q", body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	req.Header.Set(HeaderAcceptVersion, "v9")
+	res, body = doErrorRequest(t, app, req)
+	if res.StatusCode != http.StatusBadRequest || decodeErrorBody(t, body).Error.Code != "UNSUPPORTED_VERSION" {
+		t.Fatalf("unknown version: status=%d body=%s", res.StatusCode, body)
+	}
+}
+
+func TestDeprecatedRouteSetsHeadersAndMetadata(t *testing.T) {
+	app, registries := newVersionTestApp(t)
+
+	res, _ := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/api/v1/users/7", nil))
+	if res.Header.Get(HeaderDeprecation) != "@1780272000" {
+		t.Fatalf("deprecation header: %q", res.Header.Get(HeaderDeprecation))
+	}
+	if got := res.Header.Get(HeaderSunset); got != "Fri, 01 Jan 2027 00:00:00 GMT" {
+		t.Fatalf("sunset header: %q", got)
+	}
+	if got := res.Header.Get(fiber.HeaderLink); got != `<https://docs.example.com/v2>; rel="deprecation"` {
+		t.Fatalf("link header: %q", got)
+	}
+
+	res, _ = doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/api/v2/users/7", nil))
+	if res.Header.Get(HeaderDeprecation) != "" {
+		t.Fatal("override in v2 must not be deprecated")
+	}
+
+	meta := registries.Metadata.GetRoute(http.MethodGet, "/api/v1/users/:id")
+	if meta == nil || meta.Deprecation == nil || meta.Deprecation.Link != "https://docs.example.com/v2" {
+		t.Fatalf("deprecation metadata: %+v", meta)
+	}
+}
+
+func TestDeprecatedWithoutDateOmitsDeprecationHeader(t *testing.T) {
+	app := fiber.New()
+	NewRouterWithRegistry(app, nil).Get("/old", func(c fiber.Ctx) error { return c.SendString("ok") }).
+		Deprecated(time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC), "")
+
+	res, _ := doErrorRequest(t, app, httptest.NewRequest(http.MethodGet, "/old", nil))
+	if got := res.Header.Get(HeaderDeprecation); got != "" {
+		t.Fatalf("deprecation header must not be dated at startup: %q", got)
+	}
+	if got := res.Header.Get(HeaderSunset); got != "Fri, 01 Jan 2027 00:00:00 GMT" {
+		t.Fatalf("sunset header: %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import "time"
+
+type Config struct {
+	// Disabled stops the delivery worker; Publish still records deliveries.
+	Disabled       bool          `mapstructure:"disabled" default:"false"`
+	Workers        int           `mapstructure:"workers" default:"4"`
+	PollInterval   time.Duration `mapstructure:"poll_interval" default:"1s"`
+	BatchSize      int           `mapstructure:"batch_size" default:"50"`
+	Timeout        time.Duration `mapstructure:"timeout" default:"10s"`
+	MaxAttempts    int           `mapstructure:"max_attempts" default:"8"`
+	InitialBackoff time.Duration `mapstructure:"initial_backoff" default:"30s"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff" default:"6h"`
+	// DisableAfter disables an endpoint after that many consecutive failed
+	// attempts. A negative value never disables.
+	DisableAfter int `mapstructure:"disable_after" default:"20"`
+	// Lease is how long a claimed delivery stays hidden from other workers.
+	Lease time.Duration `mapstructure:"lease" default:"1m"`
+	// AllowPrivateTargets lets endpoints resolve to loopback, private,
+	// link-local and other internal addresses, e.g. in development.
+	AllowPrivateTargets bool   `mapstructure:"allow_private_targets" default:"false"`
+	AdminPrefix         string `mapstructure:"admin_prefix" default:"/admin/webhooks"`
+}
+
+const (
+	DefaultWorkers        = 4
+	DefaultPollInterval   = time.Second
+	DefaultBatchSize      = 50
+	DefaultTimeout        = 10 * time.Second
+	DefaultMaxAttempts    = 8
+	DefaultInitialBackoff = 30 * time.Second
+	DefaultMaxBackoff     = 6 * time.Hour
+	DefaultDisableAfter   = 20
+	DefaultLease          = time.Minute
+	DefaultAdminPrefix    = "/admin/webhooks"
+)
+
+// withDefaults fills in the values a zero Config leaves empty.
+func (c Config) withDefaults() Config {
+	if c.Workers <= 0 {
+		c.Workers = DefaultWorkers
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = DefaultPollInterval
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultBatchSize
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = DefaultMaxAttempts
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = DefaultInitialBackoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultMaxBackoff
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		c.MaxBackoff = c.InitialBackoff
+	}
+	if c.DisableAfter == 0 {
+		c.DisableAfter = DefaultDisableAfter
+	}
+	if c.Lease <= 0 {
+		c.Lease = DefaultLease
+	}
+	if c.Lease < c.Timeout {
+		c.Lease = 2 * c.Timeout
+	}
+	if c.AdminPrefix == "" {
+		c.AdminPrefix = DefaultAdminPrefix
+	}
+	return c
+}
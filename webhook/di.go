@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"github.com/bronystylecrazy/ultrastructure/cfg"
+	"github.com/bronystylecrazy/ultrastructure/di"
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	"github.com/gofiber/fiber/v3"
+)
+
+var OtelScope = "webhook"
+
+// Providers registers the webhook dispatcher, backed by a MemoryStore unless
+// a Store is provided, e.g. by x/gorm or x/sqlc.
+//
+// Usage: us.New(webhook.Providers(webhook.UseAdmin(authn.Any(...))), ...)
+func Providers(extends ...di.Node) di.Node {
+	nodes := []any{
+		cfg.Config[Config]("webhook", cfg.WithSourceFile("config.toml"), cfg.WithType("toml")),
+		di.Default(NewMemoryStore, di.As[Store]()),
+		di.Provide(NewDispatcher, otel.Layer(OtelScope)),
+		di.Provide(NewErrorMapper),
+	}
+	nodes = append(nodes, di.ConvertAnys(extends)...)
+	return di.Options(nodes...)
+}
+
+// UseStore replaces the in-memory store.
+func UseStore(store Store) di.Node {
+	return di.Supply(store, di.As[Store]())
+}
+
+// UseAdmin mounts the admin API behind guards, which should authenticate and
+// authorize the caller.
+//
+// Usage: webhook.UseAdmin(authn.Any(authn.UserTokenAuthenticator(users)), authz.RequireUserRole("admin"))
+func UseAdmin(guards ...fiber.Handler) di.Node {
+	return di.Provide(func(config Config, dispatcher *Dispatcher) *AdminHandler {
+		return NewAdminHandler(config, dispatcher, guards...)
+	})
+}
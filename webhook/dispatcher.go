@@ -0,0 +1,419 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	"github.com/bronystylecrazy/ultrastructure/web/httpclient"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// Metrics recorded by the dispatcher.
+const (
+	AttemptsMetric         = "webhook.delivery.attempts"
+	AttemptDurationMetric  = "webhook.delivery.duration"
+	DeadLetteredMetric     = "webhook.delivery.dead_lettered"
+	EndpointDisabledMetric = "webhook.endpoint.disabled"
+)
+
+// ErrInvalidEndpoint is returned by Register for endpoints without an
+// absolute http(s) URL.
+var ErrInvalidEndpoint = errors.New("webhook: endpoint needs an absolute http or https url")
+
+// maxResponseBody is how much of a response is read so the connection can be
+// reused; the body itself is not stored.
+const maxResponseBody = 64 << 10
+
+// Dispatcher fans published events out to subscribed endpoints and delivers
+// them in the background with exponential backoff. Deliveries that exhaust
+// MaxAttempts are dead-lettered, and endpoints failing DisableAfter times in
+// a row are disabled.
+type Dispatcher struct {
+	otel.Telemetry
+
+	config   Config
+	store    Store
+	client   *httpclient.Client
+	resolver *net.Resolver
+	now      func() time.Time
+
+	endpointMu sync.Mutex
+	wake       chan struct{}
+	cancel     context.CancelFunc
+	abort      context.CancelFunc
+	done       chan struct{}
+}
+
+func NewDispatcher(config Config, store Store) (*Dispatcher, error) {
+	d := &Dispatcher{
+		Telemetry: otel.Nop(),
+		config:    config.withDefaults(),
+		store:     store,
+		resolver:  net.DefaultResolver,
+		now:       time.Now,
+		wake:      make(chan struct{}, 1),
+	}
+	client, err := httpclient.New("webhook", httpclient.ClientConfig{
+		Timeout: d.config.Timeout,
+		// The dispatcher retries on its own schedule.
+		Retry:          httpclient.RetryConfig{MaxAttempts: 1},
+		CircuitBreaker: httpclient.BreakerConfig{Disabled: true},
+		DialControl:    d.dialControl(),
+	}, &d.Telemetry)
+	if err != nil {
+		return nil, err
+	}
+	// A redirect is a failed delivery; receivers must answer at their URL.
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	d.client = client
+	return d, nil
+}
+
+// Store returns the dispatcher's store.
+func (d *Dispatcher) Store() Store {
+	return d.store
+}
+
+// Register validates and saves a new endpoint, generating its ID and, when
+// empty, its secret. Endpoints resolving to internal addresses are rejected
+// with ErrForbiddenTarget unless Config.AllowPrivateTargets is set.
+func (d *Dispatcher) Register(ctx context.Context, endpoint Endpoint) (Endpoint, error) {
+	u, err := url.Parse(endpoint.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Endpoint{}, ErrInvalidEndpoint
+	}
+	if err := d.checkTarget(ctx, u); err != nil {
+		return Endpoint{}, err
+	}
+	if endpoint.Secret == "" {
+		if endpoint.Secret, err = NewSecret(); err != nil {
+			return Endpoint{}, err
+		}
+	}
+	now := d.now().UTC()
+	endpoint.ID = uuid.NewString()
+	endpoint.Disabled, endpoint.DisabledReason, endpoint.ConsecutiveFailures = false, "", 0
+	endpoint.CreatedAt, endpoint.UpdatedAt = now, now
+	if err := d.store.SaveEndpoint(ctx, endpoint); err != nil {
+		return Endpoint{}, err
+	}
+	return endpoint, nil
+}
+
+// Enable turns a disabled endpoint back on and resets its failure count.
+func (d *Dispatcher) Enable(ctx context.Context, id string) (Endpoint, error) {
+	d.endpointMu.Lock()
+	defer d.endpointMu.Unlock()
+	endpoint, err := d.store.Endpoint(ctx, id)
+	if err != nil {
+		return Endpoint{}, err
+	}
+	endpoint.Disabled, endpoint.DisabledReason, endpoint.ConsecutiveFailures = false, "", 0
+	endpoint.UpdatedAt = d.now().UTC()
+	return endpoint, d.store.SaveEndpoint(ctx, endpoint)
+}
+
+// Publish queues payload, encoded as JSON, for every enabled endpoint
+// subscribed to eventType and wakes the worker.
+//
+// Usage: _, err := dispatcher.Publish(ctx, "order.created", order)
+func (d *Dispatcher) Publish(ctx context.Context, eventType string, payload any) ([]Delivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: encode %s payload: %w", eventType, err)
+	}
+	endpoints, err := d.store.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := d.now().UTC()
+	eventID := uuid.NewString()
+	var deliveries []Delivery
+	for _, endpoint := range endpoints {
+		if endpoint.Disabled || !endpoint.Matches(eventType) {
+			continue
+		}
+		deliveries = append(deliveries, Delivery{
+			ID:            uuid.NewString(),
+			EndpointID:    endpoint.ID,
+			EventID:       eventID,
+			EventType:     eventType,
+			Payload:       body,
+			Status:        DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil, nil
+	}
+	if err := d.store.CreateDeliveries(ctx, deliveries); err != nil {
+		return nil, err
+	}
+	d.notify()
+	return deliveries, nil
+}
+
+// Redeliver queues a delivery again, dead-lettered or not, keeping its
+// attempt history.
+func (d *Dispatcher) Redeliver(ctx context.Context, id string) (Delivery, error) {
+	delivery, err := d.store.Delivery(ctx, id)
+	if err != nil {
+		return Delivery{}, err
+	}
+	now := d.now().UTC()
+	delivery.Status = DeliveryPending
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+	if err := d.store.UpdateDelivery(ctx, delivery); err != nil {
+		return Delivery{}, err
+	}
+	d.notify()
+	return delivery, nil
+}
+
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs the delivery worker until Stop, unless the config disables it.
+func (d *Dispatcher) Start(context.Context) error {
+	if d.config.Disabled {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	sendCtx, abort := context.WithCancel(context.Background())
+	d.cancel, d.abort = cancel, abort
+	d.done = make(chan struct{})
+	go d.run(ctx, sendCtx)
+	return nil
+}
+
+// Stop stops claiming deliveries and lets in-flight ones finish. When ctx
+// ends first they are aborted without recording an attempt, and their lease
+// hands them to the next worker.
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	if d.cancel == nil {
+		return nil
+	}
+	d.cancel()
+	defer d.abort()
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) run(ctx, sendCtx context.Context) {
+	defer close(d.done)
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		for ctx.Err() == nil {
+			n, err := d.RunOnce(sendCtx)
+			if err != nil && sendCtx.Err() == nil {
+				d.Obs.Warn("webhook: claim deliveries failed", zap.Error(err))
+			}
+			// A full batch means more may be due already.
+			if n < d.config.BatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// RunOnce sends the deliveries due now and returns how many it claimed.
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	deliveries, err := d.store.ClaimDeliveries(ctx, d.now().UTC(), d.config.Lease, d.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	sem := make(chan struct{}, d.config.Workers)
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(delivery Delivery) {
+			defer func() { <-sem; wg.Done() }()
+			d.deliver(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery Delivery) {
+	ctx, span := d.Obs.Start(ctx, "webhook.deliver")
+	defer span.End()
+
+	endpoint, err := d.store.Endpoint(ctx, delivery.EndpointID)
+	var attempt Attempt
+	switch {
+	case errors.Is(err, ErrNotFound):
+		attempt = d.newAttempt(delivery, "endpoint deleted")
+	case err != nil:
+		span.Warn("webhook: load endpoint failed", zap.String("webhook.delivery_id", delivery.ID), zap.Error(err))
+		return
+	case endpoint.Disabled:
+		attempt = d.newAttempt(delivery, "endpoint disabled")
+	default:
+		attempt = d.send(ctx, endpoint, delivery)
+	}
+	if ctx.Err() != nil {
+		// Aborted by Stop: not the endpoint's fault, so the delivery keeps
+		// its lease and is retried without counting an attempt.
+		span.Debug("webhook: delivery aborted", zap.String("webhook.delivery_id", delivery.ID))
+		return
+	}
+
+	now := d.now().UTC()
+	delivery.Attempts++
+	delivery.LastStatusCode = attempt.StatusCode
+	delivery.LastError = attempt.Error
+	delivery.UpdatedAt = now
+	switch {
+	case attempt.Succeeded():
+		delivery.Status = DeliverySucceeded
+	case delivery.Attempts >= d.config.MaxAttempts || err != nil || endpoint.Disabled:
+		delivery.Status = DeliveryDead
+	default:
+		delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("webhook.event_type", delivery.EventType),
+		attribute.Bool("webhook.succeeded", attempt.Succeeded()),
+		attribute.Int("http.response.status_code", attempt.StatusCode),
+	}
+	span.AddCounter(ctx, AttemptsMetric, 1, attrs...)
+	span.RecordHistogram(ctx, AttemptDurationMetric, float64(attempt.LatencyMS), attrs...)
+	fields := []zap.Field{
+		zap.String("webhook.delivery_id", delivery.ID),
+		zap.String("webhook.endpoint_id", delivery.EndpointID),
+		zap.String("webhook.event_type", delivery.EventType),
+		zap.Int("attempt", attempt.Number),
+		zap.Int("http.response.status_code", attempt.StatusCode),
+		zap.Int64("latency_ms", attempt.LatencyMS),
+	}
+	if delivery.Status == DeliveryDead {
+		span.AddCounter(ctx, DeadLetteredMetric, 1, attribute.String("webhook.event_type", delivery.EventType))
+		span.Warn("webhook: delivery dead-lettered", append(fields, zap.String("error", attempt.Error))...)
+	} else if !attempt.Succeeded() {
+		span.Debug("webhook: delivery attempt failed", append(fields, zap.String("error", attempt.Error), zap.Time("next_attempt_at", delivery.NextAttemptAt))...)
+	}
+
+	if err := d.store.RecordAttempt(ctx, delivery, attempt); err != nil {
+		span.Warn("webhook: record attempt failed", zap.String("webhook.delivery_id", delivery.ID), zap.Error(err))
+	}
+	if err == nil && !endpoint.Disabled {
+		d.recordEndpointResult(ctx, endpoint.ID, attempt.Succeeded())
+	}
+}
+
+func (d *Dispatcher) newAttempt(delivery Delivery, errMsg string) Attempt {
+	return Attempt{
+		ID:         uuid.NewString(),
+		DeliveryID: delivery.ID,
+		Number:     delivery.Attempts + 1,
+		Error:      errMsg,
+		CreatedAt:  d.now().UTC(),
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, endpoint Endpoint, delivery Delivery) Attempt {
+	attempt := d.newAttempt(delivery, "")
+	sentAt := d.now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, delivery.ID)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(sentAt.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, sentAt, delivery.Payload))
+
+	res, err := d.client.Do(req)
+	attempt.LatencyMS = time.Since(sentAt).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxResponseBody))
+	_ = res.Body.Close()
+	attempt.StatusCode = res.StatusCode
+	if !attempt.Succeeded() {
+		attempt.Error = "unexpected status " + strconv.Itoa(res.StatusCode)
+	}
+	return attempt
+}
+
+// recordEndpointResult tracks consecutive failures and disables the endpoint
+// at DisableAfter.
+func (d *Dispatcher) recordEndpointResult(ctx context.Context, id string, succeeded bool) {
+	d.endpointMu.Lock()
+	defer d.endpointMu.Unlock()
+
+	endpoint, err := d.store.Endpoint(ctx, id)
+	if err != nil || (succeeded && endpoint.ConsecutiveFailures == 0) {
+		return
+	}
+	if succeeded {
+		endpoint.ConsecutiveFailures = 0
+	} else {
+		endpoint.ConsecutiveFailures++
+		if d.config.DisableAfter > 0 && endpoint.ConsecutiveFailures >= d.config.DisableAfter && !endpoint.Disabled {
+			endpoint.Disabled = true
+			endpoint.DisabledReason = strconv.Itoa(endpoint.ConsecutiveFailures) + " consecutive failed deliveries"
+			d.Obs.AddCounter(ctx, EndpointDisabledMetric, 1)
+			d.Obs.Warn("webhook: endpoint disabled", zap.String("webhook.endpoint_id", id), zap.String("url.full", endpoint.URL), zap.Int("failures", endpoint.ConsecutiveFailures))
+		}
+	}
+	endpoint.UpdatedAt = d.now().UTC()
+	if err := d.store.SaveEndpoint(ctx, endpoint); err != nil {
+		d.Obs.Warn("webhook: save endpoint failed", zap.String("webhook.endpoint_id", id), zap.Error(err))
+	}
+}
+
+// backoff returns the delay before the attempt after attempt, doubling from
+// InitialBackoff up to MaxBackoff with equal jitter.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.config.InitialBackoff
+	for i := 1; i < attempt && delay < d.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.config.MaxBackoff {
+		delay = d.config.MaxBackoff
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
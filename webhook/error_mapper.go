@@ -0,0 +1,26 @@
+package webhook
+
+import (
+	"net/http"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+)
+
+// ErrorMapper renders webhook errors through the web error handler.
+type ErrorMapper struct {
+	mappings web.ErrorMappings
+}
+
+func NewErrorMapper() *ErrorMapper {
+	return &ErrorMapper{
+		mappings: web.ErrorMappings{
+			{Target: ErrNotFound, Status: http.StatusNotFound, Code: "WEBHOOK_NOT_FOUND", Message: "webhook not found"},
+			{Target: ErrInvalidEndpoint, Status: http.StatusBadRequest, Code: "WEBHOOK_INVALID_ENDPOINT", Message: "endpoint needs an absolute http or https url"},
+			{Target: ErrForbiddenTarget, Status: http.StatusBadRequest, Code: "WEBHOOK_FORBIDDEN_TARGET", Message: "endpoint resolves to a private address"},
+		},
+	}
+}
+
+func (m *ErrorMapper) MapError(err error) (*web.HTTPError, bool) {
+	return m.mappings.MapError(err)
+}
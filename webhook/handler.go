@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
+
+// AdminHandler serves the webhook admin API under Config.AdminPrefix:
+// endpoint registration, the delivery log and redelivery. Secrets are only
+// returned when an endpoint is created.
+type AdminHandler struct {
+	config     Config
+	dispatcher *Dispatcher
+	guards     []fiber.Handler
+}
+
+func NewAdminHandler(config Config, dispatcher *Dispatcher, guards ...fiber.Handler) *AdminHandler {
+	return &AdminHandler{
+		config:     config.withDefaults(),
+		dispatcher: dispatcher,
+		guards:     guards,
+	}
+}
+
+type CreateEndpointRequest struct {
+	URL         string   `json:"url"`
+	Secret      string   `json:"secret,omitempty"`
+	Events      []string `json:"events,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+type EndpointParams struct {
+	ID string `uri:"id" json:"-"`
+}
+
+type DeliveryParams struct {
+	ID string `uri:"id" json:"-"`
+}
+
+type ListDeliveriesRequest struct {
+	EndpointID string         `query:"endpoint_id" json:"-"`
+	Status     DeliveryStatus `query:"status" json:"-"`
+	Limit      int            `query:"limit" json:"-"`
+}
+
+// DeliveryDetail is a delivery with its attempts.
+type DeliveryDetail struct {
+	Delivery
+	AttemptLog []Attempt `json:"attempt_log"`
+}
+
+func (h *AdminHandler) Handle(r web.Router) {
+	g := r.Group(h.config.AdminPrefix, h.guards...).Tags("Webhooks")
+
+	g.Get("/endpoints").With(web.HandleQuery(h.ListEndpoints)).Summary("List webhook endpoints")
+	g.Post("/endpoints").With(web.Handle(h.CreateEndpoint)).Summary("Register a webhook endpoint")
+	g.Get("/endpoints/:id").With(web.HandleParams(h.GetEndpoint)).Summary("Get a webhook endpoint")
+	g.Delete("/endpoints/:id").With(web.HandleParams(h.DeleteEndpoint)).Summary("Delete a webhook endpoint")
+	g.Post("/endpoints/:id/enable").With(web.HandleParams(h.EnableEndpoint)).Summary("Re-enable a disabled endpoint")
+
+	g.Get("/deliveries").With(web.HandleQuery(h.ListDeliveries)).Summary("List webhook deliveries")
+	g.Get("/deliveries/:id").With(web.HandleParams(h.GetDelivery)).Summary("Get a delivery with its attempts")
+	g.Post("/deliveries/:id/redeliver").With(web.HandleParams(h.Redeliver)).
+		Summary("Queue a delivery again").
+		Produces(Delivery{}, http.StatusAccepted)
+}
+
+func (h *AdminHandler) ListEndpoints(ctx context.Context, _ web.Empty) ([]Endpoint, error) {
+	endpoints, err := h.dispatcher.Store().Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range endpoints {
+		endpoints[i].Secret = ""
+	}
+	return endpoints, nil
+}
+
+func (h *AdminHandler) CreateEndpoint(ctx context.Context, req CreateEndpointRequest) (Endpoint, error) {
+	return h.dispatcher.Register(ctx, Endpoint{
+		URL:         req.URL,
+		Secret:      req.Secret,
+		Events:      req.Events,
+		Description: req.Description,
+	})
+}
+
+func (h *AdminHandler) GetEndpoint(ctx context.Context, req EndpointParams) (Endpoint, error) {
+	endpoint, err := h.dispatcher.Store().Endpoint(ctx, req.ID)
+	endpoint.Secret = ""
+	return endpoint, err
+}
+
+func (h *AdminHandler) DeleteEndpoint(ctx context.Context, req EndpointParams) (web.Empty, error) {
+	return web.Empty{}, h.dispatcher.Store().DeleteEndpoint(ctx, req.ID)
+}
+
+func (h *AdminHandler) EnableEndpoint(ctx context.Context, req EndpointParams) (Endpoint, error) {
+	endpoint, err := h.dispatcher.Enable(ctx, req.ID)
+	endpoint.Secret = ""
+	return endpoint, err
+}
+
+func (h *AdminHandler) ListDeliveries(ctx context.Context, req ListDeliveriesRequest) ([]Delivery, error) {
+	return h.dispatcher.Store().Deliveries(ctx, DeliveryFilter{
+		EndpointID: req.EndpointID,
+		Status:     req.Status,
+		Limit:      req.Limit,
+	})
+}
+
+func (h *AdminHandler) GetDelivery(ctx context.Context, req DeliveryParams) (DeliveryDetail, error) {
+	delivery, err := h.dispatcher.Store().Delivery(ctx, req.ID)
+	if err != nil {
+		return DeliveryDetail{}, err
+	}
+	attempts, err := h.dispatcher.Store().Attempts(ctx, req.ID)
+	if err != nil {
+		return DeliveryDetail{}, err
+	}
+	return DeliveryDetail{Delivery: delivery, AttemptLog: attempts}, nil
+}
+
+func (h *AdminHandler) Redeliver(ctx context.Context, req DeliveryParams) (Delivery, error) {
+	if c, ok := web.FiberCtx(ctx); ok {
+		c.Status(http.StatusAccepted)
+	}
+	return h.dispatcher.Redeliver(ctx, req.ID)
+}
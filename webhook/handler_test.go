@@ -0,0 +1,43 @@
+package webhook_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/ustest"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/bronystylecrazy/ultrastructure/webhook"
+)
+
+func TestAdminHandlerRedeliversDeadDeliveries(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_TARGETS", "true")
+	store := webhook.NewMemoryStore()
+	app := ustest.Start(t,
+		webhook.Providers(webhook.UseStore(store), webhook.UseAdmin()),
+		web.InitHandlers(),
+	)
+	client := app.HTTP()
+	ctx := context.Background()
+
+	var endpoint webhook.Endpoint
+	client.Post("/admin/webhooks/endpoints").
+		JSON(webhook.CreateEndpointRequest{URL: "http://127.0.0.1:1/hook"}).
+		Expect(http.StatusCreated).
+		JSON(&endpoint)
+	if endpoint.ID == "" || endpoint.Secret == "" {
+		t.Fatalf("endpoint: %+v", endpoint)
+	}
+	client.Get("/admin/webhooks/endpoints/"+endpoint.ID).Expect(http.StatusOK).JSONPath("url", endpoint.URL)
+	client.Post("/admin/webhooks/endpoints").JSON(webhook.CreateEndpointRequest{URL: "ftp://example.com"}).Expect(http.StatusBadRequest)
+
+	dead := webhook.Delivery{ID: "d-1", EndpointID: endpoint.ID, EventType: "order.created", Payload: []byte(`{}`), Status: webhook.DeliveryDead, Attempts: 8, CreatedAt: time.Now()}
+	if err := store.CreateDeliveries(ctx, []webhook.Delivery{dead}); err != nil {
+		t.Fatalf("CreateDeliveries: %v", err)
+	}
+	client.Get("/admin/webhooks/deliveries").Query("status", "dead").Expect(http.StatusOK).JSONPath("0.id", "d-1")
+	client.Post("/admin/webhooks/deliveries/d-1/redeliver").Expect(http.StatusAccepted).JSONPath("status", "pending")
+	client.Post("/admin/webhooks/deliveries/missing/redeliver").Expect(http.StatusNotFound)
+	client.Get("/admin/webhooks/deliveries/d-1").Expect(http.StatusOK).JSONPath("event_type", "order.created")
+}
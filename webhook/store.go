@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned for unknown endpoint or delivery IDs.
+var ErrNotFound = errors.New("webhook: not found")
+
+// DeliveryFilter narrows Store.Deliveries.
+type DeliveryFilter struct {
+	EndpointID string
+	Status     DeliveryStatus
+	Limit      int
+}
+
+const DefaultListLimit = 100
+
+// Store persists endpoints, deliveries and attempts. xgorm.WebhookStore and
+// sqlc.WebhookStore keep them in the application database.
+type Store interface {
+	SaveEndpoint(ctx context.Context, endpoint Endpoint) error
+	Endpoint(ctx context.Context, id string) (Endpoint, error)
+	Endpoints(ctx context.Context) ([]Endpoint, error)
+	DeleteEndpoint(ctx context.Context, id string) error
+
+	CreateDeliveries(ctx context.Context, deliveries []Delivery) error
+	// ClaimDeliveries returns up to limit pending deliveries due at now and
+	// moves their NextAttemptAt to now+lease, so no other worker claims them
+	// while they are sent.
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error)
+	// RecordAttempt stores attempt and the delivery state it led to.
+	RecordAttempt(ctx context.Context, delivery Delivery, attempt Attempt) error
+	UpdateDelivery(ctx context.Context, delivery Delivery) error
+	Delivery(ctx context.Context, id string) (Delivery, error)
+	// Deliveries lists deliveries newest first.
+	Deliveries(ctx context.Context, filter DeliveryFilter) ([]Delivery, error)
+	// Attempts lists the attempts of a delivery oldest first.
+	Attempts(ctx context.Context, deliveryID string) ([]Attempt, error)
+}
+
+// MemoryStore keeps webhooks in process memory. Deliveries do not survive a
+// restart; use xgorm.WebhookStore for durable delivery.
+type MemoryStore struct {
+	mu         sync.Mutex
+	endpoints  map[string]Endpoint
+	deliveries map[string]Delivery
+	attempts   map[string][]Attempt
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		endpoints:  make(map[string]Endpoint),
+		deliveries: make(map[string]Delivery),
+		attempts:   make(map[string][]Attempt),
+	}
+}
+
+func (s *MemoryStore) SaveEndpoint(_ context.Context, endpoint Endpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	endpoint.Events = slices.Clone(endpoint.Events)
+	s.endpoints[endpoint.ID] = endpoint
+	return nil
+}
+
+func (s *MemoryStore) Endpoint(_ context.Context, id string) (Endpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	endpoint, ok := s.endpoints[id]
+	if !ok {
+		return Endpoint{}, ErrNotFound
+	}
+	return endpoint, nil
+}
+
+func (s *MemoryStore) Endpoints(_ context.Context) ([]Endpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Endpoint, 0, len(s.endpoints))
+	for _, endpoint := range s.endpoints {
+		out = append(out, endpoint)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (s *MemoryStore) DeleteEndpoint(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.endpoints[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.endpoints, id)
+	return nil
+}
+
+func (s *MemoryStore) CreateDeliveries(_ context.Context, deliveries []Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, delivery := range deliveries {
+		s.deliveries[delivery.ID] = delivery
+	}
+	return nil
+}
+
+func (s *MemoryStore) ClaimDeliveries(_ context.Context, now time.Time, lease time.Duration, limit int) ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []Delivery
+	for _, delivery := range s.deliveries {
+		if delivery.Status == DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for i := range due {
+		due[i].NextAttemptAt = now.Add(lease)
+		s.deliveries[due[i].ID] = due[i]
+	}
+	return due, nil
+}
+
+func (s *MemoryStore) RecordAttempt(_ context.Context, delivery Delivery, attempt Attempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[delivery.ID] = delivery
+	s.attempts[delivery.ID] = append(s.attempts[delivery.ID], attempt)
+	return nil
+}
+
+func (s *MemoryStore) UpdateDelivery(_ context.Context, delivery Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.deliveries[delivery.ID]; !ok {
+		return ErrNotFound
+	}
+	s.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (s *MemoryStore) Delivery(_ context.Context, id string) (Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delivery, ok := s.deliveries[id]
+	if !ok {
+		return Delivery{}, ErrNotFound
+	}
+	return delivery, nil
+}
+
+func (s *MemoryStore) Deliveries(_ context.Context, filter DeliveryFilter) ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Delivery
+	for _, delivery := range s.deliveries {
+		if filter.EndpointID != "" && delivery.EndpointID != filter.EndpointID {
+			continue
+		}
+		if filter.Status != "" && delivery.Status != filter.Status {
+			continue
+		}
+		out = append(out, delivery)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if limit := filter.LimitOrDefault(); len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *MemoryStore) Attempts(_ context.Context, deliveryID string) ([]Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.attempts[deliveryID]), nil
+}
+
+// LimitOrDefault returns Limit, or DefaultListLimit when it is not set.
+func (f DeliveryFilter) LimitOrDefault() int {
+	if f.Limit <= 0 {
+		return DefaultListLimit
+	}
+	return f.Limit
+}
@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+// ErrForbiddenTarget is returned by Register, and fails the delivery
+// attempt, for endpoints resolving to an internal address while
+// Config.AllowPrivateTargets is off.
+var ErrForbiddenTarget = errors.New("webhook: endpoint resolves to a private address")
+
+// internalPrefixes are global unicast ranges that still reach the local
+// network or carrier infrastructure.
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// checkTarget resolves the endpoint host and rejects it when any address is
+// loopback, private, link-local (which includes cloud metadata services),
+// multicast or unspecified. It only gives Register early feedback; the host
+// may resolve elsewhere later, so dialControl enforces the same rule on the
+// address each delivery actually connects to.
+func (d *Dispatcher) checkTarget(ctx context.Context, u *url.URL) error {
+	if d.config.AllowPrivateTargets {
+		return nil
+	}
+	host := u.Hostname()
+	addrs := []netip.Addr{}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else {
+		resolved, err := d.resolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return fmt.Errorf("%w: resolve %s: %v", ErrInvalidEndpoint, host, err)
+		}
+		addrs = resolved
+	}
+	for _, addr := range addrs {
+		if !publicAddr(addr) {
+			return ErrForbiddenTarget
+		}
+	}
+	return nil
+}
+
+// dialControl rejects connections to internal addresses, or returns nil when
+// Config.AllowPrivateTargets is set.
+func (d *Dispatcher) dialControl() func(network, address string, conn syscall.RawConn) error {
+	if d.config.AllowPrivateTargets {
+		return nil
+	}
+	return func(_, address string, _ syscall.RawConn) error {
+		addr, err := netip.ParseAddrPort(address)
+		if err != nil {
+			return err
+		}
+		if !publicAddr(addr.Addr()) {
+			return ErrForbiddenTarget
+		}
+		return nil
+	}
+}
+
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range internalPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,249 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestDispatcher(t *testing.T, config Config) (*Dispatcher, *MemoryStore, *time.Time) {
+	t.Helper()
+	store := NewMemoryStore()
+	// Receivers are httptest servers on loopback.
+	config.AllowPrivateTargets = true
+	d, err := NewDispatcher(config, store)
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+	now := time.Now()
+	d.now = func() time.Time { return now }
+	return d, store, &now
+}
+
+func TestDispatcherSignsDeliveries(t *testing.T) {
+	received := make(chan error, 1)
+	var secret string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(HeaderEvent) != "order.created" || r.Header.Get(HeaderID) == "" {
+			received <- ErrInvalidSignature
+			return
+		}
+		received <- Verify(secret, r.Header.Get(HeaderSignature), r.Header.Get(HeaderTimestamp), body, time.Minute, time.Now())
+	}))
+	defer receiver.Close()
+
+	d, store, _ := newTestDispatcher(t, Config{})
+	ctx := context.Background()
+	endpoint, err := d.Register(ctx, Endpoint{URL: receiver.URL, Events: []string{"order.*"}})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	secret = endpoint.Secret
+
+	deliveries, err := d.Publish(ctx, "order.created", map[string]string{"id": "o-1"})
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("Publish: %v %d", err, len(deliveries))
+	}
+	if skipped, _ := d.Publish(ctx, "user.created", map[string]string{}); len(skipped) != 0 {
+		t.Fatalf("expected user.created to match no endpoint, got %d deliveries", len(skipped))
+	}
+	if n, err := d.RunOnce(ctx); err != nil || n != 1 {
+		t.Fatalf("RunOnce: %v %d", err, n)
+	}
+	if err := <-received; err != nil {
+		t.Fatalf("receiver: %v", err)
+	}
+
+	delivery, _ := store.Delivery(ctx, deliveries[0].ID)
+	if delivery.Status != DeliverySucceeded || delivery.Attempts != 1 || delivery.LastStatusCode != http.StatusOK {
+		t.Fatalf("delivery: %+v", delivery)
+	}
+	attempts, _ := store.Attempts(ctx, delivery.ID)
+	if len(attempts) != 1 || !attempts[0].Succeeded() {
+		t.Fatalf("attempts: %+v", attempts)
+	}
+}
+
+func TestDispatcherRetriesThenDeadLettersAndDisablesEndpoint(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	d, store, now := newTestDispatcher(t, Config{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     4 * time.Second,
+		DisableAfter:   3,
+	})
+	ctx := context.Background()
+	endpoint, _ := d.Register(ctx, Endpoint{URL: receiver.URL})
+	deliveries, _ := d.Publish(ctx, "order.created", map[string]string{"id": "o-1"})
+
+	for i := 0; i < 3; i++ {
+		if n, err := d.RunOnce(ctx); err != nil || n != 1 {
+			t.Fatalf("RunOnce %d: %v %d", i, err, n)
+		}
+		if n, _ := d.RunOnce(ctx); n != 0 {
+			t.Fatalf("expected the retry to wait for its backoff")
+		}
+		*now = now.Add(time.Minute)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("calls: %d", calls.Load())
+	}
+
+	delivery, _ := store.Delivery(ctx, deliveries[0].ID)
+	if delivery.Status != DeliveryDead || delivery.Attempts != 3 || delivery.LastStatusCode != http.StatusInternalServerError {
+		t.Fatalf("delivery: %+v", delivery)
+	}
+	disabled, _ := store.Endpoint(ctx, endpoint.ID)
+	if !disabled.Disabled || disabled.ConsecutiveFailures != 3 {
+		t.Fatalf("endpoint: %+v", disabled)
+	}
+	if skipped, _ := d.Publish(ctx, "order.created", map[string]string{}); len(skipped) != 0 {
+		t.Fatalf("expected a disabled endpoint to receive nothing")
+	}
+
+	if _, err := d.Enable(ctx, endpoint.ID); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	if _, err := d.Redeliver(ctx, delivery.ID); err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	if n, _ := d.RunOnce(ctx); n != 1 || calls.Load() != 4 {
+		t.Fatalf("expected the redelivery to be sent, calls=%d", calls.Load())
+	}
+}
+
+func TestDispatcherRejectsPrivateTargets(t *testing.T) {
+	d, err := NewDispatcher(Config{}, NewMemoryStore())
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+	ctx := context.Background()
+	for _, target := range []string{
+		"http://169.254.169.254/latest/meta-data",
+		"http://127.0.0.1:8080/hook",
+		"http://[::1]/hook",
+		"https://10.0.0.7/hook",
+		"http://[::ffff:192.168.1.1]/hook",
+		"http://localhost/hook",
+	} {
+		if _, err := d.Register(ctx, Endpoint{URL: target}); !errors.Is(err, ErrForbiddenTarget) {
+			t.Fatalf("Register(%s): got=%v want=%v", target, err, ErrForbiddenTarget)
+		}
+	}
+	if _, err := d.Register(ctx, Endpoint{URL: "https://203.0.113.10/hook"}); err != nil {
+		t.Fatalf("Register public target: %v", err)
+	}
+}
+
+func TestDispatcherRejectsPrivateAddressAtDial(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	store := NewMemoryStore()
+	d, err := NewDispatcher(Config{}, store)
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+	ctx := context.Background()
+	// Saved directly, as if the host resolved to a public address at
+	// Register and was rebound to loopback since.
+	if err := store.SaveEndpoint(ctx, Endpoint{ID: "e-1", URL: receiver.URL, Secret: "s"}); err != nil {
+		t.Fatalf("SaveEndpoint: %v", err)
+	}
+	deliveries, _ := d.Publish(ctx, "order.created", map[string]string{"id": "o-1"})
+	if n, err := d.RunOnce(ctx); err != nil || n != 1 {
+		t.Fatalf("RunOnce: %v %d", err, n)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("expected no request to a private address, calls=%d", calls.Load())
+	}
+	delivery, _ := store.Delivery(ctx, deliveries[0].ID)
+	if delivery.Attempts != 1 || !strings.Contains(delivery.LastError, ErrForbiddenTarget.Error()) {
+		t.Fatalf("delivery: %+v", delivery)
+	}
+}
+
+func TestDispatcherStopFinishesInFlightDeliveries(t *testing.T) {
+	arrived := make(chan struct{}, 2)
+	release := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer receiver.Close()
+	defer close(release)
+
+	publishAndStop := func(t *testing.T, stopCtx context.Context, unblock func()) (func() Delivery, error) {
+		t.Helper()
+		d, store, _ := newTestDispatcher(t, Config{})
+		ctx := context.Background()
+		if _, err := d.Register(ctx, Endpoint{URL: receiver.URL}); err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+		if err := d.Start(ctx); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		deliveries, _ := d.Publish(ctx, "order.created", map[string]string{"id": "o-1"})
+		<-arrived
+		unblock()
+		err := d.Stop(stopCtx)
+		return func() Delivery {
+			delivery, _ := store.Delivery(ctx, deliveries[0].ID)
+			return delivery
+		}, err
+	}
+
+	t.Run("finishes", func(t *testing.T) {
+		load, err := publishAndStop(t, context.Background(), func() {
+			time.AfterFunc(50*time.Millisecond, func() { release <- struct{}{} })
+		})
+		if err != nil {
+			t.Fatalf("Stop: %v", err)
+		}
+		if delivery := load(); delivery.Status != DeliverySucceeded || delivery.Attempts != 1 {
+			t.Fatalf("delivery: %+v", delivery)
+		}
+	})
+
+	t.Run("aborted without an attempt", func(t *testing.T) {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		load, err := publishAndStop(t, stopCtx, func() {})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Stop: got=%v want=%v", err, context.DeadlineExceeded)
+		}
+		// The aborted send returns after Stop; give it time to (not) record.
+		time.Sleep(50 * time.Millisecond)
+		if delivery := load(); delivery.Attempts != 0 || delivery.Status != DeliveryPending {
+			t.Fatalf("delivery: %+v", delivery)
+		}
+	})
+}
+
+func TestDispatcherBackoffIsCapped(t *testing.T) {
+	d, _, _ := newTestDispatcher(t, Config{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second})
+	for attempt, max := range map[int]time.Duration{1: time.Second, 3: 4 * time.Second, 10: 10 * time.Second} {
+		if got := d.backoff(attempt); got < max/2 || got > max {
+			t.Fatalf("backoff(%d) = %s, want within [%s, %s]", attempt, got, max/2, max)
+		}
+	}
+}
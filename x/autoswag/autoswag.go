@@ -10,5 +10,6 @@ type ParameterMetadata = web.ParameterMetadata
 type ResponseHeaderMetadata = web.ResponseHeaderMetadata
 type ResponseMetadata = web.ResponseMetadata
 type PaginationMetadata = web.PaginationMetadata
+type DeprecationMetadata = web.DeprecationMetadata
 type MetadataRegistry = web.MetadataRegistry
 type Context = SwaggerContext
@@ -0,0 +1,491 @@
+package autoswag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/bronystylecrazy/ultrastructure/meta"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+// ContractMode selects what happens when a request or response does not
+// match the generated OpenAPI spec.
+type ContractMode string
+
+const (
+	// ContractOff disables contract checks.
+	ContractOff ContractMode = ""
+	// ContractLog logs violations and serves the request as usual.
+	ContractLog ContractMode = "log"
+	// ContractFail logs violations and fails the request with the violations
+	// as error details.
+	ContractFail ContractMode = "fail"
+)
+
+var (
+	// ErrRequestContract is returned in ContractFail mode for request bodies
+	// that do not match the documented schema.
+	ErrRequestContract = web.NewError(http.StatusBadRequest, "REQUEST_CONTRACT_VIOLATION", "request body does not match the documented schema")
+	// ErrResponseContract is returned in ContractFail mode for responses that
+	// do not match the documented status codes or schemas. Like other 5xx
+	// errors its details are only rendered with web.errors.expose_internal_errors.
+	ErrResponseContract = web.NewError(http.StatusInternalServerError, "RESPONSE_CONTRACT_VIOLATION", "response does not match the documented contract")
+)
+
+// ContractViolation is a single mismatch between a payload and the spec.
+// Path is a JSON path into the payload, e.g. $.items[0].email.
+type ContractViolation struct {
+	Path    string
+	Message string
+}
+
+func (v ContractViolation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return v.Path + ": " + v.Message
+}
+
+// ContractChecker validates requests and responses against an OpenAPI spec
+// built by BuildOpenAPISpec.
+type ContractChecker struct {
+	operations []contractOperation
+	schemas    map[string]any
+}
+
+type contractOperation struct {
+	method    string
+	pattern   string
+	static    bool
+	request   map[string]any // media type -> schema
+	required  bool
+	responses map[string]map[string]any // status -> media type -> schema
+}
+
+// NewContractChecker indexes the operations and component schemas of spec.
+func NewContractChecker(spec *OpenAPISpec) (*ContractChecker, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("openapi spec is nil")
+	}
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	checker := &ContractChecker{schemas: doc.Components.Schemas}
+	for path, operations := range doc.Paths {
+		pattern := fiberPathFromOpenAPI(path)
+		for method, operation := range operations {
+			op := contractOperation{
+				method:    strings.ToUpper(method),
+				pattern:   pattern,
+				static:    !strings.ContainsAny(pattern, ":*+"),
+				responses: map[string]map[string]any{},
+			}
+			if body, ok := operation["requestBody"].(map[string]any); ok {
+				op.request = contentSchemas(body)
+				op.required, _ = body["required"].(bool)
+			}
+			if responses, ok := operation["responses"].(map[string]any); ok {
+				for status, response := range responses {
+					response, _ := response.(map[string]any)
+					op.responses[status] = contentSchemas(response)
+				}
+			}
+			checker.operations = append(checker.operations, op)
+		}
+	}
+	// Static routes win over parameterized ones, like fiber's own matching
+	// of /users/me before /users/:id when declared first.
+	sort.SliceStable(checker.operations, func(i, j int) bool {
+		return checker.operations[i].static && !checker.operations[j].static
+	})
+	return checker, nil
+}
+
+func contentSchemas(object map[string]any) map[string]any {
+	out := map[string]any{}
+	content, _ := object["content"].(map[string]any)
+	for mediaType, entry := range content {
+		entry, _ := entry.(map[string]any)
+		out[mediaType] = entry["schema"]
+	}
+	return out
+}
+
+func fiberPathFromOpenAPI(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = ":" + segment[1:len(segment)-1]
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Match returns the route pattern of the operation serving method and path.
+func (c *ContractChecker) Match(cfg fiber.Config, method, path string) (string, bool) {
+	op := c.match(cfg, method, path)
+	if op == nil {
+		return "", false
+	}
+	return op.pattern, true
+}
+
+func (c *ContractChecker) match(cfg fiber.Config, method, path string) *contractOperation {
+	method = strings.ToUpper(method)
+	for i := range c.operations {
+		op := &c.operations[i]
+		if op.method != method && !(method == fiber.MethodHead && op.method == fiber.MethodGet) {
+			continue
+		}
+		if fiber.RoutePatternMatch(path, op.pattern, cfg) {
+			return op
+		}
+	}
+	return nil
+}
+
+// CheckRequest validates a JSON request body of the operation serving
+// method and path. Other media types are not checked.
+func (c *ContractChecker) CheckRequest(cfg fiber.Config, method, path, contentType string, body []byte) []ContractViolation {
+	op := c.match(cfg, method, path)
+	if op == nil || len(op.request) == 0 {
+		return nil
+	}
+	if len(body) == 0 {
+		if op.required {
+			return []ContractViolation{{Message: "request body is required"}}
+		}
+		return nil
+	}
+	schema, ok := jsonSchemaFor(op.request, contentType)
+	if !ok {
+		return nil
+	}
+	return c.validateJSON(schema, body)
+}
+
+// CheckResponse validates the status code and JSON body of a response of
+// the operation serving method and path.
+func (c *ContractChecker) CheckResponse(cfg fiber.Config, method, path string, status int, contentType string, body []byte) []ContractViolation {
+	op := c.match(cfg, method, path)
+	if op == nil {
+		return nil
+	}
+	content, ok := op.responses[strconv.Itoa(status)]
+	if !ok {
+		content, ok = op.responses[strconv.Itoa(status/100)+"XX"]
+	}
+	if !ok {
+		content, ok = op.responses["default"]
+	}
+	if !ok {
+		documented := make([]string, 0, len(op.responses))
+		for code := range op.responses {
+			documented = append(documented, code)
+		}
+		sort.Strings(documented)
+		return []ContractViolation{{Message: fmt.Sprintf("status %d is not documented (documented: %s)", status, strings.Join(documented, ", "))}}
+	}
+	if len(body) == 0 || len(content) == 0 {
+		return nil
+	}
+	schema, ok := jsonSchemaFor(content, contentType)
+	if !ok {
+		return nil
+	}
+	return c.validateJSON(schema, body)
+}
+
+func jsonSchemaFor(content map[string]any, contentType string) (any, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !isJSONMediaType(mediaType) {
+		return nil, false
+	}
+	if schema, ok := content[mediaType]; ok {
+		return schema, schema != nil
+	}
+	for candidate, schema := range content {
+		if isJSONMediaType(candidate) {
+			return schema, schema != nil
+		}
+	}
+	return nil, false
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == web.ContentTypeApplicationJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+func (c *ContractChecker) validateJSON(schema any, body []byte) []ContractViolation {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []ContractViolation{{Path: "$", Message: "invalid JSON: " + err.Error()}}
+	}
+	var out []ContractViolation
+	c.validate(schema, value, "$", &out, 0)
+	return out
+}
+
+const maxContractDepth = 32
+
+func (c *ContractChecker) validate(schema any, value any, path string, out *[]ContractViolation, depth int) {
+	s, ok := schema.(map[string]any)
+	if !ok || depth > maxContractDepth {
+		return
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		c.validate(c.schemas[strings.TrimPrefix(ref, "#/components/schemas/")], value, path, out, depth+1)
+		return
+	}
+	if value == nil {
+		if nullable, _ := s["nullable"].(bool); !nullable && s["type"] != nil {
+			*out = append(*out, ContractViolation{Path: path, Message: "must not be null"})
+		}
+		return
+	}
+	if variants, ok := s["oneOf"].([]any); ok {
+		c.validateAny(variants, value, path, out, depth)
+		return
+	}
+	if variants, ok := s["anyOf"].([]any); ok {
+		c.validateAny(variants, value, path, out, depth)
+		return
+	}
+	if parts, ok := s["allOf"].([]any); ok {
+		for _, part := range parts {
+			c.validate(part, value, path, out, depth+1)
+		}
+	}
+
+	if typ, ok := s["type"].(string); ok && !matchesJSONType(typ, value) {
+		*out = append(*out, ContractViolation{Path: path, Message: fmt.Sprintf("expected %s, got %s", typ, jsonTypeName(value))})
+		return
+	}
+	if enum, ok := s["enum"].([]any); ok && !containsJSONValue(enum, value) {
+		*out = append(*out, ContractViolation{Path: path, Message: fmt.Sprintf("value %v is not one of %v", value, enum)})
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		required, _ := s["required"].([]any)
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, present := v[name]; !present {
+					*out = append(*out, ContractViolation{Path: path + "." + name, Message: "missing required field"})
+				}
+			}
+		}
+		properties, _ := s["properties"].(map[string]any)
+		for name, field := range v {
+			if property, ok := properties[name]; ok {
+				c.validate(property, field, path+"."+name, out, depth+1)
+			} else if additional, ok := s["additionalProperties"].(map[string]any); ok {
+				c.validate(additional, field, path+"."+name, out, depth+1)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			c.validate(s["items"], item, path+"["+strconv.Itoa(i)+"]", out, depth+1)
+		}
+	}
+}
+
+func (c *ContractChecker) validateAny(variants []any, value any, path string, out *[]ContractViolation, depth int) {
+	var first []ContractViolation
+	for i, variant := range variants {
+		var candidate []ContractViolation
+		c.validate(variant, value, path, &candidate, depth+1)
+		if len(candidate) == 0 {
+			return
+		}
+		if i == 0 {
+			first = candidate
+		}
+	}
+	*out = append(*out, first...)
+}
+
+func matchesJSONType(typ string, value any) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	default:
+		return true
+	}
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	default:
+		return "null"
+	}
+}
+
+func containsJSONValue(values []any, value any) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContractMiddleware checks requests and responses against the spec served
+// by autoswag, so handlers that drift from their RouteMetadata are caught
+// by integration tests. It only runs in development builds
+// (meta.IsDevelopment) and when enabled with WithContractChecks.
+type ContractMiddleware struct {
+	mode    ContractMode
+	logger  *zap.Logger
+	checker atomic.Pointer[ContractChecker]
+}
+
+func NewContractMiddleware(mode ContractMode, logger *zap.Logger) *ContractMiddleware {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ContractMiddleware{mode: mode, logger: logger}
+}
+
+// Enabled reports whether the middleware checks requests.
+func (m *ContractMiddleware) Enabled() bool {
+	return m != nil && m.mode != ContractOff && meta.IsDevelopment()
+}
+
+func (m *ContractMiddleware) Handle(r web.Router) {
+	if !m.Enabled() {
+		return
+	}
+	r.Use(m.Middleware)
+}
+
+// Load replaces the spec requests are checked against.
+func (m *ContractMiddleware) Load(spec *OpenAPISpec) error {
+	if !m.Enabled() {
+		return nil
+	}
+	checker, err := NewContractChecker(spec)
+	if err != nil {
+		return err
+	}
+	m.checker.Store(checker)
+	return nil
+}
+
+func (m *ContractMiddleware) Middleware(c fiber.Ctx) error {
+	checker := m.checker.Load()
+	if checker == nil {
+		return c.Next()
+	}
+	cfg := c.App().Config()
+	method, path := c.Method(), c.Path()
+	route, ok := checker.Match(cfg, method, path)
+	if !ok {
+		return c.Next()
+	}
+
+	violations := checker.CheckRequest(cfg, method, path, c.Get(fiber.HeaderContentType), c.Body())
+	if len(violations) > 0 {
+		m.report("request", method, route, violations)
+		if m.mode == ContractFail {
+			return ErrRequestContract.WithDetails(contractDetails(violations)...)
+		}
+	}
+
+	err := c.Next()
+
+	// The path may have been rewritten while routing, e.g. by web versioning.
+	method, path = c.Method(), c.Path()
+	violations = nil
+	if err != nil {
+		// Errors mapped by web.ErrorMapper only get their status later.
+		if status, ok := errorStatus(err); ok {
+			violations = checker.CheckResponse(cfg, method, path, status, "", nil)
+		}
+	} else if !c.Response().IsBodyStream() {
+		violations = checker.CheckResponse(cfg, method, path, c.Response().StatusCode(), string(c.Response().Header.ContentType()), c.Response().Body())
+	}
+	if len(violations) > 0 {
+		m.report("response", method, route, violations)
+		if m.mode == ContractFail {
+			return ErrResponseContract.WithDetails(contractDetails(violations)...)
+		}
+	}
+	return err
+}
+
+func (m *ContractMiddleware) report(direction, method, route string, violations []ContractViolation) {
+	m.logger.Warn("autoswag: "+direction+" contract violation",
+		zap.String("method", method),
+		zap.String("route", route),
+		zap.Strings("violations", contractDetails(violations)),
+	)
+}
+
+func contractDetails(violations []ContractViolation) []string {
+	out := make([]string, len(violations))
+	for i, violation := range violations {
+		out[i] = violation.String()
+	}
+	return out
+}
+
+func errorStatus(err error) (int, bool) {
+	var httpErr *web.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Status, true
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code, true
+	}
+	return 0, false
+}
@@ -0,0 +1,107 @@
+package autoswag
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+type contractCreateUser struct {
+	Email string `json:"email" validate:"required"`
+}
+
+type contractUser struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+func newContractTestApp(t *testing.T, mode ContractMode) *fiber.App {
+	t.Helper()
+
+	handler := web.NewErrorHandler(web.Config{Errors: web.ErrorConfig{ExposeInternalErrors: true}}, nil)
+	app := fiber.New(fiber.Config{ErrorHandler: handler.HandleError})
+	registry := web.NewMetadataRegistry()
+	r := web.NewRouterWithRegistry(app, registry)
+
+	contract := NewContractMiddleware(mode, zap.NewNop())
+	contract.Handle(r)
+
+	r.Post("/users", func(c fiber.Ctx) error {
+		switch c.Query("drift") {
+		case "field":
+			return c.Status(http.StatusCreated).JSON(fiber.Map{"id": 7})
+		case "status":
+			return c.Status(http.StatusAccepted).JSON(contractUser{ID: "u1", Email: "a@b.c"})
+		}
+		return c.Status(http.StatusCreated).JSON(contractUser{ID: "u1", Email: "a@b.c"})
+	}).Body(contractCreateUser{}).Produces(contractUser{}, http.StatusCreated)
+
+	spec := BuildOpenAPISpecWithRegistryAndOptions(InspectFiberRoutes(app, zap.NewNop()), web.Config{Name: "Test API"}, registry, OpenAPIBuildOptions{})
+	if err := contract.Load(spec); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return app
+}
+
+func doContractRequest(t *testing.T, app *fiber.App, target, body string) (int, web.Error) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	raw, _ := io.ReadAll(res.Body)
+	var out web.Error
+	if res.StatusCode >= http.StatusBadRequest {
+		if err := json.Unmarshal(raw, &out); err != nil {
+			t.Fatalf("decode error body %s: %v", raw, err)
+		}
+	}
+	return res.StatusCode, out
+}
+
+func TestContractMiddlewareFailsOnDrift(t *testing.T) {
+	app := newContractTestApp(t, ContractFail)
+
+	if status, _ := doContractRequest(t, app, "/users", `{"email":"a@b.c"}`); status != http.StatusCreated {
+		t.Fatalf("valid request: status=%d", status)
+	}
+
+	status, body := doContractRequest(t, app, "/users", `{"email":42}`)
+	if status != http.StatusBadRequest || body.Error.Code != "REQUEST_CONTRACT_VIOLATION" {
+		t.Fatalf("request drift: status=%d body=%+v", status, body)
+	}
+	if len(body.Error.Details) != 1 || body.Error.Details[0] != "$.email: expected string, got number" {
+		t.Fatalf("request drift details: %v", body.Error.Details)
+	}
+
+	status, body = doContractRequest(t, app, "/users?drift=field", `{"email":"a@b.c"}`)
+	if status != http.StatusInternalServerError || body.Error.Code != "RESPONSE_CONTRACT_VIOLATION" {
+		t.Fatalf("response drift: status=%d body=%+v", status, body)
+	}
+	details := strings.Join(body.Error.Details, "\n")
+	if !strings.Contains(details, "$.id: expected string, got number") || !strings.Contains(details, "$.email: missing required field") {
+		t.Fatalf("response drift details: %v", body.Error.Details)
+	}
+
+	status, body = doContractRequest(t, app, "/users?drift=status", `{"email":"a@b.c"}`)
+	if status != http.StatusInternalServerError || !strings.Contains(strings.Join(body.Error.Details, ""), "status 202 is not documented") {
+		t.Fatalf("undeclared status: status=%d body=%+v", status, body)
+	}
+}
+
+func TestContractMiddlewareLogModeServesRequest(t *testing.T) {
+	app := newContractTestApp(t, ContractLog)
+
+	if status, _ := doContractRequest(t, app, "/users?drift=status", `{"email":"a@b.c"}`); status != http.StatusAccepted {
+		t.Fatalf("log mode must not change the response, got %d", status)
+	}
+}
@@ -25,6 +25,7 @@ type Middleware struct {
 	emitFiles           []string
 	versionedDocs       []VersionedDocsOption
 	registry            *web.MetadataRegistry
+	versions            *web.VersionRegistry
 	modelRegistry       *SwaggerModelRegistry
 	extraModels         []reflect.Type
 	hook                HookFunc
@@ -43,6 +44,7 @@ type Middleware struct {
 	customizers         []Customizer
 	preCustomizers      []PreRun
 	postCustomizers     []PostRun
+	contract            *ContractMiddleware
 	logger              *zap.Logger
 }
 
@@ -53,6 +55,9 @@ type mountedSpec struct {
 
 func Use(opts ...Option) di.Node {
 	return di.Options(
+		di.Provide(func(logger *zap.Logger) *ContractMiddleware {
+			return NewContractMiddleware(ResolveOptions("/docs", opts...).ContractMode, logger)
+		}, web.Priority(web.Earlier)),
 		di.AutoGroup[Customizer](CustomizersGroupName),
 		di.AutoGroup[PreRun](PreCustomizersGroupName),
 		di.AutoGroup[PostRun](PostCustomizersGroupName),
@@ -66,12 +71,17 @@ func Use(opts ...Option) di.Node {
 			customizers []Customizer,
 			preCustomizers []PreRun,
 			postCustomizers []PostRun,
+			contract *ContractMiddleware,
 		) (*Middleware, error) {
 			cfg := ResolveOptions("/docs", opts...)
 
-			var metadataRegistry *web.MetadataRegistry
+			var (
+				metadataRegistry *web.MetadataRegistry
+				versionRegistry  *web.VersionRegistry
+			)
 			if registries != nil {
 				metadataRegistry = registries.Metadata
+				versionRegistry = registries.Versions
 			}
 			var app *fiber.App
 			if server != nil {
@@ -85,6 +95,7 @@ func Use(opts ...Option) di.Node {
 				emitFiles:           append([]string(nil), cfg.EmitFiles...),
 				versionedDocs:       append([]VersionedDocsOption(nil), cfg.VersionedDocs...),
 				registry:            metadataRegistry,
+				versions:            versionRegistry,
 				modelRegistry:       modelRegistry,
 				extraModels:         append([]reflect.Type(nil), cfg.ExtraModels...),
 				hook:                cfg.Hook,
@@ -101,6 +112,7 @@ func Use(opts ...Option) di.Node {
 				customizers:         append([]Customizer(nil), customizers...),
 				preCustomizers:      append([]PreRun(nil), preCustomizers...),
 				postCustomizers:     append([]PostRun(nil), postCustomizers...),
+				contract:            contract,
 				logger:              logger,
 			}, nil
 		}, web.Priority(web.Latest), di.Params(
@@ -112,6 +124,7 @@ func Use(opts ...Option) di.Node {
 			di.Group(CustomizersGroupName),
 			di.Group(PreCustomizersGroupName),
 			di.Group(PostCustomizersGroupName),
+			nil,
 		)),
 	)
 }
@@ -288,7 +301,7 @@ func emitOpenAPIVersion(spec *OpenAPISpec) string {
 
 // Handle registers the auto-swagger routes.
 func (m *Middleware) Handle(r web.Router) {
-	routes := InspectFiberRoutes(m.app, m.logger)
+	routes := withInheritedRoutes(InspectFiberRoutes(m.app, m.logger), m.versions, m.registry)
 	extraModels := combineExtraModelTypes(m.modelRegistry, m.extraModels)
 	hooks := composeSwaggerCustomizeHooks(m.hook, m.customizers, m.preCustomizers, m.postCustomizers)
 	buildOpts := OpenAPIBuildOptions{
@@ -344,6 +357,9 @@ func (m *Middleware) Handle(r web.Router) {
 		zap.String("spec_path", m.path+"/swagger.json"),
 	)
 	m.emitSpecFiles()
+	if err := m.contract.Load(m.spec); err != nil {
+		m.logger.Warn("auto-swagger: contract checks disabled", zap.Error(err))
+	}
 
 	m.registerSpecEndpoints(r, m.path, m.spec)
 	for _, mounted := range m.versionedSpecs {
@@ -429,6 +445,31 @@ func (m *Middleware) generateSwaggerUI(specURL string) string {
 </html>`
 }
 
+// withInheritedRoutes adds the routes a version inherits under the version's
+// own prefix, sharing the metadata of the route serving them.
+func withInheritedRoutes(routes []RouteInfo, versions *web.VersionRegistry, registry *web.MetadataRegistry) []RouteInfo {
+	if versions == nil {
+		return routes
+	}
+	out := routes
+	for _, inherited := range versions.InheritedRoutes() {
+		for _, route := range routes {
+			if route.Path != inherited.Source || (inherited.Method != "" && route.Method != inherited.Method) {
+				continue
+			}
+			alias := route
+			alias.Path = inherited.Path
+			out = append(out, alias)
+			if registry != nil && registry.GetRoute(route.Method, alias.Path) == nil {
+				if meta := registry.GetRoute(route.Method, route.Path); meta != nil {
+					registry.RegisterRoute(route.Method, alias.Path, meta)
+				}
+			}
+		}
+	}
+	return out
+}
+
 func filterRoutesByPrefix(routes []RouteInfo, prefix string) []RouteInfo {
 	prefix = normalizeRoutePrefix(prefix)
 	if prefix == "" {
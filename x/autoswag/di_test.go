@@ -1,6 +1,12 @@
 package autoswag
 
-import "testing"
+import (
+	"net/http"
+	"testing"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/gofiber/fiber/v3"
+)
 
 func TestFilterRoutesByPrefix(t *testing.T) {
 	routes := []RouteInfo{
@@ -37,3 +43,29 @@ func TestWithVersionedDocs_NormalizesValues(t *testing.T) {
 		t.Fatalf("expected name API v1, got %q", doc.Name)
 	}
 }
+
+func TestWithInheritedRoutesListsRoutesUnderInheritingVersion(t *testing.T) {
+	app := fiber.New()
+	registries := web.NewRegistryContainer()
+	r := web.NewRouterWithRegistries(app, registries)
+	api := r.Group("/api")
+	v1 := api.Version("v1")
+	v2 := api.Version("v2", web.VersionInherit("v1"))
+	v1.Get("/users", func(c fiber.Ctx) error { return nil }).Name("ListUsers")
+	v1.Get("/users/:id", func(c fiber.Ctx) error { return nil })
+	v2.Get("/users/:id", func(c fiber.Ctx) error { return nil })
+
+	routes := withInheritedRoutes([]RouteInfo{
+		{Method: http.MethodGet, Path: "/api/v1/users"},
+		{Method: http.MethodGet, Path: "/api/v1/users/:id"},
+		{Method: http.MethodGet, Path: "/api/v2/users/:id"},
+	}, registries.Versions, registries.Metadata)
+
+	filtered := filterRoutesByPrefix(routes, "/api/v2")
+	if len(filtered) != 2 {
+		t.Fatalf("expected 2 routes for v2, got %+v", filtered)
+	}
+	if meta := registries.Metadata.GetRoute(http.MethodGet, "/api/v2/users"); meta == nil || meta.OperationID != "ListUsers" {
+		t.Fatalf("inherited route must share metadata, got %+v", meta)
+	}
+}
@@ -315,6 +315,17 @@ func buildOpenAPISpecWithRegistryAndOptions(routes []RouteInfo, config Config, r
 					operation["x-policies"] = policies
 				}
 			}
+			if metadata.Deprecation != nil {
+				if _, exists := operation["deprecated"]; !exists {
+					operation["deprecated"] = true
+				}
+				if _, exists := operation["x-sunset"]; !exists && !metadata.Deprecation.Sunset.IsZero() {
+					operation["x-sunset"] = metadata.Deprecation.Sunset.UTC().Format(time.RFC3339)
+				}
+				if _, exists := operation["externalDocs"]; !exists && metadata.Deprecation.Link != "" {
+					operation["externalDocs"] = map[string]string{"description": "Deprecation notice", "url": metadata.Deprecation.Link}
+				}
+			}
 		}
 		if existingOperationID, ok := operation["operationId"].(string); ok && strings.TrimSpace(existingOperationID) != "" {
 			baseOperationID = existingOperationID
@@ -502,6 +513,10 @@ func cloneRouteMetadata(meta *RouteMetadata) *RouteMetadata {
 		p := *meta.Pagination
 		cloned.Pagination = &p
 	}
+	if meta.Deprecation != nil {
+		d := *meta.Deprecation
+		cloned.Deprecation = &d
+	}
 
 	return &cloned
 }
@@ -724,7 +739,11 @@ func extractParameters(path string, metadata *RouteMetadata) []map[string]interf
 		}
 		params = append(params, extractMetadataParams(metadata.Parameters)...)
 		if metadata.Pagination != nil {
-			params = mergeParameters(params, extractPaginationParams())
+			if metadata.Pagination.Cursor {
+				params = mergeParameters(params, extractCursorPaginationParams())
+			} else {
+				params = mergeParameters(params, extractPaginationParams())
+			}
 		}
 	}
 
@@ -949,7 +968,23 @@ func extractMetadataParams(metadataParams []ParameterMetadata) []map[string]inte
 		if isSkippedType(p.Type) {
 			continue
 		}
+		if p.Type != nil && p.Type.Kind() == reflect.Slice && p.Type.Elem().Kind() != reflect.Uint8 {
+			// Comma separated values, e.g. sort=-created_at,total.
+			param["schema"] = map[string]interface{}{
+				"type":  "array",
+				"items": mapOpenAPIType(p.Type.Elem()),
+			}
+			param["style"] = "form"
+			param["explode"] = false
+		}
 		schema := param["schema"].(map[string]interface{})
+		if len(p.Enum) > 0 {
+			if items, ok := schema["items"].(map[string]interface{}); ok && schema["type"] == "array" {
+				items["enum"] = p.Enum
+			} else {
+				schema["enum"] = p.Enum
+			}
+		}
 		applyExtensionsTag(schema, p.Extensions)
 
 		if p.Description != "" {
@@ -1327,6 +1362,33 @@ func extractPaginationParams() []map[string]interface{} {
 	}
 }
 
+func extractCursorPaginationParams() []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"name":        "cursor",
+			"in":          "query",
+			"required":    false,
+			"description": "Opaque cursor from meta.next_cursor or meta.prev_cursor",
+			"schema": map[string]interface{}{
+				"type":    "string",
+				"example": "eyJ2IjoxLCJkIjoibmV4dCIsImsiOlsxMjNdfQ.c2ln",
+			},
+		},
+		{
+			"name":        "limit",
+			"in":          "query",
+			"required":    false,
+			"description": "Page size",
+			"schema": map[string]interface{}{
+				"type":    "integer",
+				"minimum": 1,
+				"default": 20,
+				"example": 20,
+			},
+		},
+	}
+}
+
 func mergeParameters(existing, additional []map[string]interface{}) []map[string]interface{} {
 	if len(additional) == 0 {
 		return existing
@@ -1379,6 +1441,10 @@ func applyPaginationResponse(operation map[string]interface{}, pagination *Pagin
 	if pagination != nil && pagination.ItemType != nil {
 		itemSchema = extractor.ExtractSchemaRef(pagination.ItemType)
 	}
+	if pagination != nil && pagination.Cursor {
+		responses["200"] = cursorPaginationResponse(itemSchema)
+		return
+	}
 
 	responses["200"] = map[string]interface{}{
 		"description": "OK",
@@ -1408,6 +1474,43 @@ func applyPaginationResponse(operation map[string]interface{}, pagination *Pagin
 	}
 }
 
+func cursorPaginationResponse(itemSchema map[string]interface{}) map[string]interface{} {
+	cursor := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":        "string",
+			"description": description,
+		}
+	}
+	return map[string]interface{}{
+		"description": "OK",
+		"content": map[string]interface{}{
+			web.ContentTypeApplicationJSON: map[string]interface{}{
+				"schema": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"data": map[string]interface{}{
+							"type":  "array",
+							"items": itemSchema,
+						},
+						"meta": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"limit":       map[string]interface{}{"type": "integer", "example": 20},
+								"next_cursor": cursor("Cursor of the next page, absent on the last page"),
+								"prev_cursor": cursor("Cursor of the previous page, absent on the first page"),
+								"has_next":    map[string]interface{}{"type": "boolean"},
+								"has_prev":    map[string]interface{}{"type": "boolean"},
+							},
+							"required": []string{"has_next", "has_prev"},
+						},
+					},
+					"required": []string{"data", "meta"},
+				},
+			},
+		},
+	}
+}
+
 func hasErrorDiagnostics(in []Diagnostic) bool {
 	_, ok := lo.Find(in, func(d Diagnostic) bool {
 		return normalizeDiagnosticSeverity(d.Severity) == "error"
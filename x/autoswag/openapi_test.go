@@ -934,6 +934,71 @@ func TestBuildOpenAPISpec_PaginatedAddsParamsAndEnvelope(t *testing.T) {
 	}
 }
 
+func TestBuildOpenAPISpec_CursorPaginatedAddsCursorParamsAndEnvelope(t *testing.T) {
+	GetGlobalRegistry().Clear()
+	GetGlobalRegistry().RegisterRoute("GET", "/users", &RouteMetadata{
+		Pagination: &PaginationMetadata{
+			ItemType: reflect.TypeOf(paginatedUser{}),
+			Cursor:   true,
+		},
+	})
+
+	spec := BuildOpenAPISpec([]RouteInfo{
+		{Method: "GET", Path: "/users"},
+	}, Config{Name: "Test API"})
+
+	getOp := spec.Paths["/users"]["get"].(map[string]interface{})
+
+	params := getOp["parameters"].([]map[string]interface{})
+	byName := map[string]bool{}
+	for _, p := range params {
+		byName[p["in"].(string)+":"+p["name"].(string)] = true
+	}
+	if !byName["query:cursor"] || !byName["query:limit"] || byName["query:page"] {
+		t.Fatalf("expected only cursor and limit pagination params, got %v", byName)
+	}
+
+	responses := getOp["responses"].(map[string]interface{})
+	schema := responses["200"].(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+	props := schema["properties"].(map[string]interface{})
+	if props["data"].(map[string]interface{})["type"] != "array" {
+		t.Fatalf("expected data array, got %v", props["data"])
+	}
+	meta := props["meta"].(map[string]interface{})["properties"].(map[string]interface{})
+	for _, key := range []string{"next_cursor", "prev_cursor", "has_next", "has_prev"} {
+		if _, ok := meta[key]; !ok {
+			t.Fatalf("expected meta.%s", key)
+		}
+	}
+}
+
+func TestBuildOpenAPISpec_MetadataParamEnums(t *testing.T) {
+	GetGlobalRegistry().Clear()
+	GetGlobalRegistry().RegisterRoute("GET", "/orders", &RouteMetadata{
+		Parameters: []ParameterMetadata{
+			{Name: "filter[status][eq]", In: "query", Type: reflect.TypeOf(""), Enum: []any{"pending", "paid"}},
+			{Name: "sort", In: "query", Type: reflect.TypeOf([]string{}), Enum: []any{"total", "-total"}},
+		},
+	})
+
+	spec := BuildOpenAPISpec([]RouteInfo{
+		{Method: "GET", Path: "/orders"},
+	}, Config{Name: "Test API"})
+
+	getOp := spec.Paths["/orders"]["get"].(map[string]interface{})
+	byName := map[string]map[string]interface{}{}
+	for _, p := range getOp["parameters"].([]map[string]interface{}) {
+		byName[p["name"].(string)] = p["schema"].(map[string]interface{})
+	}
+	if enum := byName["filter[status][eq]"]["enum"]; !reflect.DeepEqual(enum, []any{"pending", "paid"}) {
+		t.Fatalf("expected status enum, got %v", enum)
+	}
+	items, _ := byName["sort"]["items"].(map[string]interface{})
+	if enum := items["enum"]; !reflect.DeepEqual(enum, []any{"total", "-total"}) {
+		t.Fatalf("expected sort item enum, got %v", byName["sort"])
+	}
+}
+
 func TestBuildOpenAPISpec_PaginatedDoesNotOverrideExplicit200(t *testing.T) {
 	GetGlobalRegistry().Clear()
 	GetGlobalRegistry().RegisterRoute("GET", "/users", &RouteMetadata{
@@ -2070,3 +2135,29 @@ func TestSwaggerContext_PrimaryModelTypeFallsBackToLowestResponseCode(t *testing
 		t.Fatalf("expected lowest-status response model paginatedUser, got %s", primary.Name())
 	}
 }
+
+func TestBuildOpenAPISpec_DeprecatedOperation(t *testing.T) {
+	GetGlobalRegistry().Clear()
+	GetGlobalRegistry().RegisterRoute("GET", "/v1/users", &RouteMetadata{
+		Deprecation: &DeprecationMetadata{
+			Sunset: time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Link:   "https://docs.example.com/v2",
+		},
+	})
+
+	spec := BuildOpenAPISpec([]RouteInfo{
+		{Method: "GET", Path: "/v1/users"},
+	}, Config{Name: "Test API"})
+
+	getOp := spec.Paths["/v1/users"]["get"].(map[string]interface{})
+	if getOp["deprecated"] != true {
+		t.Fatalf("expected deprecated operation, got %v", getOp["deprecated"])
+	}
+	if getOp["x-sunset"] != "2027-01-01T00:00:00Z" {
+		t.Fatalf("unexpected x-sunset: %v", getOp["x-sunset"])
+	}
+	docs, ok := getOp["externalDocs"].(map[string]string)
+	if !ok || docs["url"] != "https://docs.example.com/v2" {
+		t.Fatalf("unexpected externalDocs: %v", getOp["externalDocs"])
+	}
+}
@@ -26,6 +26,7 @@ type options struct {
 	license             *OpenAPILicense
 	hook                HookFunc
 	extraModels         []reflect.Type
+	contractMode        ContractMode
 }
 
 type ResolvedOptions struct {
@@ -45,6 +46,7 @@ type ResolvedOptions struct {
 	License             *OpenAPILicense
 	Hook                HookFunc
 	ExtraModels         []reflect.Type
+	ContractMode        ContractMode
 }
 
 type VersionedDocsOption struct {
@@ -77,6 +79,7 @@ func ResolveOptions(defaultPath string, opts ...Option) ResolvedOptions {
 		License:             cfg.license,
 		Hook:                cfg.hook,
 		ExtraModels:         append([]reflect.Type(nil), cfg.extraModels...),
+		ContractMode:        cfg.contractMode,
 	}
 }
 
@@ -265,6 +268,12 @@ func WithHook(hook ...HookFunc) Option {
 	return WithCustomize(hook[0])
 }
 
+// WithContractChecks checks requests and responses against the generated
+// spec in development builds; see ContractMiddleware.
+func WithContractChecks(mode ContractMode) Option {
+	return func(o *options) { o.contractMode = mode }
+}
+
 func WithExtraModels(models ...any) Option {
 	return func(o *options) {
 		if len(models) == 0 {
@@ -0,0 +1,327 @@
+package xgorm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/audit"
+	"github.com/bronystylecrazy/ultrastructure/otel"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrAuditChainBroken is returned by AuditStore.Verify when a row was
+	// changed, removed or inserted out of band.
+	ErrAuditChainBroken = errors.New("xgorm: audit hash chain broken")
+	// ErrAuditChainContended is logged for an event that lost the race for
+	// the head of the chain too many times in a row.
+	ErrAuditChainContended = errors.New("xgorm: audit hash chain contended")
+	// ErrAuditStoreStopped is returned by AuditStore.Write after Stop.
+	ErrAuditStoreStopped = errors.New("xgorm: audit store stopped")
+)
+
+// AuditEvent is the row kept for one audit.Event. Data holds the event as
+// JSON; the other columns are copies for filtering. Hash covers PrevHash, the
+// filter columns and Data, chaining every row to the one before it, and the
+// unique PrevHash keeps concurrent writers from forking the chain.
+type AuditEvent struct {
+	Seq          int64     `gorm:"primaryKey;autoIncrement"`
+	ID           string    `gorm:"size:36;not null;uniqueIndex"`
+	Time         time.Time `gorm:"not null;index"`
+	Action       string    `gorm:"size:255;not null;index"`
+	Outcome      string    `gorm:"size:16;not null"`
+	ActorType    string    `gorm:"size:32"`
+	ActorSubject string    `gorm:"size:255;index"`
+	ActorAppID   string    `gorm:"size:255;index"`
+	ResourceType string    `gorm:"size:255;index:idx_audit_events_resource,priority:1"`
+	ResourceID   string    `gorm:"size:255;index:idx_audit_events_resource,priority:2"`
+	RequestID    string    `gorm:"size:128"`
+	Status       int       `gorm:"not null;default:0"`
+	Data         []byte    `gorm:"not null"`
+	PrevHash     string    `gorm:"size:64;not null;uniqueIndex"`
+	Hash         string    `gorm:"size:64;not null;uniqueIndex"`
+}
+
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// DefaultAuditQueueSize is how many events Write buffers before it blocks.
+const DefaultAuditQueueSize = 1024
+
+// AuditTimePrecision is the precision Time is stored and hashed at; it is
+// the coarsest of the supported dialects, MySQL's datetime(3).
+const AuditTimePrecision = time.Millisecond
+
+// maxAuditAppendAttempts bounds how often one event is retried after other
+// instances moved the head of the chain.
+const maxAuditAppendAttempts = 10
+
+// AuditStore is an audit.Sink and audit.Querier keeping events in an
+// append-only, hash-chained table. One writer goroutine appends events in
+// order; Write hands it the event and waits until the row is stored, unless
+// WithAsyncAuditWrites is set. Stop, called by the audit recorder, flushes
+// the queue. Create the table with Migrate or an equivalent migration, and
+// check it with Verify.
+//
+// Usage: audit.Providers(audit.UseSink(xgorm.NewAuditStore(db)))
+type AuditStore struct {
+	otel.Telemetry
+
+	db    *gorm.DB
+	async bool
+	queue chan auditWrite
+	done  chan struct{}
+
+	// closeMu keeps Write from sending on the queue once Stop closed it.
+	closeMu sync.RWMutex
+	closed  bool
+
+	// head is the last hash this writer appended or read; only the writer
+	// goroutine uses it.
+	head       string
+	headLoaded bool
+}
+
+type auditWrite struct {
+	event audit.Event
+	// stored receives the result of a synchronous write.
+	stored chan error
+}
+
+type AuditStoreOption func(*AuditStore)
+
+// WithAsyncAuditWrites makes Write return once the event is queued, keeping
+// the database out of the request path. Events still queued when the
+// process crashes are lost, up to DefaultAuditQueueSize of them, and
+// failures are only logged; do not use it where every acknowledged event
+// must be kept.
+func WithAsyncAuditWrites() AuditStoreOption {
+	return func(s *AuditStore) { s.async = true }
+}
+
+func NewAuditStore(db *gorm.DB, opts ...AuditStoreOption) *AuditStore {
+	s := &AuditStore{
+		Telemetry: otel.Nop(),
+		db:        db,
+		queue:     make(chan auditWrite, DefaultAuditQueueSize),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+	go s.run()
+	return s
+}
+
+// Migrate creates or updates the audit_events table.
+func (s *AuditStore) Migrate(ctx context.Context) error {
+	return s.db.WithContext(ctx).AutoMigrate(&AuditEvent{})
+}
+
+// Write appends event and returns the error of the insert. With
+// WithAsyncAuditWrites it only queues the event, blocking while the queue is
+// full. An event whose ctx ends after it was queued may still be stored.
+func (s *AuditStore) Write(ctx context.Context, event audit.Event) error {
+	write := auditWrite{event: event}
+	if !s.async {
+		write.stored = make(chan error, 1)
+	}
+	if err := s.enqueue(ctx, write); err != nil || s.async {
+		return err
+	}
+	select {
+	case err := <-write.stored:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *AuditStore) enqueue(ctx context.Context, write auditWrite) error {
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		return ErrAuditStoreStopped
+	}
+	select {
+	case s.queue <- write:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop stops accepting events and waits until the queued ones are stored.
+func (s *AuditStore) Stop(ctx context.Context) error {
+	s.closeMu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.closeMu.Unlock()
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *AuditStore) run() {
+	defer close(s.done)
+	for write := range s.queue {
+		err := s.append(context.Background(), write.event)
+		if write.stored != nil {
+			write.stored <- err
+			continue
+		}
+		if err != nil {
+			s.Obs.Error("xgorm: audit event not stored", zap.String("audit.id", write.event.ID), zap.String("audit.action", write.event.Action), zap.Error(err))
+		}
+	}
+}
+
+// append inserts event after the head of the chain. The insert does nothing
+// when another instance appended to the same head first; the head is then
+// read again and the insert retried. Other errors are not retried.
+func (s *AuditStore) append(ctx context.Context, event audit.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	db := s.db.WithContext(ctx)
+	for range maxAuditAppendAttempts {
+		if !s.headLoaded {
+			var head AuditEvent
+			switch err := db.Select("hash").Order("seq DESC").Limit(1).Take(&head).Error; {
+			case err == nil:
+				s.head = head.Hash
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				s.head = ""
+			default:
+				return err
+			}
+			s.headLoaded = true
+		}
+
+		row := AuditEvent{
+			ID:           event.ID,
+			Time:         event.Time.UTC().Truncate(AuditTimePrecision),
+			Action:       event.Action,
+			Outcome:      string(event.Outcome),
+			ActorType:    event.Actor.Type,
+			ActorSubject: event.Actor.Subject,
+			ActorAppID:   event.Actor.AppID,
+			ResourceType: event.Resource.Type,
+			ResourceID:   event.Resource.ID,
+			RequestID:    event.RequestID,
+			Status:       event.Status,
+			Data:         data,
+			PrevHash:     s.head,
+		}
+		row.Hash = auditHash(row)
+		res := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 1 {
+			s.head = row.Hash
+			return nil
+		}
+
+		var stored int64
+		if err := db.Model(&AuditEvent{}).Where("id = ?", event.ID).Count(&stored).Error; err != nil {
+			return err
+		}
+		if stored > 0 {
+			return nil
+		}
+		s.headLoaded = false
+	}
+	return fmt.Errorf("xgorm: append audit event %s: %w", event.ID, ErrAuditChainContended)
+}
+
+func (s *AuditStore) Query(ctx context.Context, filter audit.Filter) ([]audit.Event, error) {
+	q := s.db.WithContext(ctx).Order("seq DESC").Limit(filter.LimitOrDefault())
+	if filter.Actor != "" {
+		q = q.Where("actor_subject = ? OR actor_app_id = ?", filter.Actor, filter.Actor)
+	}
+	if filter.Action != "" {
+		q = q.Where("action = ?", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		q = q.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		q = q.Where("resource_id = ?", filter.ResourceID)
+	}
+	if !filter.From.IsZero() {
+		q = q.Where("time >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		q = q.Where("time < ?", filter.To)
+	}
+	var rows []AuditEvent
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]audit.Event, len(rows))
+	for i, row := range rows {
+		if err := json.Unmarshal(row.Data, &out[i]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Verify walks the chain from the first row and returns the sequence number
+// of the first row that does not match, with ErrAuditChainBroken. Removing
+// the newest rows leaves a valid chain, so keep the latest Hash elsewhere to
+// detect truncation.
+func (s *AuditStore) Verify(ctx context.Context) (int64, error) {
+	prev := ""
+	var broken int64
+	var rows []AuditEvent
+	err := s.db.WithContext(ctx).Order("seq").FindInBatches(&rows, 500, func(tx *gorm.DB, _ int) error {
+		for _, row := range rows {
+			if row.PrevHash != prev || row.Hash != auditHash(row) {
+				broken = row.Seq
+				return ErrAuditChainBroken
+			}
+			prev = row.Hash
+		}
+		return nil
+	}).Error
+	if err != nil {
+		return broken, err
+	}
+	return 0, nil
+}
+
+// auditHash hashes row's PrevHash, filter columns and Data, so editing a
+// column used by Query breaks the chain as much as editing the event.
+func auditHash(row AuditEvent) string {
+	columns, _ := json.Marshal([]any{
+		row.ID, row.Time.UnixMilli(), row.Action, row.Outcome,
+		row.ActorType, row.ActorSubject, row.ActorAppID,
+		row.ResourceType, row.ResourceID, row.RequestID, row.Status,
+	})
+	h := sha256.New()
+	h.Write([]byte(row.PrevHash))
+	h.Write([]byte("\n"))
+	h.Write(columns)
+	h.Write([]byte("\n"))
+	h.Write(row.Data)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,110 @@
+package xgorm_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/audit"
+	xgorm "github.com/bronystylecrazy/ultrastructure/x/gorm"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestAuditStoreChainsAndDetectsTampering(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	store := xgorm.NewAuditStore(db)
+	require.NoError(t, store.Migrate(ctx))
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	for i, id := range []string{"o-1", "o-2", "o-3"} {
+		require.NoError(t, store.Write(ctx, audit.Event{
+			ID:       "ev-" + id,
+			Time:     now.Add(time.Duration(i) * time.Second),
+			Action:   "order.cancel",
+			Outcome:  audit.OutcomeSuccess,
+			Actor:    audit.Actor{Type: "user", Subject: "u-1"},
+			Resource: audit.Resource{Type: "order", ID: id},
+		}))
+	}
+	require.NoError(t, store.Stop(ctx))
+	require.ErrorIs(t, store.Write(ctx, audit.Event{ID: "ev-late"}), xgorm.ErrAuditStoreStopped)
+
+	events, err := store.Query(ctx, audit.Filter{Actor: "u-1", From: now.Add(time.Second)})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, "o-3", events[0].Resource.ID)
+
+	seq, err := store.Verify(ctx)
+	require.NoError(t, err)
+	require.Zero(t, seq)
+
+	require.NoError(t, db.Exec(`UPDATE audit_events SET data = replace(data, '"u-1"', '"u-2"') WHERE seq = 2`).Error)
+	seq, err = store.Verify(ctx)
+	require.ErrorIs(t, err, xgorm.ErrAuditChainBroken)
+	require.EqualValues(t, 2, seq)
+
+	require.NoError(t, db.Exec(`UPDATE audit_events SET data = replace(data, '"u-2"', '"u-1"') WHERE seq = 2`).Error)
+	require.NoError(t, db.Exec(`UPDATE audit_events SET resource_id = 'o-9' WHERE seq = 3`).Error)
+	seq, err = store.Verify(ctx)
+	require.ErrorIs(t, err, xgorm.ErrAuditChainBroken)
+	require.EqualValues(t, 3, seq)
+}
+
+func TestAuditStoreAppendsAfterAnotherInstance(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "audit.db")+"?_busy_timeout=5000"), &gorm.Config{})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	first, second := xgorm.NewAuditStore(db), xgorm.NewAuditStore(db)
+	require.NoError(t, first.Migrate(ctx))
+
+	written := func(n int) func() bool {
+		return func() bool {
+			var count int64
+			return db.Model(&xgorm.AuditEvent{}).Count(&count).Error == nil && count == int64(n)
+		}
+	}
+	now := time.Now().UTC()
+	require.NoError(t, first.Write(ctx, audit.Event{ID: "ev-1", Time: now, Action: "order.create"}))
+	require.Eventually(t, written(1), time.Second, 5*time.Millisecond)
+	require.NoError(t, second.Write(ctx, audit.Event{ID: "ev-2", Time: now, Action: "order.create"}))
+	require.NoError(t, second.Stop(ctx))
+	// first still holds ev-1 as its head; the insert conflicts and retries.
+	require.NoError(t, first.Write(ctx, audit.Event{ID: "ev-3", Time: now, Action: "order.create"}))
+	require.NoError(t, first.Stop(ctx))
+
+	require.True(t, written(3)())
+	seq, err := first.Verify(ctx)
+	require.NoError(t, err)
+	require.Zero(t, seq)
+}
+
+func TestAuditStoreVerifiesAtStoredTimePrecision(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	store := xgorm.NewAuditStore(db, xgorm.WithAsyncAuditWrites())
+	require.NoError(t, store.Migrate(ctx))
+
+	at := time.Date(2026, 3, 4, 5, 6, 7, 123456789, time.UTC)
+	require.NoError(t, store.Write(ctx, audit.Event{ID: "ev-1", Time: at, Action: "order.create"}))
+	require.NoError(t, store.Stop(ctx))
+
+	var row xgorm.AuditEvent
+	require.NoError(t, db.First(&row).Error)
+	require.True(t, row.Time.Equal(at.Truncate(time.Millisecond)), "stored %s", row.Time)
+
+	// A datetime(3) column hands back the same milliseconds in the server's
+	// time zone.
+	require.NoError(t, db.Exec(`UPDATE audit_events SET time = ?`, at.Truncate(time.Millisecond).In(time.FixedZone("ICT", 7*3600))).Error)
+	seq, err := store.Verify(ctx)
+	require.NoError(t, err)
+	require.Zero(t, seq)
+}
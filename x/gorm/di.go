@@ -64,6 +64,9 @@ func Use(opts ...Option) di.Node {
 		di.Provide(NewDB),
 		di.Provide(NewSQLDB),
 		di.Provide(NewChecker),
+		di.Provide(NewHealthChecker),
+		di.Provide(NewIdempotencyStore),
+		di.Provide(NewErrorMapper),
 		di.Provide(gormOtel, di.Params(``, ``, ``, ``, ``, di.Optional(), di.Optional())),
 		// Building GormOtel is what installs the logger and the tracing plugin,
 		// and it settles to nothing when telemetry is off, so the application
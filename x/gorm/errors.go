@@ -0,0 +1,29 @@
+package xgorm
+
+import (
+	"net/http"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"gorm.io/gorm"
+)
+
+// ErrorMapper renders gorm errors through the web error handler, so a handler
+// can return a query error as is.
+type ErrorMapper struct {
+	mappings web.ErrorMappings
+}
+
+func NewErrorMapper() *ErrorMapper {
+	return &ErrorMapper{
+		mappings: web.ErrorMappings{
+			{Target: gorm.ErrRecordNotFound, Status: http.StatusNotFound, Code: "NOT_FOUND", Message: "resource not found"},
+			{Target: gorm.ErrDuplicatedKey, Status: http.StatusConflict, Code: "CONFLICT", Message: "resource already exists"},
+			{Target: gorm.ErrForeignKeyViolated, Status: http.StatusConflict, Code: "CONFLICT", Message: "resource is referenced by another resource"},
+			{Target: gorm.ErrCheckConstraintViolated, Status: http.StatusUnprocessableEntity, Code: "CONSTRAINT_VIOLATED", Message: "resource violates a constraint"},
+		},
+	}
+}
+
+func (m *ErrorMapper) MapError(err error) (*web.HTTPError, bool) {
+	return m.mappings.MapError(err)
+}
@@ -0,0 +1,29 @@
+package xgorm
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// HealthChecker reports database reachability to the health service on every
+// readiness probe, unlike Checker which only runs while the application starts.
+type HealthChecker struct {
+	db *gorm.DB
+}
+
+func NewHealthChecker(db *gorm.DB) *HealthChecker {
+	return &HealthChecker{db: db}
+}
+
+func (h *HealthChecker) Name() string {
+	return "database"
+}
+
+func (h *HealthChecker) Check(ctx context.Context) error {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
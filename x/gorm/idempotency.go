@@ -0,0 +1,112 @@
+package xgorm
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyKey is the row kept for one web.Idempotent key.
+type IdempotencyKey struct {
+	Key         string    `gorm:"column:idempotency_key;primaryKey;size:128"`
+	Fingerprint string    `gorm:"size:64;not null"`
+	Completed   bool      `gorm:"not null;default:false"`
+	Status      int       `gorm:"not null;default:0"`
+	Header      []byte    `gorm:"column:header"`
+	Body        []byte    `gorm:"column:body"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+// IdempotencyStore keeps web.Idempotent records in the application database.
+// Create the table with Migrate or an equivalent migration.
+//
+// Usage: r.Post("/orders", h.Create).With(web.Idempotent(24*time.Hour, web.WithIdempotencyStore(store)))
+type IdempotencyStore struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+func NewIdempotencyStore(db *gorm.DB) *IdempotencyStore {
+	return &IdempotencyStore{db: db, now: time.Now}
+}
+
+// Migrate creates or updates the idempotency_keys table.
+func (s *IdempotencyStore) Migrate(ctx context.Context) error {
+	return s.db.WithContext(ctx).AutoMigrate(&IdempotencyKey{})
+}
+
+func (s *IdempotencyStore) Acquire(ctx context.Context, key string, fingerprint string, lockTTL time.Duration) (web.IdempotencyRecord, bool, error) {
+	db := s.db.WithContext(ctx)
+	now := s.now()
+
+	if err := db.Where("idempotency_key = ? AND expires_at <= ?", key, now).Delete(&IdempotencyKey{}).Error; err != nil {
+		return web.IdempotencyRecord{}, false, err
+	}
+
+	lock := IdempotencyKey{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(lockTTL),
+	}
+	res := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&lock)
+	if res.Error != nil {
+		return web.IdempotencyRecord{}, false, res.Error
+	}
+	if res.RowsAffected == 1 {
+		return web.IdempotencyRecord{Fingerprint: fingerprint}, true, nil
+	}
+
+	var row IdempotencyKey
+	if err := db.Where("idempotency_key = ?", key).First(&row).Error; err != nil {
+		return web.IdempotencyRecord{}, false, err
+	}
+	record, err := row.record()
+	return record, false, err
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, record web.IdempotencyRecord, ttl time.Duration) error {
+	header, err := json.Marshal(record.Response.Header)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).
+		Model(&IdempotencyKey{}).
+		Where("idempotency_key = ?", key).
+		Updates(map[string]any{
+			"fingerprint": record.Fingerprint,
+			"completed":   record.Completed,
+			"status":      record.Response.Status,
+			"header":      header,
+			"body":        record.Response.Body,
+			"expires_at":  s.now().Add(ttl),
+		}).Error
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).Where("idempotency_key = ?", key).Delete(&IdempotencyKey{}).Error
+}
+
+func (k IdempotencyKey) record() (web.IdempotencyRecord, error) {
+	record := web.IdempotencyRecord{
+		Fingerprint: k.Fingerprint,
+		Completed:   k.Completed,
+		Response: web.IdempotentResponse{
+			Status: k.Status,
+			Body:   k.Body,
+		},
+	}
+	if len(k.Header) > 0 {
+		if err := json.Unmarshal(k.Header, &record.Response.Header); err != nil {
+			return web.IdempotencyRecord{}, err
+		}
+	}
+	return record, nil
+}
@@ -0,0 +1,55 @@
+package xgorm_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	xgorm "github.com/bronystylecrazy/ultrastructure/x/gorm"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestIdempotencyStoreLifecycle(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	store := xgorm.NewIdempotencyStore(db)
+	require.NoError(t, store.Migrate(ctx))
+
+	_, acquired, err := store.Acquire(ctx, "k1", "fp", time.Minute)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	record, acquired, err := store.Acquire(ctx, "k1", "fp", time.Minute)
+	require.NoError(t, err)
+	require.False(t, acquired)
+	require.False(t, record.Completed)
+
+	require.NoError(t, store.Complete(ctx, "k1", web.IdempotencyRecord{
+		Fingerprint: "fp",
+		Completed:   true,
+		Response: web.IdempotentResponse{
+			Status: http.StatusCreated,
+			Header: map[string][]string{"Location": {"/orders/1"}},
+			Body:   []byte(`{"id":1}`),
+		},
+	}, time.Hour))
+
+	record, acquired, err = store.Acquire(ctx, "k1", "fp", time.Minute)
+	require.NoError(t, err)
+	require.False(t, acquired)
+	require.True(t, record.Completed)
+	require.Equal(t, http.StatusCreated, record.Response.Status)
+	require.Equal(t, []string{"/orders/1"}, record.Response.Header["Location"])
+	require.Equal(t, `{"id":1}`, string(record.Response.Body))
+
+	require.NoError(t, store.Release(ctx, "k1"))
+	_, acquired, err = store.Acquire(ctx, "k1", "other", time.Minute)
+	require.NoError(t, err)
+	require.True(t, acquired)
+}
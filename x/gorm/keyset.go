@@ -0,0 +1,37 @@
+package xgorm
+
+import (
+	"github.com/bronystylecrazy/ultrastructure/database"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Keyset scopes a query to the page after cursor (or before it for a
+// prev_cursor), ordered by columns and limited to limit+1 rows for
+// web.NewCursorPage. A cursor whose keys do not match columns fails the
+// query with web.ErrInvalidCursor.
+//
+// Usage:
+//
+//	columns := []database.KeysetColumn{{Name: "created_at", Desc: true}, {Name: "id", Desc: true}}
+//	err := db.Scopes(xgorm.Keyset(cursor, limit, columns...)).Find(&orders).Error
+func Keyset(cursor web.Cursor, limit int, columns ...database.KeysetColumn) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !cursor.IsZero() {
+			where, args, err := database.KeysetWhere(columns, cursor.Keys, cursor.Backward(), func(int) string { return "?" })
+			if err != nil {
+				_ = db.AddError(web.ErrInvalidCursor)
+				return db
+			}
+			db = db.Where(clause.Expr{SQL: where, Vars: args})
+		}
+		if order := database.KeysetOrder(columns, cursor.Backward()); order != "" {
+			db = db.Order(order)
+		}
+		if limit > 0 {
+			db = db.Limit(limit + 1)
+		}
+		return db
+	}
+}
@@ -0,0 +1,85 @@
+package xgorm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/database"
+	"github.com/bronystylecrazy/ultrastructure/web"
+	xgorm "github.com/bronystylecrazy/ultrastructure/x/gorm"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type keysetOrder struct {
+	ID        int64
+	Priority  int
+	CreatedAt time.Time
+}
+
+func TestKeysetWalksPagesBothWays(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&keysetOrder{}))
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := int64(1); i <= 5; i++ {
+		// Two rows share each timestamp so the id tie-breaker matters.
+		require.NoError(t, db.Create(&keysetOrder{ID: i, CreatedAt: base.Add(time.Duration(i/2) * time.Hour)}).Error)
+	}
+
+	codec := web.NewCursorCodec("secret")
+	columns := []database.KeysetColumn{{Name: "created_at", Desc: true}, {Name: "id", Desc: true}}
+	keys := func(o keysetOrder) []any { return []any{o.CreatedAt, o.ID} }
+	fetch := func(token string) web.CursorPage[keysetOrder] {
+		cursor, err := codec.Decode(token)
+		require.NoError(t, err)
+		var rows []keysetOrder
+		require.NoError(t, db.Scopes(xgorm.Keyset(cursor, 2, columns...)).Find(&rows).Error)
+		page, err := web.NewCursorPage(codec, cursor, rows, 2, keys)
+		require.NoError(t, err)
+		return page
+	}
+	ids := func(page web.CursorPage[keysetOrder]) []int64 {
+		out := make([]int64, 0, len(page.Data))
+		for _, o := range page.Data {
+			out = append(out, o.ID)
+		}
+		return out
+	}
+
+	first := fetch("")
+	require.Equal(t, []int64{5, 4}, ids(first))
+	second := fetch(first.Meta.NextCursor)
+	require.Equal(t, []int64{3, 2}, ids(second))
+	last := fetch(second.Meta.NextCursor)
+	require.Equal(t, []int64{1}, ids(last))
+	require.False(t, last.Meta.HasNext)
+
+	back := fetch(last.Meta.PrevCursor)
+	require.Equal(t, []int64{3, 2}, ids(back))
+	require.True(t, back.Meta.HasPrev)
+	require.Equal(t, []int64{5, 4}, ids(fetch(back.Meta.PrevCursor)))
+}
+
+func TestKeysetMixedDirectionsAndMismatchedCursor(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&keysetOrder{}))
+	for i, priority := range []int{1, 2, 2, 3} {
+		require.NoError(t, db.Create(&keysetOrder{ID: int64(i + 1), Priority: priority}).Error)
+	}
+
+	columns := []database.KeysetColumn{{Name: "priority", Desc: true}, {Name: "id"}}
+	var rows []keysetOrder
+	cursor := web.Cursor{Direction: web.CursorNext, Keys: []any{int64(2), int64(2)}}
+	require.NoError(t, db.Scopes(xgorm.Keyset(cursor, 10, columns...)).Find(&rows).Error)
+	require.Len(t, rows, 2)
+	require.Equal(t, int64(3), rows[0].ID)
+	require.Equal(t, int64(1), rows[1].ID)
+
+	cursor.Keys = cursor.Keys[:1]
+	err = db.Scopes(xgorm.Keyset(cursor, 10, columns...)).Find(&rows).Error
+	require.ErrorIs(t, err, web.ErrInvalidCursor)
+}
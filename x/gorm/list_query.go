@@ -0,0 +1,22 @@
+package xgorm
+
+import (
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListScope applies the filters and sort order of q.
+//
+// Usage: err := db.Scopes(xgorm.ListScope(q)).Find(&orders).Error
+func ListScope[T any](q web.ListQuery[T]) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if where, args := q.Where(func(int) string { return "?" }); where != "" {
+			db = db.Where(clause.Expr{SQL: where, Vars: args})
+		}
+		if order := q.OrderBy(); order != "" {
+			db = db.Order(order)
+		}
+		return db
+	}
+}
@@ -0,0 +1,56 @@
+package xgorm_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	xgorm "github.com/bronystylecrazy/ultrastructure/x/gorm"
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type listProduct struct {
+	ID    int64
+	Name  string
+	Price int
+}
+
+type listProductFilter struct {
+	Name  string `json:"name" filter:"like"`
+	Price int    `json:"price" filter:"gte,lt" sort:"true"`
+}
+
+func TestListScopeFiltersAndSorts(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&listProduct{}))
+	for _, p := range []listProduct{{Name: "apple", Price: 3}, {Name: "pineapple", Price: 9}, {Name: "apricot", Price: 5}, {Name: "grape", Price: 12}} {
+		require.NoError(t, db.Create(&p).Error)
+	}
+
+	var names []string
+	app := fiber.New()
+	app.Get("/products", func(c fiber.Ctx) error {
+		q, err := web.ParseListQuery[listProductFilter](c)
+		if err != nil {
+			return err
+		}
+		var products []listProduct
+		if err := db.Scopes(xgorm.ListScope(q)).Find(&products).Error; err != nil {
+			return err
+		}
+		for _, p := range products {
+			names = append(names, p.Name)
+		}
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	res, err := app.Test(httptest.NewRequest(http.MethodGet, "/products?filter[name][like]=p&filter[price][lt]=10&sort=-price", nil))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.Equal(t, []string{"pineapple", "apricot", "apple"}, names)
+}
@@ -0,0 +1,320 @@
+package xgorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/webhook"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookEndpoint is the row kept for one webhook.Endpoint.
+type WebhookEndpoint struct {
+	ID                  string   `gorm:"primaryKey;size:36"`
+	URL                 string   `gorm:"size:2048;not null"`
+	Secret              string   `gorm:"size:128;not null"`
+	Events              []string `gorm:"serializer:json"`
+	Description         string   `gorm:"size:512"`
+	Disabled            bool     `gorm:"not null;default:false"`
+	DisabledReason      string   `gorm:"size:512"`
+	ConsecutiveFailures int      `gorm:"not null;default:0"`
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+// WebhookDelivery is the row kept for one webhook.Delivery. Version guards
+// claims so concurrent workers never send the same attempt twice.
+type WebhookDelivery struct {
+	ID             string    `gorm:"primaryKey;size:36"`
+	EndpointID     string    `gorm:"size:36;not null;index"`
+	EventID        string    `gorm:"size:36;not null"`
+	EventType      string    `gorm:"size:255;not null"`
+	Payload        []byte    `gorm:"not null"`
+	Status         string    `gorm:"size:16;not null;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts       int       `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time `gorm:"not null;index:idx_webhook_deliveries_due,priority:2"`
+	LastStatusCode int       `gorm:"not null;default:0"`
+	LastError      string    `gorm:"size:1024"`
+	Version        int64     `gorm:"not null;default:0"`
+	CreatedAt      time.Time `gorm:"index"`
+	UpdatedAt      time.Time
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookAttempt is the row kept for one webhook.Attempt.
+type WebhookAttempt struct {
+	ID         string `gorm:"primaryKey;size:36"`
+	DeliveryID string `gorm:"size:36;not null;index"`
+	Number     int    `gorm:"not null"`
+	StatusCode int    `gorm:"not null;default:0"`
+	Error      string `gorm:"size:1024"`
+	LatencyMS  int64  `gorm:"column:latency_ms;not null;default:0"`
+	CreatedAt  time.Time
+}
+
+func (WebhookAttempt) TableName() string {
+	return "webhook_attempts"
+}
+
+// WebhookStore keeps webhook endpoints, deliveries and attempts in the
+// application database. Create the tables with Migrate or an equivalent
+// migration.
+//
+// Usage: webhook.Providers(webhook.UseStore(xgorm.NewWebhookStore(db)))
+type WebhookStore struct {
+	db *gorm.DB
+}
+
+func NewWebhookStore(db *gorm.DB) *WebhookStore {
+	return &WebhookStore{db: db}
+}
+
+// Migrate creates or updates the webhook tables.
+func (s *WebhookStore) Migrate(ctx context.Context) error {
+	return s.db.WithContext(ctx).AutoMigrate(&WebhookEndpoint{}, &WebhookDelivery{}, &WebhookAttempt{})
+}
+
+func (s *WebhookStore) SaveEndpoint(ctx context.Context, endpoint webhook.Endpoint) error {
+	row := WebhookEndpoint{
+		ID:                  endpoint.ID,
+		URL:                 endpoint.URL,
+		Secret:              endpoint.Secret,
+		Events:              endpoint.Events,
+		Description:         endpoint.Description,
+		Disabled:            endpoint.Disabled,
+		DisabledReason:      endpoint.DisabledReason,
+		ConsecutiveFailures: endpoint.ConsecutiveFailures,
+		CreatedAt:           endpoint.CreatedAt,
+		UpdatedAt:           endpoint.UpdatedAt,
+	}
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&row).Error
+}
+
+func (s *WebhookStore) Endpoint(ctx context.Context, id string) (webhook.Endpoint, error) {
+	var row WebhookEndpoint
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+		return webhook.Endpoint{}, webhookNotFound(err)
+	}
+	return row.endpoint(), nil
+}
+
+func (s *WebhookStore) Endpoints(ctx context.Context) ([]webhook.Endpoint, error) {
+	var rows []WebhookEndpoint
+	if err := s.db.WithContext(ctx).Order("created_at").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]webhook.Endpoint, len(rows))
+	for i, row := range rows {
+		out[i] = row.endpoint()
+	}
+	return out, nil
+}
+
+func (s *WebhookStore) DeleteEndpoint(ctx context.Context, id string) error {
+	res := s.db.WithContext(ctx).Where("id = ?", id).Delete(&WebhookEndpoint{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return webhook.ErrNotFound
+	}
+	return nil
+}
+
+func (s *WebhookStore) CreateDeliveries(ctx context.Context, deliveries []webhook.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	rows := make([]WebhookDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		rows[i] = newWebhookDelivery(delivery)
+	}
+	return s.db.WithContext(ctx).Create(&rows).Error
+}
+
+// ClaimDeliveries leases due deliveries with a versioned update per row, so a
+// row another worker claimed first is skipped.
+func (s *WebhookStore) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]webhook.Delivery, error) {
+	db := s.db.WithContext(ctx)
+	var rows []WebhookDelivery
+	err := db.Where("status = ? AND next_attempt_at <= ?", string(webhook.DeliveryPending), now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	leased := now.Add(lease)
+	out := make([]webhook.Delivery, 0, len(rows))
+	for _, row := range rows {
+		res := db.Model(&WebhookDelivery{}).
+			Where("id = ? AND version = ?", row.ID, row.Version).
+			Updates(map[string]any{"next_attempt_at": leased, "version": row.Version + 1})
+		if res.Error != nil {
+			return out, res.Error
+		}
+		if res.RowsAffected == 1 {
+			row.NextAttemptAt = leased
+			out = append(out, row.delivery())
+		}
+	}
+	return out, nil
+}
+
+func (s *WebhookStore) RecordAttempt(ctx context.Context, delivery webhook.Delivery, attempt webhook.Attempt) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		row := WebhookAttempt{
+			ID:         attempt.ID,
+			DeliveryID: attempt.DeliveryID,
+			Number:     attempt.Number,
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			LatencyMS:  attempt.LatencyMS,
+			CreatedAt:  attempt.CreatedAt,
+		}
+		if err := tx.Create(&row).Error; err != nil {
+			return err
+		}
+		return updateDelivery(tx, delivery)
+	})
+}
+
+func (s *WebhookStore) UpdateDelivery(ctx context.Context, delivery webhook.Delivery) error {
+	return updateDelivery(s.db.WithContext(ctx), delivery)
+}
+
+func (s *WebhookStore) Delivery(ctx context.Context, id string) (webhook.Delivery, error) {
+	var row WebhookDelivery
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&row).Error; err != nil {
+		return webhook.Delivery{}, webhookNotFound(err)
+	}
+	return row.delivery(), nil
+}
+
+func (s *WebhookStore) Deliveries(ctx context.Context, filter webhook.DeliveryFilter) ([]webhook.Delivery, error) {
+	q := s.db.WithContext(ctx).Order("created_at DESC").Limit(filter.LimitOrDefault())
+	if filter.EndpointID != "" {
+		q = q.Where("endpoint_id = ?", filter.EndpointID)
+	}
+	if filter.Status != "" {
+		q = q.Where("status = ?", string(filter.Status))
+	}
+	var rows []WebhookDelivery
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]webhook.Delivery, len(rows))
+	for i, row := range rows {
+		out[i] = row.delivery()
+	}
+	return out, nil
+}
+
+func (s *WebhookStore) Attempts(ctx context.Context, deliveryID string) ([]webhook.Attempt, error) {
+	var rows []WebhookAttempt
+	if err := s.db.WithContext(ctx).Where("delivery_id = ?", deliveryID).Order("number").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]webhook.Attempt, len(rows))
+	for i, row := range rows {
+		out[i] = webhook.Attempt{
+			ID:         row.ID,
+			DeliveryID: row.DeliveryID,
+			Number:     row.Number,
+			StatusCode: row.StatusCode,
+			Error:      row.Error,
+			LatencyMS:  row.LatencyMS,
+			CreatedAt:  row.CreatedAt,
+		}
+	}
+	return out, nil
+}
+
+// updateDelivery writes the delivery state and bumps its version, ending any
+// claim on it.
+func updateDelivery(db *gorm.DB, delivery webhook.Delivery) error {
+	res := db.Model(&WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]any{
+			"status":           string(delivery.Status),
+			"attempts":         delivery.Attempts,
+			"next_attempt_at":  delivery.NextAttemptAt,
+			"last_status_code": delivery.LastStatusCode,
+			"last_error":       delivery.LastError,
+			"version":          gorm.Expr("version + 1"),
+			"updated_at":       delivery.UpdatedAt,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return webhook.ErrNotFound
+	}
+	return nil
+}
+
+func webhookNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return webhook.ErrNotFound
+	}
+	return err
+}
+
+func newWebhookDelivery(d webhook.Delivery) WebhookDelivery {
+	return WebhookDelivery{
+		ID:             d.ID,
+		EndpointID:     d.EndpointID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        d.Payload,
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+}
+
+func (r WebhookEndpoint) endpoint() webhook.Endpoint {
+	return webhook.Endpoint{
+		ID:                  r.ID,
+		URL:                 r.URL,
+		Secret:              r.Secret,
+		Events:              r.Events,
+		Description:         r.Description,
+		Disabled:            r.Disabled,
+		DisabledReason:      r.DisabledReason,
+		ConsecutiveFailures: r.ConsecutiveFailures,
+		CreatedAt:           r.CreatedAt,
+		UpdatedAt:           r.UpdatedAt,
+	}
+}
+
+func (r WebhookDelivery) delivery() webhook.Delivery {
+	return webhook.Delivery{
+		ID:             r.ID,
+		EndpointID:     r.EndpointID,
+		EventID:        r.EventID,
+		EventType:      r.EventType,
+		Payload:        r.Payload,
+		Status:         webhook.DeliveryStatus(r.Status),
+		Attempts:       r.Attempts,
+		NextAttemptAt:  r.NextAttemptAt,
+		LastStatusCode: r.LastStatusCode,
+		LastError:      r.LastError,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+	}
+}
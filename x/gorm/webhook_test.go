@@ -0,0 +1,66 @@
+package xgorm_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/webhook"
+	xgorm "github.com/bronystylecrazy/ultrastructure/x/gorm"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestWebhookStoreLifecycle(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	store := xgorm.NewWebhookStore(db)
+	require.NoError(t, store.Migrate(ctx))
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	endpoint := webhook.Endpoint{ID: "e-1", URL: "https://example.com/hook", Secret: "s", Events: []string{"order.*"}, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, store.SaveEndpoint(ctx, endpoint))
+	endpoint.Disabled = true
+	require.NoError(t, store.SaveEndpoint(ctx, endpoint))
+	got, err := store.Endpoint(ctx, "e-1")
+	require.NoError(t, err)
+	require.True(t, got.Disabled)
+	require.Equal(t, []string{"order.*"}, got.Events)
+
+	delivery := webhook.Delivery{
+		ID: "d-1", EndpointID: "e-1", EventID: "ev-1", EventType: "order.created",
+		Payload: []byte(`{"id":1}`), Status: webhook.DeliveryPending, NextAttemptAt: now, CreatedAt: now, UpdatedAt: now,
+	}
+	require.NoError(t, store.CreateDeliveries(ctx, []webhook.Delivery{delivery}))
+
+	claimed, err := store.ClaimDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	claimed, err = store.ClaimDeliveries(ctx, now, time.Minute, 10)
+	require.NoError(t, err)
+	require.Empty(t, claimed, "a leased delivery must not be claimed again")
+
+	delivery.Status = webhook.DeliveryDead
+	delivery.Attempts = 1
+	delivery.LastStatusCode = 500
+	require.NoError(t, store.RecordAttempt(ctx, delivery, webhook.Attempt{ID: "a-1", DeliveryID: "d-1", Number: 1, StatusCode: 500, LatencyMS: 12, CreatedAt: now}))
+
+	dead, err := store.Deliveries(ctx, webhook.DeliveryFilter{Status: webhook.DeliveryDead})
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	require.Equal(t, 500, dead[0].LastStatusCode)
+	require.JSONEq(t, `{"id":1}`, string(dead[0].Payload))
+
+	attempts, err := store.Attempts(ctx, "d-1")
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	require.EqualValues(t, 12, attempts[0].LatencyMS)
+
+	require.NoError(t, store.DeleteEndpoint(ctx, "e-1"))
+	_, err = store.Endpoint(ctx, "e-1")
+	require.ErrorIs(t, err, webhook.ErrNotFound)
+	require.ErrorIs(t, store.DeleteEndpoint(ctx, "e-1"), webhook.ErrNotFound)
+}
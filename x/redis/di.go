@@ -4,7 +4,9 @@ import (
 	"github.com/bronystylecrazy/ultrastructure/cfg"
 	"github.com/bronystylecrazy/ultrastructure/di"
 	"github.com/bronystylecrazy/ultrastructure/security/apikey"
+	"github.com/bronystylecrazy/ultrastructure/security/authn"
 	"github.com/bronystylecrazy/ultrastructure/security/session"
+	"github.com/bronystylecrazy/ultrastructure/web"
 )
 
 func Providers(extends ...di.Node) di.Node {
@@ -13,6 +15,10 @@ func Providers(extends ...di.Node) di.Node {
 		di.Provide(NewClient, interfaces()...),
 		di.Provide(NewAPIKeyCacheStore, di.As[apikey.CacheStore]()),
 		di.Provide(NewTokenRevocationCache, di.As[session.RevocationCache]()),
+		di.Provide(NewRateLimitStore, di.As[web.RateLimitStore]()),
+		di.Provide(NewIdempotencyStore, di.As[web.IdempotencyStore]()),
+		di.Provide(NewNonceStore, di.As[authn.NonceStore]()),
+		di.Provide(NewHealthChecker),
 	}
 	nodes = append(nodes, di.ConvertAnys(extends)...)
 	return di.Options(nodes...)
@@ -0,0 +1,24 @@
+package rd
+
+import (
+	"context"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+// HealthChecker pings redis for the health service.
+type HealthChecker struct {
+	client *redis.Client
+}
+
+func NewHealthChecker(client *redis.Client) *HealthChecker {
+	return &HealthChecker{client: client}
+}
+
+func (h *HealthChecker) Name() string {
+	return "redis"
+}
+
+func (h *HealthChecker) Check(ctx context.Context) error {
+	return h.client.Ping(ctx).Err()
+}
@@ -0,0 +1,66 @@
+package rd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	redis "github.com/redis/go-redis/v9"
+)
+
+// IdempotencyStore keeps web.Idempotent records in Redis so retries are
+// recognised by any instance.
+type IdempotencyStore struct {
+	client *redis.Client
+}
+
+func NewIdempotencyStore(client *redis.Client) *IdempotencyStore {
+	return &IdempotencyStore{client: client}
+}
+
+func (s *IdempotencyStore) Acquire(ctx context.Context, key string, fingerprint string, lockTTL time.Duration) (web.IdempotencyRecord, bool, error) {
+	lock := web.IdempotencyRecord{Fingerprint: fingerprint}
+	payload, err := json.Marshal(lock)
+	if err != nil {
+		return web.IdempotencyRecord{}, false, err
+	}
+
+	// The key may expire between SETNX and GET; try once more before giving up.
+	for range 2 {
+		ok, err := s.client.SetNX(ctx, key, payload, lockTTL).Result()
+		if err != nil {
+			return web.IdempotencyRecord{}, false, err
+		}
+		if ok {
+			return lock, true, nil
+		}
+
+		raw, err := s.client.Get(ctx, key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return web.IdempotencyRecord{}, false, err
+		}
+		var record web.IdempotencyRecord
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return web.IdempotencyRecord{}, false, err
+		}
+		return record, false, nil
+	}
+	return lock, false, nil
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, record web.IdempotencyRecord, ttl time.Duration) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, key, payload, ttl).Err()
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
@@ -0,0 +1,28 @@
+package rd
+
+import (
+	"context"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+)
+
+// NonceStore remembers inbound webhook nonces in Redis so a replay is
+// rejected by every instance.
+//
+// Usage: authn.GitHubWebhookAuthenticator("github", secrets, authn.WithWebhookNonceStore(nonces, 0))
+type NonceStore struct {
+	client *redis.Client
+}
+
+func NewNonceStore(client *redis.Client) *NonceStore {
+	return &NonceStore{client: client}
+}
+
+func (s *NonceStore) Remember(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, key, 1, ttl).Result()
+}
+
+func (s *NonceStore) Forget(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
@@ -0,0 +1,130 @@
+package rd
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	redis "github.com/redis/go-redis/v9"
+)
+
+// Every script returns {allowed, remaining, reset_ms, retry_ms}. Window keys
+// are built by windowKey and passed in KEYS, so Redis Cluster can route them.
+
+var fixedWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local start = now - (now % window)
+local key = KEYS[1]
+local reset = start + window - now
+local count = tonumber(redis.call("GET", key) or "0")
+if count >= limit then
+  return {0, 0, reset, reset}
+end
+count = redis.call("INCR", key)
+if count == 1 then
+  redis.call("PEXPIRE", key, window)
+end
+return {1, limit - count, reset, 0}
+`)
+
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local start = now - (now % window)
+local current_key = KEYS[1]
+local previous_key = KEYS[2]
+local current = tonumber(redis.call("GET", current_key) or "0")
+local previous = tonumber(redis.call("GET", previous_key) or "0")
+local elapsed = now - start
+local estimate = previous * (window - elapsed) / window + current
+local reset = window - elapsed
+if estimate + 1 > limit then
+  local free = limit - 1 - current
+  local retry = reset
+  if free >= 0 and previous > 0 then
+    retry = math.max(0, math.ceil(window * (1 - free / previous)) - elapsed)
+  end
+  return {0, 0, reset, retry}
+end
+redis.call("INCR", current_key)
+redis.call("PEXPIRE", current_key, window * 2)
+return {1, math.floor(limit - estimate - 1), reset, 0}
+`)
+
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local per_token = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+  tokens = capacity
+elseif now > ts then
+  tokens = math.min(capacity, tokens + (now - ts) / per_token)
+end
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) * per_token)
+end
+local reset = math.ceil((capacity - tokens) * per_token)
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.max(reset, 1))
+return {allowed, math.floor(tokens), reset, retry}
+`)
+
+// RateLimitStore keeps web.RateLimit counters in Redis so limits hold across
+// instances.
+type RateLimitStore struct {
+	client redis.Scripter
+	now    func() time.Time
+}
+
+func NewRateLimitStore(client *redis.Client) *RateLimitStore {
+	return &RateLimitStore{client: client, now: time.Now}
+}
+
+func (s *RateLimitStore) Take(ctx context.Context, key string, rule web.RateLimitRule) (web.RateLimitDecision, error) {
+	window := max(rule.Window.Milliseconds(), 1)
+	now := s.now().UnixMilli()
+
+	start := now - now%window
+
+	var res []int64
+	var err error
+	switch rule.Algorithm {
+	case web.RateLimitSlidingWindow:
+		keys := []string{windowKey(key, start), windowKey(key, start-window)}
+		res, err = slidingWindowScript.Run(ctx, s.client, keys, rule.Limit, window, now).Int64Slice()
+	case web.RateLimitTokenBucket:
+		perToken := max(window/int64(rule.Limit), 1)
+		res, err = tokenBucketScript.Run(ctx, s.client, []string{key}, rule.Capacity(), perToken, now).Int64Slice()
+	default:
+		res, err = fixedWindowScript.Run(ctx, s.client, []string{windowKey(key, start)}, rule.Limit, window, now).Int64Slice()
+	}
+	if err != nil {
+		return web.RateLimitDecision{}, err
+	}
+
+	return web.RateLimitDecision{
+		Allowed:    res[0] == 1,
+		Limit:      rule.Capacity(),
+		Remaining:  int(res[1]),
+		ResetAfter: time.Duration(res[2]) * time.Millisecond,
+		RetryAfter: time.Duration(res[3]) * time.Millisecond,
+	}, nil
+}
+
+// windowKey names the counter of the window starting at start. The hash tag
+// keeps every window of key in one cluster slot.
+func windowKey(key string, start int64) string {
+	return "{" + key + "}:" + strconv.FormatInt(start, 10)
+}
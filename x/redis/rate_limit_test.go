@@ -0,0 +1,47 @@
+package rd_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+	"github.com/bronystylecrazy/ultrastructure/x/redis"
+)
+
+func TestRateLimitStoreAlgorithms(t *testing.T) {
+	client, err := rd.NewClient(rd.Config{InMemory: true})
+	if err != nil {
+		t.Fatalf("new redis client: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	store := rd.NewRateLimitStore(client)
+	ctx := context.Background()
+
+	for _, algorithm := range []web.RateLimitAlgorithm{
+		web.RateLimitFixedWindow,
+		web.RateLimitSlidingWindow,
+		web.RateLimitTokenBucket,
+	} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			rule := web.RateLimitRule{Algorithm: algorithm, Limit: 3, Window: time.Hour}
+			for i := range 3 {
+				d, err := store.Take(ctx, "ratelimit:test:"+string(algorithm), rule)
+				if err != nil {
+					t.Fatalf("take %d: %v", i, err)
+				}
+				if !d.Allowed || d.Remaining != 2-i {
+					t.Fatalf("take %d: %+v", i, d)
+				}
+			}
+			d, err := store.Take(ctx, "ratelimit:test:"+string(algorithm), rule)
+			if err != nil {
+				t.Fatalf("take: %v", err)
+			}
+			if d.Allowed || d.RetryAfter <= 0 || d.Limit != 3 {
+				t.Fatalf("expected rejection: %+v", d)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ func Providers() di.Node {
 	return di.Options(
 		di.Default(NewPool),
 		di.Default(NewDB),
+		di.Provide(NewHealthChecker, di.Params(di.Optional(), di.Optional())),
 	)
 }
 
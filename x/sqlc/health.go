@@ -0,0 +1,34 @@
+package sqlc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// HealthChecker pings the pgx pool, or the database/sql handle when no pool
+// is configured, for the health service.
+type HealthChecker struct {
+	pool *pgxpool.Pool
+	db   *sql.DB
+}
+
+func NewHealthChecker(pool *pgxpool.Pool, db *sql.DB) *HealthChecker {
+	return &HealthChecker{pool: pool, db: db}
+}
+
+func (h *HealthChecker) Name() string {
+	return "sql"
+}
+
+func (h *HealthChecker) Check(ctx context.Context) error {
+	if h.pool != nil {
+		return h.pool.Ping(ctx)
+	}
+	if h.db != nil {
+		return h.db.PingContext(ctx)
+	}
+	return errors.New("sqlc: no database handle configured")
+}
@@ -0,0 +1,50 @@
+package sqlc
+
+import (
+	"strconv"
+
+	"github.com/bronystylecrazy/ultrastructure/database"
+	"github.com/bronystylecrazy/ultrastructure/web"
+)
+
+// KeysetQuery holds the clauses of a keyset-paginated pgx query.
+type KeysetQuery struct {
+	// Where is the keyset condition, or TRUE on the first page.
+	Where   string
+	OrderBy string
+	Args    []any
+	// Limit is the page size plus one row to detect a next page.
+	Limit int
+}
+
+// Keyset builds Postgres keyset clauses from cursor. Placeholders are
+// numbered from firstArg so they can follow the query's own arguments.
+//
+// Usage:
+//
+//	ks, err := sqlc.Keyset(cursor, limit, 2, database.KeysetColumn{Name: "created_at", Desc: true}, database.KeysetColumn{Name: "id", Desc: true})
+//	rows, err := pool.Query(ctx, "SELECT * FROM orders WHERE tenant_id = $1 AND "+ks.Where+
+//		" ORDER BY "+ks.OrderBy+" LIMIT "+strconv.Itoa(ks.Limit), append([]any{tenantID}, ks.Args...)...)
+func Keyset(cursor web.Cursor, limit, firstArg int, columns ...database.KeysetColumn) (KeysetQuery, error) {
+	if firstArg < 1 {
+		firstArg = 1
+	}
+	query := KeysetQuery{
+		Where:   "TRUE",
+		OrderBy: database.KeysetOrder(columns, cursor.Backward()),
+		Limit:   limit + 1,
+	}
+	if cursor.IsZero() {
+		return query, nil
+	}
+
+	where, args, err := database.KeysetWhere(columns, cursor.Keys, cursor.Backward(), func(n int) string {
+		return "$" + strconv.Itoa(firstArg+n-1)
+	})
+	if err != nil {
+		return KeysetQuery{}, web.ErrInvalidCursor
+	}
+	query.Where = where
+	query.Args = args
+	return query, nil
+}
@@ -0,0 +1,33 @@
+package sqlc
+
+import (
+	"strconv"
+
+	"github.com/bronystylecrazy/ultrastructure/web"
+)
+
+// ListClause renders q as Postgres clauses for dynamic pgx queries.
+// Placeholders are numbered from firstArg. where is TRUE without filters and
+// orderBy falls back to defaultOrder when q has no sort.
+//
+// Usage:
+//
+//	where, orderBy, args := sqlc.ListClause(q, 2, "created_at DESC")
+//	rows, err := pool.Query(ctx, "SELECT * FROM orders WHERE tenant_id = $1 AND "+where+" ORDER BY "+orderBy,
+//		append([]any{tenantID}, args...)...)
+func ListClause[T any](q web.ListQuery[T], firstArg int, defaultOrder string) (where, orderBy string, args []any) {
+	if firstArg < 1 {
+		firstArg = 1
+	}
+	where, args = q.Where(func(n int) string {
+		return "$" + strconv.Itoa(firstArg+n-1)
+	})
+	if where == "" {
+		where = "TRUE"
+	}
+	orderBy = q.OrderBy()
+	if orderBy == "" {
+		orderBy = defaultOrder
+	}
+	return where, orderBy, args
+}
@@ -0,0 +1,295 @@
+package sqlc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/webhook"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// WebhookSchema creates the Postgres tables WebhookStore uses. Run it from a
+// migration or with WebhookStore.Migrate.
+const WebhookSchema = `
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+	id                   varchar(36) PRIMARY KEY,
+	url                  varchar(2048) NOT NULL,
+	secret               varchar(128) NOT NULL,
+	events               text[] NOT NULL DEFAULT '{}',
+	description          varchar(512) NOT NULL DEFAULT '',
+	disabled             boolean NOT NULL DEFAULT false,
+	disabled_reason      varchar(512) NOT NULL DEFAULT '',
+	consecutive_failures integer NOT NULL DEFAULT 0,
+	created_at           timestamptz NOT NULL,
+	updated_at           timestamptz NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id               varchar(36) PRIMARY KEY,
+	endpoint_id      varchar(36) NOT NULL,
+	event_id         varchar(36) NOT NULL,
+	event_type       varchar(255) NOT NULL,
+	payload          bytea NOT NULL,
+	status           varchar(16) NOT NULL,
+	attempts         integer NOT NULL DEFAULT 0,
+	next_attempt_at  timestamptz NOT NULL,
+	last_status_code integer NOT NULL DEFAULT 0,
+	last_error       varchar(1024) NOT NULL DEFAULT '',
+	created_at       timestamptz NOT NULL,
+	updated_at       timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint_id ON webhook_deliveries (endpoint_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created_at ON webhook_deliveries (created_at);
+
+CREATE TABLE IF NOT EXISTS webhook_attempts (
+	id          varchar(36) PRIMARY KEY,
+	delivery_id varchar(36) NOT NULL,
+	number      integer NOT NULL,
+	status_code integer NOT NULL DEFAULT 0,
+	error       varchar(1024) NOT NULL DEFAULT '',
+	latency_ms  bigint NOT NULL DEFAULT 0,
+	created_at  timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_attempts_delivery_id ON webhook_attempts (delivery_id);
+`
+
+// WebhookDB is the pgx handle WebhookStore runs on; *pgxpool.Pool and
+// pgx.Tx both satisfy it.
+type WebhookDB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// WebhookStore keeps webhook endpoints, deliveries and attempts in Postgres
+// through pgx. Create the tables with WebhookSchema.
+//
+// Usage: webhook.Providers(sqlc.Provide(sqlc.NewWebhookStore, di.As[webhook.Store]()))
+type WebhookStore struct {
+	db WebhookDB
+}
+
+func NewWebhookStore(db WebhookDB) *WebhookStore {
+	return &WebhookStore{db: db}
+}
+
+// Migrate creates the webhook tables if they do not exist.
+func (s *WebhookStore) Migrate(ctx context.Context) error {
+	_, err := s.db.Exec(ctx, WebhookSchema)
+	return err
+}
+
+const webhookEndpointColumns = `id, url, secret, events, description, disabled, disabled_reason, consecutive_failures, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, endpoint_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, updated_at`
+
+func (s *WebhookStore) SaveEndpoint(ctx context.Context, endpoint webhook.Endpoint) error {
+	events := endpoint.Events
+	if events == nil {
+		events = []string{}
+	}
+	_, err := s.db.Exec(ctx, `
+INSERT INTO webhook_endpoints (`+webhookEndpointColumns+`)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (id) DO UPDATE SET
+	url = EXCLUDED.url,
+	secret = EXCLUDED.secret,
+	events = EXCLUDED.events,
+	description = EXCLUDED.description,
+	disabled = EXCLUDED.disabled,
+	disabled_reason = EXCLUDED.disabled_reason,
+	consecutive_failures = EXCLUDED.consecutive_failures,
+	updated_at = EXCLUDED.updated_at`,
+		endpoint.ID, endpoint.URL, endpoint.Secret, events, endpoint.Description, endpoint.Disabled,
+		endpoint.DisabledReason, endpoint.ConsecutiveFailures, endpoint.CreatedAt, endpoint.UpdatedAt)
+	return err
+}
+
+func (s *WebhookStore) Endpoint(ctx context.Context, id string) (webhook.Endpoint, error) {
+	row := s.db.QueryRow(ctx, `SELECT `+webhookEndpointColumns+` FROM webhook_endpoints WHERE id = $1`, id)
+	endpoint, err := scanWebhookEndpoint(row)
+	if err != nil {
+		return webhook.Endpoint{}, webhookNotFound(err)
+	}
+	return endpoint, nil
+}
+
+func (s *WebhookStore) Endpoints(ctx context.Context) ([]webhook.Endpoint, error) {
+	rows, err := s.db.Query(ctx, `SELECT `+webhookEndpointColumns+` FROM webhook_endpoints ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (webhook.Endpoint, error) {
+		return scanWebhookEndpoint(row)
+	})
+}
+
+func (s *WebhookStore) DeleteEndpoint(ctx context.Context, id string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return webhook.ErrNotFound
+	}
+	return nil
+}
+
+func (s *WebhookStore) CreateDeliveries(ctx context.Context, deliveries []webhook.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	batch := &pgx.Batch{}
+	for _, d := range deliveries {
+		batch.Queue(`INSERT INTO webhook_deliveries (`+webhookDeliveryColumns+`)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+			d.ID, d.EndpointID, d.EventID, d.EventType, []byte(d.Payload), string(d.Status), d.Attempts,
+			d.NextAttemptAt, d.LastStatusCode, d.LastError, d.CreatedAt, d.UpdatedAt)
+	}
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		return tx.SendBatch(ctx, batch).Close()
+	})
+}
+
+// ClaimDeliveries leases due deliveries in one statement. SKIP LOCKED leaves
+// rows another worker is claiming to that worker.
+func (s *WebhookStore) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]webhook.Delivery, error) {
+	rows, err := s.db.Query(ctx, `
+UPDATE webhook_deliveries SET next_attempt_at = $1
+WHERE id IN (
+	SELECT id FROM webhook_deliveries
+	WHERE status = $2 AND next_attempt_at <= $3
+	ORDER BY next_attempt_at
+	LIMIT $4
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING `+webhookDeliveryColumns,
+		now.Add(lease), string(webhook.DeliveryPending), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (webhook.Delivery, error) {
+		return scanWebhookDelivery(row)
+	})
+}
+
+func (s *WebhookStore) RecordAttempt(ctx context.Context, delivery webhook.Delivery, attempt webhook.Attempt) error {
+	return s.inTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+INSERT INTO webhook_attempts (id, delivery_id, number, status_code, error, latency_ms, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			attempt.ID, attempt.DeliveryID, attempt.Number, attempt.StatusCode, attempt.Error,
+			attempt.LatencyMS, attempt.CreatedAt)
+		if err != nil {
+			return err
+		}
+		return updateWebhookDelivery(ctx, tx, delivery)
+	})
+}
+
+func (s *WebhookStore) UpdateDelivery(ctx context.Context, delivery webhook.Delivery) error {
+	return updateWebhookDelivery(ctx, s.db, delivery)
+}
+
+func (s *WebhookStore) Delivery(ctx context.Context, id string) (webhook.Delivery, error) {
+	row := s.db.QueryRow(ctx, `SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE id = $1`, id)
+	delivery, err := scanWebhookDelivery(row)
+	if err != nil {
+		return webhook.Delivery{}, webhookNotFound(err)
+	}
+	return delivery, nil
+}
+
+func (s *WebhookStore) Deliveries(ctx context.Context, filter webhook.DeliveryFilter) ([]webhook.Delivery, error) {
+	rows, err := s.db.Query(ctx, `
+SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries
+WHERE ($1::text = '' OR endpoint_id = $1) AND ($2::text = '' OR status = $2)
+ORDER BY created_at DESC
+LIMIT $3`,
+		filter.EndpointID, string(filter.Status), filter.LimitOrDefault())
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (webhook.Delivery, error) {
+		return scanWebhookDelivery(row)
+	})
+}
+
+func (s *WebhookStore) Attempts(ctx context.Context, deliveryID string) ([]webhook.Attempt, error) {
+	rows, err := s.db.Query(ctx, `
+SELECT id, delivery_id, number, status_code, error, latency_ms, created_at
+FROM webhook_attempts WHERE delivery_id = $1 ORDER BY number`, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (webhook.Attempt, error) {
+		var a webhook.Attempt
+		err := row.Scan(&a.ID, &a.DeliveryID, &a.Number, &a.StatusCode, &a.Error, &a.LatencyMS, &a.CreatedAt)
+		return a, err
+	})
+}
+
+func (s *WebhookStore) inTx(ctx context.Context, fn func(pgx.Tx) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+type webhookExecer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+func updateWebhookDelivery(ctx context.Context, db webhookExecer, d webhook.Delivery) error {
+	tag, err := db.Exec(ctx, `
+UPDATE webhook_deliveries SET
+	status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6, updated_at = $7
+WHERE id = $1`,
+		d.ID, string(d.Status), d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return webhook.ErrNotFound
+	}
+	return nil
+}
+
+func scanWebhookEndpoint(row pgx.Row) (webhook.Endpoint, error) {
+	var e webhook.Endpoint
+	err := row.Scan(&e.ID, &e.URL, &e.Secret, &e.Events, &e.Description, &e.Disabled,
+		&e.DisabledReason, &e.ConsecutiveFailures, &e.CreatedAt, &e.UpdatedAt)
+	if len(e.Events) == 0 {
+		e.Events = nil
+	}
+	return e, err
+}
+
+func scanWebhookDelivery(row pgx.Row) (webhook.Delivery, error) {
+	var (
+		d       webhook.Delivery
+		payload []byte
+		status  string
+	)
+	err := row.Scan(&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &payload, &status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.UpdatedAt)
+	d.Payload = payload
+	d.Status = webhook.DeliveryStatus(status)
+	return d, err
+}
+
+func webhookNotFound(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return webhook.ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+//go:build integration
+
+package sqlc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bronystylecrazy/ultrastructure/testkit"
+	"github.com/bronystylecrazy/ultrastructure/webhook"
+	"github.com/bronystylecrazy/ultrastructure/x/sqlc"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestWebhookStoreWithPostgres(t *testing.T) {
+	testkit.RequireIntegration(t)
+
+	suite := testkit.NewSuite(t)
+	pg := suite.StartPostgres(testkit.PostgresOptions{})
+	ctx := suite.Context()
+	pool, err := pgxpool.New(ctx, pg.URL())
+	if err != nil {
+		t.Fatalf("pgxpool.New: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	store := sqlc.NewWebhookStore(pool)
+	if err := store.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	endpoint := webhook.Endpoint{ID: "e-1", URL: "https://example.com/hook", Secret: "s", Events: []string{"order.*"}, CreatedAt: now, UpdatedAt: now}
+	if err := store.SaveEndpoint(ctx, endpoint); err != nil {
+		t.Fatalf("SaveEndpoint: %v", err)
+	}
+	got, err := store.Endpoint(ctx, "e-1")
+	if err != nil || got.URL != endpoint.URL || len(got.Events) != 1 {
+		t.Fatalf("Endpoint: %+v, %v", got, err)
+	}
+
+	delivery := webhook.Delivery{ID: "d-1", EndpointID: "e-1", EventID: "ev-1", EventType: "order.paid", Payload: []byte(`{"id":1}`),
+		Status: webhook.DeliveryPending, NextAttemptAt: now, CreatedAt: now, UpdatedAt: now}
+	if err := store.CreateDeliveries(ctx, []webhook.Delivery{delivery}); err != nil {
+		t.Fatalf("CreateDeliveries: %v", err)
+	}
+	claimed, err := store.ClaimDeliveries(ctx, now, time.Minute, 10)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("ClaimDeliveries: %+v, %v", claimed, err)
+	}
+	if again, _ := store.ClaimDeliveries(ctx, now, time.Minute, 10); len(again) != 0 {
+		t.Fatalf("leased delivery claimed twice: %+v", again)
+	}
+
+	delivery.Status, delivery.Attempts = webhook.DeliverySucceeded, 1
+	attempt := webhook.Attempt{ID: "a-1", DeliveryID: "d-1", Number: 1, StatusCode: 200, LatencyMS: 12, CreatedAt: now}
+	if err := store.RecordAttempt(ctx, delivery, attempt); err != nil {
+		t.Fatalf("RecordAttempt: %v", err)
+	}
+	attempts, err := store.Attempts(ctx, "d-1")
+	if err != nil || len(attempts) != 1 || attempts[0].StatusCode != 200 {
+		t.Fatalf("Attempts: %+v, %v", attempts, err)
+	}
+	listed, err := store.Deliveries(ctx, webhook.DeliveryFilter{Status: webhook.DeliverySucceeded})
+	if err != nil || len(listed) != 1 || string(listed[0].Payload) != `{"id":1}` {
+		t.Fatalf("Deliveries: %+v, %v", listed, err)
+	}
+	if err := store.DeleteEndpoint(ctx, "e-1"); err != nil {
+		t.Fatalf("DeleteEndpoint: %v", err)
+	}
+	if _, err := store.Endpoint(ctx, "e-1"); err != webhook.ErrNotFound {
+		t.Fatalf("deleted endpoint: %v", err)
+	}
+}